@@ -9,5 +9,15 @@ import (
 
 func main() {
 	log.SetOutput(os.Stdout)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			app.Migrate(os.Args[2:])
+			return
+		case "roster":
+			app.Roster(os.Args[2:])
+			return
+		}
+	}
 	app.Run()
 }
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const (
+	// RequestIDHeader carries the id of a request, the client may set it
+	// and the response always has it
+	RequestIDHeader = "X-Request-ID"
+	// ActorHeader names who makes the request, it is written to the audit log
+	ActorHeader = "X-Actor"
+	// AnonymousActor is the actor of requests without ActorHeader
+	AnonymousActor = "anonymous"
+
+	requestIDKey = "request_id"
+)
+
+// assignRequestID gives every request an id, the one sent by the client or
+// a random one, and returns it in the response.
+func assignRequestID(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+
+	c.Set(requestIDKey, requestID)
+	c.Header(RequestIDHeader, requestID)
+	c.Next()
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	// crypto/rand.Read never fails on the supported platforms
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// requestContext is the context of service calls made by a handler, it tells
+// the service who changes data and in which request.
+func requestContext(c *gin.Context) context.Context {
+	actor := c.GetHeader(ActorHeader)
+	if actor == "" {
+		actor = AnonymousActor
+	}
+
+	return service.WithAuditMeta(context.Background(), service.AuditMeta{
+		Actor:     actor,
+		RequestID: c.GetString(requestIDKey),
+	})
+}
+
+type AuditLogQuery struct {
+	Actor      string     `form:"actor"`
+	Action     string     `form:"action"`
+	TargetType string     `form:"target_type"`
+	TargetID   string     `form:"target_id"`
+	RequestID  string     `form:"request_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// AfterID is the next_after_id of the previous page
+	AfterID int64 `form:"after_id"`
+	Limit   int   `form:"limit"`
+}
+
+func (q *AuditLogQuery) filter() domain.AuditFilter {
+	return domain.AuditFilter{
+		Actor:      q.Actor,
+		Action:     domain.AuditAction(q.Action),
+		TargetType: domain.AuditTargetType(q.TargetType),
+		TargetID:   q.TargetID,
+		RequestID:  q.RequestID,
+		From:       q.From,
+		To:         q.To,
+		AfterID:    q.AfterID,
+		Limit:      q.Limit,
+	}
+}
+
+type AuditEntryResponse struct {
+	ID         int64  `json:"id"`
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// Before and After are null when the target did not exist
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	Entries []AuditEntryResponse `json:"entries"`
+	// NextAfterID is set when the page is full and more entries may follow
+	NextAfterID *int64 `json:"next_after_id,omitempty"`
+}
+
+func newAuditEntryResponse(entry domain.AuditEntry) AuditEntryResponse {
+	return AuditEntryResponse{
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		Action:     string(entry.Action),
+		TargetType: string(entry.TargetType),
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		RequestID:  entry.RequestID,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+func (s *GinService) GetAuditLog(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var query AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	filter := query.filter()
+	entries, err := s.srv.GetAuditLog(ctx, filter)
+	if err != nil {
+		writeAuditLogError(c, err)
+		return
+	}
+
+	response := AuditLogResponse{Entries: make([]AuditEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, newAuditEntryResponse(entry))
+	}
+	pageSize := filter.Limit
+	if pageSize == 0 {
+		pageSize = service.DefaultAuditPageSize
+	}
+	if len(entries) == pageSize {
+		response.NextAfterID = &entries[len(entries)-1].ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportAuditLog streams every entry matching the query as NDJSON,
+// one AuditEntryResponse per line. The query is the one of GetAuditLog,
+// limit is ignored.
+func (s *GinService) ExportAuditLog(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var query AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	encoder := json.NewEncoder(c.Writer)
+	err := s.srv.ExportAuditLog(ctx, query.filter(), func(entry domain.AuditEntry) error {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+		}
+		return encoder.Encode(newAuditEntryResponse(entry))
+	})
+	if err != nil {
+		// Once lines are sent the status can't change, the client sees a cut stream
+		if !c.Writer.Written() {
+			writeAuditLogError(c, err)
+		}
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+	}
+}
+
+func writeAuditLogError(c *gin.Context, err error) {
+	var invalidFilterErr *domain.InvalidAuditFilterError
+	if errors.As(err, &invalidFilterErr) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+	} else {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+			Code:    UNHANDLED_SERVER_ERROR,
+			Message: err.Error(),
+		}})
+	}
+}
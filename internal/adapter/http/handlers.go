@@ -1,12 +1,10 @@
 package http
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raccoon00/avito-pr/internal/domain"
@@ -23,6 +21,16 @@ const (
 	NO_CANDIDATE ErrorCode = "NO_CANDIDATE"
 	NOT_FOUND    ErrorCode = "NOT_FOUND"
 
+	USER_EXISTS    ErrorCode = "USER_EXISTS"
+	TEAM_NOT_EMPTY ErrorCode = "TEAM_NOT_EMPTY"
+	USER_HAS_PRS   ErrorCode = "USER_HAS_PRS"
+
+	PR_VERSION_CONFLICT ErrorCode = "PR_VERSION_CONFLICT"
+	PR_NOT_MODIFIABLE   ErrorCode = "PR_NOT_MODIFIABLE"
+	MERGE_BLOCKED       ErrorCode = "MERGE_BLOCKED"
+	ALREADY_ASSIGNED    ErrorCode = "ALREADY_ASSIGNED"
+	REVIEWER_LIMIT      ErrorCode = "REVIEWER_LIMIT"
+
 	BAD_REQUEST            ErrorCode = "BAD_REQUEST"
 	UNHANDLED_SERVER_ERROR ErrorCode = "UNHANDLED_SERVER_ERROR"
 )
@@ -32,8 +40,11 @@ type GinService struct {
 }
 
 type Team struct {
-	Name    string   `json:"team_name" binding:"required"`
-	Members []Member `json:"members" binding:"required"`
+	Name             string   `json:"team_name" binding:"required"`
+	Members          []Member `json:"members" binding:"required"`
+	ReviewerStrategy string   `json:"reviewer_strategy,omitempty"`
+	// 0 означает число ревьюверов по умолчанию
+	ReviewerCount int `json:"reviewer_count,omitempty"`
 }
 
 type Member struct {
@@ -45,6 +56,8 @@ type Member struct {
 type ErrorBody struct {
 	Code    ErrorCode `json:"code" binding:"required"`
 	Message string    `json:"message" binding:"required"`
+	// Details lists the individual problems, e.g. the unmet merge policy conditions
+	Details []string `json:"details,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -52,7 +65,7 @@ type ErrorResponse struct {
 }
 
 func (s *GinService) TeamAdd(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	var team Team
 	if err := c.ShouldBind(&team); err != nil {
@@ -63,19 +76,33 @@ func (s *GinService) TeamAdd(c *gin.Context) {
 		return
 	}
 
-	newTeam := domain.Team{Name: team.Name, Members: make([]domain.User, 0, len(team.Members))}
+	newTeam := domain.Team{
+		Name:    team.Name,
+		Members: make([]domain.User, 0, len(team.Members)),
+		Settings: domain.TeamSettings{
+			ReviewerStrategy: domain.ReviewerStrategy(team.ReviewerStrategy),
+			ReviewerCount:    team.ReviewerCount,
+		},
+	}
 	for _, member := range team.Members {
 		newTeam.Members = append(newTeam.Members, domain.User{Id: member.Id, Name: member.Name, Team: team.Name, IsActive: member.IsActive})
 	}
 
-	insertedTeam, err := s.srv.AddTeam(ctx, &newTeam)
+	result, err := s.srv.AddTeam(ctx, &newTeam)
 	if err != nil {
 		var errTeamExits *domain.TeamExistsError
+		var errUnknownStrategy *domain.UnknownReviewerStrategyError
+		var errInvalidCount *domain.InvalidReviewerCountError
 		if errors.As(err, &errTeamExits) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
 				Code:    TEAM_EXISTS,
 				Message: fmt.Sprintf("Team %s already exists", newTeam.Name),
 			}})
+		} else if errors.As(err, &errUnknownStrategy) || errors.As(err, &errInvalidCount) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
 		} else {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
 				Code:    UNHANDLED_SERVER_ERROR,
@@ -86,11 +113,13 @@ func (s *GinService) TeamAdd(c *gin.Context) {
 	}
 
 	createdTeam := Team{
-		Name:    insertedTeam.Name,
-		Members: make([]Member, 0, len(insertedTeam.Members)),
+		Name:             result.Team.Name,
+		Members:          make([]Member, 0, len(result.Team.Members)),
+		ReviewerStrategy: string(result.Team.Settings.ReviewerStrategy),
+		ReviewerCount:    result.Team.Settings.ReviewerCount,
 	}
 
-	for _, member := range insertedTeam.Members {
+	for _, member := range result.Team.Members {
 		createdTeam.Members = append(createdTeam.Members, Member{
 			Id:       member.Id,
 			Name:     member.Name,
@@ -99,12 +128,15 @@ func (s *GinService) TeamAdd(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"team": createdTeam,
+		"team":            createdTeam,
+		"created_members": result.CreatedUserIDs,
+		"updated_members": result.UpdatedUserIDs,
+		"reassignments":   newReviewerReplacementsResponse(result.Replacements),
 	})
 }
 
 func (s *GinService) TeamGet(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	teamName := c.Query("team_name")
 	if teamName == "" {
@@ -125,8 +157,10 @@ func (s *GinService) TeamGet(c *gin.Context) {
 	}
 
 	responseTeam := Team{
-		Name:    team.Name,
-		Members: make([]Member, 0, len(team.Members)),
+		Name:             team.Name,
+		Members:          make([]Member, 0, len(team.Members)),
+		ReviewerStrategy: string(team.Settings.ReviewerStrategy),
+		ReviewerCount:    team.Settings.ReviewerCount,
 	}
 
 	for _, member := range team.Members {
@@ -140,6 +174,64 @@ func (s *GinService) TeamGet(c *gin.Context) {
 	c.JSON(http.StatusOK, responseTeam)
 }
 
+type SetReviewerStrategyRequest struct {
+	TeamName         string `json:"team_name" binding:"required"`
+	ReviewerStrategy string `json:"reviewer_strategy" binding:"required"`
+}
+
+type TeamSettingsResponse struct {
+	TeamName         string              `json:"team_name"`
+	ReviewerStrategy string              `json:"reviewer_strategy"`
+	ReviewerCount    int                 `json:"reviewer_count"`
+	MergePolicy      MergePolicyResponse `json:"merge_policy"`
+	FallbackTeams    []string            `json:"fallback_teams"`
+	// Правила владения путями, последнее совпавшее правило побеждает
+	OwnershipRules []OwnershipRuleResponse `json:"ownership_rules"`
+}
+
+type OwnershipRuleResponse struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+func (s *GinService) SetTeamReviewerStrategy(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetReviewerStrategyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	settings, err := s.srv.SetTeamReviewerStrategy(ctx, req.TeamName, domain.ReviewerStrategy(req.ReviewerStrategy))
+	if err != nil {
+		var unknownStrategyErr *domain.UnknownReviewerStrategyError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &unknownStrategyErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamSettingsResponse(req.TeamName, settings))
+}
+
 type SetUserIsActiveRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 	// Ссылка на bool потому что gin не понимает разницу между
@@ -148,14 +240,15 @@ type SetUserIsActiveRequest struct {
 }
 
 type UserResponse struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	MaxOpenReviews *int   `json:"max_open_reviews,omitempty"`
 }
 
 func (s *GinService) SetUserIsActive(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	var req SetUserIsActiveRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -176,10 +269,11 @@ func (s *GinService) SetUserIsActive(c *gin.Context) {
 	}
 
 	responseUser := UserResponse{
-		UserID:   user.Id,
-		Username: user.Name,
-		TeamName: user.Team,
-		IsActive: user.IsActive,
+		UserID:         user.Id,
+		Username:       user.Name,
+		TeamName:       user.Team,
+		IsActive:       user.IsActive,
+		MaxOpenReviews: user.MaxOpenReviews,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -187,10 +281,67 @@ func (s *GinService) SetUserIsActive(c *gin.Context) {
 	})
 }
 
+type SetUserReviewLimitRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	// null снимает ограничение
+	MaxOpenReviews *int `json:"max_open_reviews"`
+}
+
+func (s *GinService) SetUserReviewLimit(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetUserReviewLimitRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	user, err := s.srv.SetUserMaxOpenReviews(ctx, req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		var invalidLimitErr *domain.InvalidReviewLimitError
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &invalidLimitErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &userNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: fmt.Sprintf("User %s not found", req.UserID),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": UserResponse{
+			UserID:         user.Id,
+			Username:       user.Name,
+			TeamName:       user.Team,
+			IsActive:       user.IsActive,
+			MaxOpenReviews: user.MaxOpenReviews,
+		},
+	})
+}
+
 type CreatePullRequestRequest struct {
 	PullRequestID   string `json:"pull_request_id" binding:"required"`
 	PullRequestName string `json:"pull_request_name" binding:"required"`
 	AuthorID        string `json:"author_id" binding:"required"`
+	// Draft PRs are created without reviewers
+	Draft bool `json:"draft"`
+	// Владельцы изменённых файлов по ownership_rules команды автора
+	// назначаются ревьюверами в первую очередь
+	ChangedPaths []string `json:"changed_paths"`
 }
 
 type PullRequestResponse struct {
@@ -199,12 +350,16 @@ type PullRequestResponse struct {
 	AuthorID          string   `json:"author_id"`
 	Status            string   `json:"status"`
 	AssignedReviewers []string `json:"assigned_reviewers"`
+	ChangedPaths      []string `json:"changed_paths,omitempty"`
 	CreatedAt         *string  `json:"createdAt,omitempty"`
 	MergedAt          *string  `json:"mergedAt,omitempty"`
+	Version           int      `json:"version"`
+	// Reviews has the state of every assigned reviewer, in the order of assigned_reviewers
+	Reviews []ReviewerStateResponse `json:"reviews"`
 }
 
 func (s *GinService) CreatePullRequest(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	var req CreatePullRequestRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -215,7 +370,7 @@ func (s *GinService) CreatePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := s.srv.CreatePullRequest(ctx, req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := s.srv.CreatePullRequest(ctx, req.PullRequestID, req.PullRequestName, req.AuthorID, req.ChangedPaths, req.Draft)
 	if err != nil {
 		var prExistsErr *domain.PullRequestExistsError
 		var authorNotFoundErr *domain.AuthorNotFoundError
@@ -240,22 +395,7 @@ func (s *GinService) CreatePullRequest(c *gin.Context) {
 		return
 	}
 
-	responsePR := PullRequestResponse{
-		PullRequestID:     pr.ID,
-		PullRequestName:   pr.Name,
-		AuthorID:          pr.AuthorID,
-		Status:            string(pr.Status),
-		AssignedReviewers: pr.AssignedReviewers,
-	}
-
-	if pr.CreatedAt != nil {
-		createdAtStr := pr.CreatedAt.Format(time.RFC3339)
-		responsePR.CreatedAt = &createdAtStr
-	}
-	if pr.MergedAt != nil {
-		mergedAtStr := pr.MergedAt.Format(time.RFC3339)
-		responsePR.MergedAt = &mergedAtStr
-	}
+	responsePR := newPullRequestResponse(pr)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"pr": responsePR,
@@ -273,7 +413,7 @@ type ReassignReviewerResponse struct {
 }
 
 func (s *GinService) ReassignReviewer(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	var req ReassignReviewerRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -286,15 +426,23 @@ func (s *GinService) ReassignReviewer(c *gin.Context) {
 
 	pr, newReviewerID, err := s.srv.ReassignReviewer(ctx, req.PullRequestID, req.OldUserID)
 	if err != nil {
-		var prMergedErr *domain.PRMergedError
+		var notModifiableErr *domain.PRNotModifiableError
 		var notAssignedErr *domain.ReviewerNotAssignedError
 		var userNotFoundErr *domain.UserNotFoundError
 		var noCandidateErr *domain.NoReviewersAvailableError
-		if errors.As(err, &prMergedErr) {
-			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
-				Code:    PR_MERGED,
-				Message: "cannot reassign on merged PR",
-			}})
+		var conflictErr *domain.PullRequestConflictError
+		if errors.As(err, &notModifiableErr) {
+			if notModifiableErr.Status == domain.PullRequestStatusMerged {
+				c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+					Code:    PR_MERGED,
+					Message: "cannot reassign on merged PR",
+				}})
+			} else {
+				c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+					Code:    PR_NOT_MODIFIABLE,
+					Message: err.Error(),
+				}})
+			}
 		} else if errors.As(err, &notAssignedErr) {
 			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
 				Code:    NOT_ASSIGNED,
@@ -305,6 +453,11 @@ func (s *GinService) ReassignReviewer(c *gin.Context) {
 				Code:    NO_CANDIDATE,
 				Message: "no active replacement candidate in team",
 			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
 		} else if errors.As(err, &userNotFoundErr) || strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
 				Code:    NOT_FOUND,
@@ -319,22 +472,7 @@ func (s *GinService) ReassignReviewer(c *gin.Context) {
 		return
 	}
 
-	responsePR := PullRequestResponse{
-		PullRequestID:     pr.ID,
-		PullRequestName:   pr.Name,
-		AuthorID:          pr.AuthorID,
-		Status:            string(pr.Status),
-		AssignedReviewers: pr.AssignedReviewers,
-	}
-
-	if pr.CreatedAt != nil {
-		createdAtStr := pr.CreatedAt.Format(time.RFC3339)
-		responsePR.CreatedAt = &createdAtStr
-	}
-	if pr.MergedAt != nil {
-		mergedAtStr := pr.MergedAt.Format(time.RFC3339)
-		responsePR.MergedAt = &mergedAtStr
-	}
+	responsePR := newPullRequestResponse(pr)
 
 	c.JSON(http.StatusOK, ReassignReviewerResponse{
 		PR:         responsePR,
@@ -344,10 +482,12 @@ func (s *GinService) ReassignReviewer(c *gin.Context) {
 
 type MergePullRequestRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
+	// Team leads merging a PR bypass the merge policy
+	MergedBy string `json:"user_id"`
 }
 
 func (s *GinService) MergePullRequest(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	var req MergePullRequestRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -358,9 +498,28 @@ func (s *GinService) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := s.srv.MergePullRequest(ctx, req.PullRequestID)
+	pr, err := s.srv.MergePullRequest(ctx, req.PullRequestID, req.MergedBy)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		var notModifiableErr *domain.PRNotModifiableError
+		var policyErr *domain.MergePolicyViolationError
+		var conflictErr *domain.PullRequestConflictError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    MERGE_BLOCKED,
+				Message: err.Error(),
+				Details: policyErr.Unmet,
+			}})
+		} else if errors.As(err, &notModifiableErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_NOT_MODIFIABLE,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
 				Code:    NOT_FOUND,
 				Message: err.Error(),
@@ -374,22 +533,7 @@ func (s *GinService) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	responsePR := PullRequestResponse{
-		PullRequestID:     pr.ID,
-		PullRequestName:   pr.Name,
-		AuthorID:          pr.AuthorID,
-		Status:            string(pr.Status),
-		AssignedReviewers: pr.AssignedReviewers,
-	}
-
-	if pr.CreatedAt != nil {
-		createdAtStr := pr.CreatedAt.Format(time.RFC3339)
-		responsePR.CreatedAt = &createdAtStr
-	}
-	if pr.MergedAt != nil {
-		mergedAtStr := pr.MergedAt.Format(time.RFC3339)
-		responsePR.MergedAt = &mergedAtStr
-	}
+	responsePR := newPullRequestResponse(pr)
 
 	c.JSON(http.StatusOK, gin.H{
 		"pr": responsePR,
@@ -409,7 +553,7 @@ type GetUserReviewsResponse struct {
 }
 
 func (s *GinService) GetUserReviews(c *gin.Context) {
-	ctx := context.Background()
+	ctx := requestContext(c)
 
 	userID := c.Query("user_id")
 	if userID == "" {
@@ -420,7 +564,8 @@ func (s *GinService) GetUserReviews(c *gin.Context) {
 		return
 	}
 
-	prs, err := s.srv.GetUserReviews(ctx, userID)
+	// awaiting=true keeps only OPEN PRs the user has not decided on yet
+	prs, err := s.srv.GetUserReviews(ctx, userID, c.Query("awaiting") == "true")
 	if err != nil {
 		var userNotFoundErr *domain.UserNotFoundError
 		if errors.As(err, &userNotFoundErr) {
@@ -0,0 +1,480 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// reviewStatePending is the state of an assigned reviewer without a verdict
+const reviewStatePending = "PENDING"
+
+type PullRequestTransitionRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+}
+
+func (s *GinService) ClosePullRequest(c *gin.Context) {
+	s.transitionPullRequest(c, s.srv.ClosePullRequest)
+}
+
+func (s *GinService) ReopenPullRequest(c *gin.Context) {
+	s.transitionPullRequest(c, s.srv.ReopenPullRequest)
+}
+
+func (s *GinService) MarkPullRequestReady(c *gin.Context) {
+	s.transitionPullRequest(c, s.srv.MarkPullRequestReady)
+}
+
+func (s *GinService) ConvertPullRequestToDraft(c *gin.Context) {
+	s.transitionPullRequest(c, s.srv.ConvertPullRequestToDraft)
+}
+
+// transitionPullRequest serves the status change endpoints,
+// they share the request, the errors and the response.
+func (s *GinService) transitionPullRequest(
+	c *gin.Context,
+	transition func(ctx context.Context, prID string) (*domain.PullRequest, error),
+) {
+	ctx := requestContext(c)
+
+	var req PullRequestTransitionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	pr, err := transition(ctx, req.PullRequestID)
+	if err != nil {
+		var notModifiableErr *domain.PRNotModifiableError
+		var conflictErr *domain.PullRequestConflictError
+		var authorNotFoundErr *domain.AuthorNotFoundError
+		if errors.As(err, &notModifiableErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_NOT_MODIFIABLE,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &authorNotFoundErr) || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": newPullRequestResponse(pr),
+	})
+}
+
+type ReviewerChangeRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	UserID        string `json:"user_id" binding:"required"`
+}
+
+func (s *GinService) AddReviewer(c *gin.Context) {
+	s.changeReviewer(c, s.srv.AddReviewer)
+}
+
+func (s *GinService) RemoveReviewer(c *gin.Context) {
+	s.changeReviewer(c, s.srv.RemoveReviewer)
+}
+
+// changeReviewer serves the manual reviewer endpoints,
+// they share the request, the errors and the response.
+func (s *GinService) changeReviewer(
+	c *gin.Context,
+	change func(ctx context.Context, prID, userID string) (*domain.PullRequest, error),
+) {
+	ctx := requestContext(c)
+
+	var req ReviewerChangeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	pr, err := change(ctx, req.PullRequestID, req.UserID)
+	if err != nil {
+		var notModifiableErr *domain.PRNotModifiableError
+		var alreadyAssignedErr *domain.ReviewerAlreadyAssignedError
+		var notAssignedErr *domain.ReviewerNotAssignedError
+		var limitErr *domain.ReviewerLimitError
+		var invalidReviewerErr *domain.InvalidReviewerError
+		var conflictErr *domain.PullRequestConflictError
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &notModifiableErr) {
+			code := PR_NOT_MODIFIABLE
+			if notModifiableErr.Status == domain.PullRequestStatusMerged {
+				code = PR_MERGED
+			}
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    code,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &alreadyAssignedErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    ALREADY_ASSIGNED,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &notAssignedErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_ASSIGNED,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &limitErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    REVIEWER_LIMIT,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &invalidReviewerErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &userNotFoundErr) || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": newPullRequestResponse(pr),
+	})
+}
+
+type ReviewerChangeResponse struct {
+	UserID    string `json:"user_id"`
+	Action    string `json:"action"`
+	ChangedAt string `json:"changed_at"`
+}
+
+type ReviewerHistoryResponse struct {
+	PullRequestID string                   `json:"pull_request_id"`
+	History       []ReviewerChangeResponse `json:"history"`
+}
+
+func (s *GinService) GetReviewerHistory(c *gin.Context) {
+	ctx := requestContext(c)
+
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: "pull_request_id query parameter is required",
+		}})
+		return
+	}
+
+	changes, err := s.srv.GetReviewerHistory(ctx, prID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	response := ReviewerHistoryResponse{
+		PullRequestID: prID,
+		History:       make([]ReviewerChangeResponse, 0, len(changes)),
+	}
+	for _, change := range changes {
+		response.History = append(response.History, ReviewerChangeResponse{
+			UserID:    change.UserID,
+			Action:    string(change.Action),
+			ChangedAt: change.ChangedAt.Format(time.RFC3339),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type ExcludedCandidateResponse struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// AssignmentRecordResponse описывает выбор ревьюверов из одного пула:
+// владельцев изменённых файлов, команды или запасной команды
+type AssignmentRecordResponse struct {
+	Cause          string                      `json:"cause"`
+	ReplacedUserID string                      `json:"replaced_user_id,omitempty"`
+	Pool           string                      `json:"pool"`
+	TeamName       string                      `json:"team_name"`
+	Strategy       string                      `json:"strategy"`
+	Candidates     []string                    `json:"candidates"`
+	Excluded       []ExcludedCandidateResponse `json:"excluded"`
+	Chosen         []string                    `json:"chosen"`
+	DecidedAt      string                      `json:"decided_at"`
+}
+
+type AssignmentHistoryResponse struct {
+	PullRequestID string                     `json:"pull_request_id"`
+	History       []AssignmentRecordResponse `json:"history"`
+}
+
+func (s *GinService) GetAssignmentHistory(c *gin.Context) {
+	ctx := requestContext(c)
+
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: "pull_request_id query parameter is required",
+		}})
+		return
+	}
+
+	records, err := s.srv.GetAssignmentHistory(ctx, prID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	response := AssignmentHistoryResponse{
+		PullRequestID: prID,
+		History:       make([]AssignmentRecordResponse, 0, len(records)),
+	}
+	for _, record := range records {
+		excluded := make([]ExcludedCandidateResponse, 0, len(record.Excluded))
+		for _, candidate := range record.Excluded {
+			excluded = append(excluded, ExcludedCandidateResponse{
+				UserID: candidate.UserID,
+				Reason: string(candidate.Reason),
+			})
+		}
+		response.History = append(response.History, AssignmentRecordResponse{
+			Cause:          string(record.Cause),
+			ReplacedUserID: record.ReplacedUserID,
+			Pool:           string(record.Pool),
+			TeamName:       record.Team,
+			Strategy:       string(record.Strategy),
+			Candidates:     record.Candidates,
+			Excluded:       excluded,
+			Chosen:         record.Chosen,
+			DecidedAt:      record.DecidedAt.Format(time.RFC3339),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type FillReviewersResponse struct {
+	PR             PullRequestResponse `json:"pr"`
+	AddedReviewers []string            `json:"added_reviewers"`
+}
+
+func (s *GinService) FillPullRequestReviewers(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req PullRequestTransitionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	pr, addedIDs, err := s.srv.FillPullRequestReviewers(ctx, req.PullRequestID)
+	if err != nil {
+		var notModifiableErr *domain.PRNotModifiableError
+		var conflictErr *domain.PullRequestConflictError
+		var authorNotFoundErr *domain.AuthorNotFoundError
+		if errors.As(err, &notModifiableErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_NOT_MODIFIABLE,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &authorNotFoundErr) || strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, FillReviewersResponse{
+		PR:             newPullRequestResponse(pr),
+		AddedReviewers: addedIDs,
+	})
+}
+
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	ReviewerID    string `json:"user_id" binding:"required"`
+	Verdict       string `json:"verdict" binding:"required"`
+}
+
+type ReviewerStateResponse struct {
+	UserID      string  `json:"user_id"`
+	State       string  `json:"state"`
+	SubmittedAt *string `json:"submitted_at,omitempty"`
+	// External marks reviewers picked from a fallback team of the author's team
+	External bool `json:"external,omitempty"`
+	// Reason and OwnedPaths explain why the reviewer was picked, they are
+	// only set in the response of the call that picked the reviewer
+	Reason     string   `json:"reason,omitempty"`
+	OwnedPaths []string `json:"owned_paths,omitempty"`
+}
+
+func (s *GinService) SubmitReview(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SubmitReviewRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	pr, err := s.srv.SubmitReview(ctx, req.PullRequestID, req.ReviewerID, domain.ReviewVerdict(req.Verdict))
+	if err != nil {
+		var unknownVerdictErr *domain.UnknownReviewVerdictError
+		var notModifiableErr *domain.PRNotModifiableError
+		var notAssignedErr *domain.ReviewerNotAssignedError
+		if errors.As(err, &unknownVerdictErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &notModifiableErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_NOT_MODIFIABLE,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &notAssignedErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_ASSIGNED,
+				Message: "reviewer is not assigned to this PR",
+			}})
+		} else if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": newPullRequestResponse(pr),
+	})
+}
+
+func newPullRequestResponse(pr *domain.PullRequest) PullRequestResponse {
+	responsePR := PullRequestResponse{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            string(pr.Status),
+		AssignedReviewers: pr.AssignedReviewers,
+		ChangedPaths:      pr.ChangedPaths,
+		Version:           pr.Version,
+	}
+
+	if pr.CreatedAt != nil {
+		createdAtStr := pr.CreatedAt.Format(time.RFC3339)
+		responsePR.CreatedAt = &createdAtStr
+	}
+	if pr.MergedAt != nil {
+		mergedAtStr := pr.MergedAt.Format(time.RFC3339)
+		responsePR.MergedAt = &mergedAtStr
+	}
+
+	responsePR.Reviews = make([]ReviewerStateResponse, 0, len(pr.AssignedReviewers))
+	for _, reviewer := range pr.AssignedReviewers {
+		state := ReviewerStateResponse{
+			UserID:   reviewer,
+			State:    reviewStatePending,
+			External: slices.Contains(pr.ExternalReviewers, reviewer),
+		}
+		for _, choice := range pr.ReviewerChoices {
+			if choice.UserID == reviewer {
+				state.Reason = string(choice.Reason)
+				state.OwnedPaths = choice.Paths
+			}
+		}
+		for _, review := range pr.Reviews {
+			if review.ReviewerID == reviewer {
+				submittedAtStr := review.UpdatedAt.Format(time.RFC3339)
+				state.State = string(review.Verdict)
+				state.SubmittedAt = &submittedAtStr
+			}
+		}
+		responsePR.Reviews = append(responsePR.Reviews, state)
+	}
+
+	return responsePR
+}
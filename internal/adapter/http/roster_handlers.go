@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// RosterDocument is the roster accepted by /team/sync and the roster
+// command, as JSON or YAML with the same field names.
+type RosterDocument struct {
+	Teams []RosterTeam `json:"teams" binding:"required"`
+}
+
+type RosterTeam struct {
+	Name    string   `json:"team_name"`
+	Members []Member `json:"members"`
+}
+
+type RosterUserChangeResponse struct {
+	Before UserResponse `json:"before"`
+	After  UserResponse `json:"after"`
+}
+
+type RosterSyncPlanResponse struct {
+	CreatedTeams     []string                   `json:"created_teams"`
+	CreatedUsers     []UserResponse             `json:"created_users"`
+	UpdatedUsers     []RosterUserChangeResponse `json:"updated_users"`
+	DeactivatedUsers []UserResponse             `json:"deactivated_users"`
+}
+
+type RosterSyncResponse struct {
+	Applied       bool                          `json:"applied"`
+	Plan          RosterSyncPlanResponse        `json:"plan"`
+	Reassignments []ReviewerReplacementResponse `json:"reassignments"`
+}
+
+func (s *GinService) SyncRoster(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var doc RosterDocument
+	if err := c.ShouldBind(&doc); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	result, err := s.srv.SyncRoster(ctx, doc.Roster(), c.Query("dry_run") == "true")
+	if err != nil {
+		var invalidRosterErr *domain.InvalidRosterError
+		var conflictErr *domain.PullRequestConflictError
+		if errors.As(err, &invalidRosterErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, NewRosterSyncResponse(result))
+}
+
+func (d *RosterDocument) Roster() *domain.Roster {
+	roster := &domain.Roster{Teams: make([]domain.Team, 0, len(d.Teams))}
+	for _, team := range d.Teams {
+		domainTeam := domain.Team{Name: team.Name, Members: make([]domain.User, 0, len(team.Members))}
+		for _, member := range team.Members {
+			domainTeam.Members = append(domainTeam.Members, domain.User{
+				Id:       member.Id,
+				Name:     member.Name,
+				Team:     team.Name,
+				IsActive: member.IsActive,
+			})
+		}
+		roster.Teams = append(roster.Teams, domainTeam)
+	}
+	return roster
+}
+
+func NewRosterSyncResponse(result *domain.RosterSyncResult) RosterSyncResponse {
+	plan := RosterSyncPlanResponse{
+		CreatedTeams:     result.Plan.CreatedTeams,
+		CreatedUsers:     make([]UserResponse, 0, len(result.Plan.CreatedUsers)),
+		UpdatedUsers:     make([]RosterUserChangeResponse, 0, len(result.Plan.UpdatedUsers)),
+		DeactivatedUsers: make([]UserResponse, 0, len(result.Plan.DeactivatedUsers)),
+	}
+	for _, user := range result.Plan.CreatedUsers {
+		plan.CreatedUsers = append(plan.CreatedUsers, newUserResponse(user))
+	}
+	for _, change := range result.Plan.UpdatedUsers {
+		plan.UpdatedUsers = append(plan.UpdatedUsers, RosterUserChangeResponse{
+			Before: newUserResponse(change.Before),
+			After:  newUserResponse(change.After),
+		})
+	}
+	for _, user := range result.Plan.DeactivatedUsers {
+		plan.DeactivatedUsers = append(plan.DeactivatedUsers, newUserResponse(user))
+	}
+
+	return RosterSyncResponse{
+		Applied:       result.Applied,
+		Plan:          plan,
+		Reassignments: newReviewerReplacementsResponse(result.Replacements),
+	}
+}
@@ -7,16 +7,54 @@ import (
 
 func Run(s *service.Service) {
 	r := gin.Default()
+	r.Use(assignRequestID)
 
 	gs := GinService{srv: s}
 
 	r.POST("/team/add", gs.TeamAdd)
 	r.GET("/team/get", gs.TeamGet)
+	r.POST("/team/setReviewerStrategy", gs.SetTeamReviewerStrategy)
+	r.POST("/team/setReviewerCount", gs.SetTeamReviewerCount)
+	r.POST("/team/setMergePolicy", gs.SetTeamMergePolicy)
+	r.POST("/team/setFallbackTeams", gs.SetTeamFallbackTeams)
+	r.POST("/team/setOwnershipRules", gs.SetTeamOwnershipRules)
+	r.POST("/team/fillReviewers", gs.FillTeamReviewers)
+	r.POST("/team/deactivateMembers", gs.DeactivateTeamMembers)
+	r.POST("/team/addMember", gs.AddTeamMember)
+	r.POST("/team/removeMember", gs.RemoveTeamMember)
+	r.POST("/team/moveMember", gs.MoveTeamMember)
+	r.POST("/team/rename", gs.RenameTeam)
+	r.POST("/team/delete", gs.DeleteTeam)
+	r.POST("/team/sync", gs.SyncRoster)
 	r.POST("/users/setIsActive", gs.SetUserIsActive)
+	r.POST("/users/setReviewLimit", gs.SetUserReviewLimit)
+	r.POST("/users/addUnavailability", gs.AddUnavailability)
+	r.GET("/users/getUnavailability", gs.GetUnavailability)
+	r.POST("/users/deleteUnavailability", gs.DeleteUnavailability)
 	r.POST("/pullRequest/create", gs.CreatePullRequest)
 	r.POST("/pullRequest/reassign", gs.ReassignReviewer)
+	r.POST("/pullRequest/fillReviewers", gs.FillPullRequestReviewers)
+	r.POST("/pullRequest/addReviewer", gs.AddReviewer)
+	r.POST("/pullRequest/removeReviewer", gs.RemoveReviewer)
+	r.GET("/pullRequest/reviewerHistory", gs.GetReviewerHistory)
+	r.GET("/pullRequest/assignmentHistory", gs.GetAssignmentHistory)
 	r.POST("/pullRequest/merge", gs.MergePullRequest)
+	r.POST("/pullRequest/close", gs.ClosePullRequest)
+	r.POST("/pullRequest/reopen", gs.ReopenPullRequest)
+	r.POST("/pullRequest/ready", gs.MarkPullRequestReady)
+	r.POST("/pullRequest/draft", gs.ConvertPullRequestToDraft)
+	r.POST("/pullRequest/review", gs.SubmitReview)
 	r.GET("/users/getReview", gs.GetUserReviews)
+	r.GET("/stats/assignments", gs.GetAssignmentStats)
+	r.GET("/stats/assignments/team", gs.GetTeamAssignmentStats)
+	r.GET("/audit/log", gs.GetAuditLog)
+	r.GET("/audit/export", gs.ExportAuditLog)
+	r.POST("/webhooks/create", gs.CreateWebhook)
+	r.GET("/webhooks/get", gs.GetWebhook)
+	r.GET("/webhooks/list", gs.ListWebhooks)
+	r.POST("/webhooks/update", gs.UpdateWebhook)
+	r.POST("/webhooks/delete", gs.DeleteWebhook)
+	r.GET("/webhooks/deliveries", gs.GetWebhookDeliveries)
 
 	r.Run()
 }
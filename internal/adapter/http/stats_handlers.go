@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+type UserAssignmentStatsResponse struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	// Всего PR в любом статусе
+	Assigned int `json:"assigned"`
+	Open     int `json:"open"`
+	Merged   int `json:"merged"`
+	Draft    int `json:"draft"`
+	Closed   int `json:"closed"`
+}
+
+type PullRequestAssignmentStatsResponse struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+	ReviewersCount  int    `json:"reviewers_count"`
+}
+
+type AssignmentStatsResponse struct {
+	TeamName     string                               `json:"team_name,omitempty"`
+	Users        []UserAssignmentStatsResponse        `json:"users"`
+	PullRequests []PullRequestAssignmentStatsResponse `json:"pull_requests"`
+}
+
+func (s *GinService) GetAssignmentStats(c *gin.Context) {
+	ctx := requestContext(c)
+
+	stats, err := s.srv.GetAssignmentStats(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+			Code:    UNHANDLED_SERVER_ERROR,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAssignmentStatsResponse("", stats))
+}
+
+func (s *GinService) GetTeamAssignmentStats(c *gin.Context) {
+	ctx := requestContext(c)
+
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: "team_name query parameter is required",
+		}})
+		return
+	}
+
+	stats, err := s.srv.GetTeamAssignmentStats(ctx, teamName)
+	if err != nil {
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newAssignmentStatsResponse(teamName, stats))
+}
+
+func newAssignmentStatsResponse(teamName string, stats *domain.AssignmentStats) AssignmentStatsResponse {
+	response := AssignmentStatsResponse{
+		TeamName:     teamName,
+		Users:        make([]UserAssignmentStatsResponse, 0, len(stats.Users)),
+		PullRequests: make([]PullRequestAssignmentStatsResponse, 0, len(stats.PullRequests)),
+	}
+
+	for _, user := range stats.Users {
+		response.Users = append(response.Users, UserAssignmentStatsResponse{
+			UserID:   user.UserID,
+			Username: user.Username,
+			TeamName: user.TeamName,
+			Assigned: user.Assigned,
+			Open:     user.Open,
+			Merged:   user.Merged,
+			Draft:    user.Draft,
+			Closed:   user.Closed,
+		})
+	}
+
+	for _, pr := range stats.PullRequests {
+		response.PullRequests = append(response.PullRequests, PullRequestAssignmentStatsResponse{
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			Status:          string(pr.Status),
+			ReviewersCount:  pr.ReviewersCount,
+		})
+	}
+
+	return response
+}
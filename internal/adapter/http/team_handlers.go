@@ -0,0 +1,670 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+type DeactivateTeamMembersRequest struct {
+	TeamName string   `json:"team_name" binding:"required"`
+	UserIDs  []string `json:"user_ids" binding:"required,min=1"`
+}
+
+type ReviewerReplacementResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	// nil когда свободного кандидата не нашлось и слот ревьювера убран
+	NewUserID *string `json:"new_user_id"`
+}
+
+type DeactivateTeamMembersResponse struct {
+	TeamName         string                        `json:"team_name"`
+	DeactivatedUsers []UserResponse                `json:"deactivated_users"`
+	Reassignments    []ReviewerReplacementResponse `json:"reassignments"`
+}
+
+func (s *GinService) DeactivateTeamMembers(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req DeactivateTeamMembersRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	result, err := s.srv.DeactivateTeamMembers(ctx, req.TeamName, req.UserIDs)
+	if err != nil {
+		var teamNotFoundErr *domain.TeamNotFoundError
+		var notInTeamErr *domain.UserNotInTeamError
+		var conflictErr *domain.PullRequestConflictError
+		if errors.As(err, &teamNotFoundErr) || errors.As(err, &notInTeamErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	response := DeactivateTeamMembersResponse{
+		TeamName:         result.TeamName,
+		DeactivatedUsers: make([]UserResponse, 0, len(result.Deactivated)),
+		Reassignments:    newReviewerReplacementsResponse(result.Replacements),
+	}
+
+	for _, user := range result.Deactivated {
+		response.DeactivatedUsers = append(response.DeactivatedUsers, UserResponse{
+			UserID:         user.Id,
+			Username:       user.Name,
+			TeamName:       user.Team,
+			IsActive:       user.IsActive,
+			MaxOpenReviews: user.MaxOpenReviews,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func newReviewerReplacementsResponse(replacements []domain.ReviewerReplacement) []ReviewerReplacementResponse {
+	response := make([]ReviewerReplacementResponse, 0, len(replacements))
+	for _, replacement := range replacements {
+		item := ReviewerReplacementResponse{
+			PullRequestID: replacement.PullRequestID,
+			OldUserID:     replacement.OldUserID,
+		}
+		if replacement.NewUserID != "" {
+			newUserID := replacement.NewUserID
+			item.NewUserID = &newUserID
+		}
+		response = append(response, item)
+	}
+	return response
+}
+
+type AddTeamMemberRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+	UserID   string `json:"user_id" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	IsActive *bool  `json:"is_active" binding:"required"`
+}
+
+type RemoveTeamMemberRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+	UserID   string `json:"user_id" binding:"required"`
+}
+
+type MoveTeamMemberRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+type RenameTeamRequest struct {
+	TeamName    string `json:"team_name" binding:"required"`
+	NewTeamName string `json:"new_team_name" binding:"required"`
+}
+
+type DeleteTeamRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+type TeamMembershipResponse struct {
+	User          UserResponse                  `json:"user"`
+	FromTeam      string                        `json:"from_team,omitempty"`
+	ToTeam        string                        `json:"to_team,omitempty"`
+	Reassignments []ReviewerReplacementResponse `json:"reassignments"`
+}
+
+func (s *GinService) AddTeamMember(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req AddTeamMemberRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	user, err := s.srv.AddTeamMember(ctx, &domain.User{
+		Id:       req.UserID,
+		Name:     req.Username,
+		Team:     req.TeamName,
+		IsActive: *req.IsActive,
+	})
+	if err != nil {
+		var userExistsErr *domain.UserExistsError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &userExistsErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    USER_EXISTS,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"user": newUserResponse(*user),
+	})
+}
+
+func (s *GinService) RemoveTeamMember(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req RemoveTeamMemberRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	result, err := s.srv.RemoveTeamMember(ctx, req.TeamName, req.UserID)
+	if err != nil {
+		s.teamMembershipError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamMembershipResponse(result))
+}
+
+func (s *GinService) MoveTeamMember(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req MoveTeamMemberRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	result, err := s.srv.MoveTeamMember(ctx, req.UserID, req.TeamName)
+	if err != nil {
+		s.teamMembershipError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamMembershipResponse(result))
+}
+
+func (s *GinService) teamMembershipError(c *gin.Context, err error) {
+	var teamNotFoundErr *domain.TeamNotFoundError
+	var userNotFoundErr *domain.UserNotFoundError
+	var notInTeamErr *domain.UserNotInTeamError
+	var hasPRsErr *domain.UserHasPullRequestsError
+	var conflictErr *domain.PullRequestConflictError
+	if errors.As(err, &teamNotFoundErr) || errors.As(err, &userNotFoundErr) || errors.As(err, &notInTeamErr) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+			Code:    NOT_FOUND,
+			Message: err.Error(),
+		}})
+	} else if errors.As(err, &hasPRsErr) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+			Code:    USER_HAS_PRS,
+			Message: err.Error(),
+		}})
+	} else if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+			Code:    PR_VERSION_CONFLICT,
+			Message: err.Error(),
+		}})
+	} else {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+			Code:    UNHANDLED_SERVER_ERROR,
+			Message: err.Error(),
+		}})
+	}
+}
+
+func (s *GinService) RenameTeam(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req RenameTeamRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	team, err := s.srv.RenameTeam(ctx, req.TeamName, req.NewTeamName)
+	if err != nil {
+		var teamExistsErr *domain.TeamExistsError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &teamExistsErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    TEAM_EXISTS,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	responseTeam := Team{
+		Name:             team.Name,
+		Members:          make([]Member, 0, len(team.Members)),
+		ReviewerStrategy: string(team.Settings.ReviewerStrategy),
+	}
+	for _, member := range team.Members {
+		responseTeam.Members = append(responseTeam.Members, Member{
+			Id:       member.Id,
+			Name:     member.Name,
+			IsActive: member.IsActive,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team": responseTeam,
+	})
+}
+
+func (s *GinService) DeleteTeam(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req DeleteTeamRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	err := s.srv.DeleteTeam(ctx, req.TeamName)
+	if err != nil {
+		var teamNotFoundErr *domain.TeamNotFoundError
+		var notEmptyErr *domain.TeamNotEmptyError
+		if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &notEmptyErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    TEAM_NOT_EMPTY,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name": req.TeamName,
+	})
+}
+
+type SetReviewerCountRequest struct {
+	TeamName      string `json:"team_name" binding:"required"`
+	ReviewerCount int    `json:"reviewer_count" binding:"required"`
+}
+
+func (s *GinService) SetTeamReviewerCount(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetReviewerCountRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	settings, err := s.srv.SetTeamReviewerCount(ctx, req.TeamName, req.ReviewerCount)
+	if err != nil {
+		var invalidCountErr *domain.InvalidReviewerCountError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &invalidCountErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamSettingsResponse(req.TeamName, settings))
+}
+
+// SetMergePolicyRequest replaces the whole policy, omitted fields are reset
+type SetMergePolicyRequest struct {
+	TeamName                string   `json:"team_name" binding:"required"`
+	MinApprovals            int      `json:"min_approvals"`
+	BlockOnChangesRequested bool     `json:"block_on_changes_requested"`
+	LeadIDs                 []string `json:"lead_ids"`
+}
+
+type MergePolicyResponse struct {
+	MinApprovals            int      `json:"min_approvals"`
+	BlockOnChangesRequested bool     `json:"block_on_changes_requested"`
+	LeadIDs                 []string `json:"lead_ids"`
+}
+
+func (s *GinService) SetTeamMergePolicy(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetMergePolicyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	settings, err := s.srv.SetTeamMergePolicy(ctx, req.TeamName, domain.MergePolicy{
+		MinApprovals:            req.MinApprovals,
+		BlockOnChangesRequested: req.BlockOnChangesRequested,
+		LeadIDs:                 req.LeadIDs,
+	})
+	if err != nil {
+		var invalidPolicyErr *domain.InvalidMergePolicyError
+		var notInTeamErr *domain.UserNotInTeamError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &invalidPolicyErr) || errors.As(err, &notInTeamErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamSettingsResponse(req.TeamName, settings))
+}
+
+type FillTeamReviewersRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+type ReviewerAdditionResponse struct {
+	PullRequestID  string   `json:"pull_request_id"`
+	AddedReviewers []string `json:"added_reviewers"`
+}
+
+type FillTeamReviewersResponse struct {
+	TeamName string                     `json:"team_name"`
+	Filled   []ReviewerAdditionResponse `json:"filled"`
+}
+
+func (s *GinService) FillTeamReviewers(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req FillTeamReviewersRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	additions, err := s.srv.FillTeamReviewers(ctx, req.TeamName)
+	if err != nil {
+		var teamNotFoundErr *domain.TeamNotFoundError
+		var conflictErr *domain.PullRequestConflictError
+		if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorBody{
+				Code:    PR_VERSION_CONFLICT,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	response := FillTeamReviewersResponse{
+		TeamName: req.TeamName,
+		Filled:   make([]ReviewerAdditionResponse, 0, len(additions)),
+	}
+	for _, addition := range additions {
+		response.Filled = append(response.Filled, ReviewerAdditionResponse{
+			PullRequestID:  addition.PullRequestID,
+			AddedReviewers: addition.AddedUserIDs,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetFallbackTeamsRequest replaces the whole list, an empty one removes the fallbacks
+type SetFallbackTeamsRequest struct {
+	TeamName      string   `json:"team_name" binding:"required"`
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+func (s *GinService) SetTeamFallbackTeams(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetFallbackTeamsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	settings, err := s.srv.SetTeamFallbackTeams(ctx, req.TeamName, req.FallbackTeams)
+	if err != nil {
+		var invalidFallbackErr *domain.InvalidFallbackTeamsError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &invalidFallbackErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamSettingsResponse(req.TeamName, settings))
+}
+
+type OwnershipRuleRequest struct {
+	Pattern string   `json:"pattern" binding:"required"`
+	Owners  []string `json:"owners"`
+}
+
+// SetOwnershipRulesRequest replaces the whole list of rules, given either as
+// rules or as CODEOWNERS text
+type SetOwnershipRulesRequest struct {
+	TeamName   string                 `json:"team_name" binding:"required"`
+	Rules      []OwnershipRuleRequest `json:"rules" binding:"dive"`
+	Codeowners string                 `json:"codeowners"`
+}
+
+func (s *GinService) SetTeamOwnershipRules(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req SetOwnershipRulesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+	if len(req.Rules) > 0 && req.Codeowners != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: "rules and codeowners are mutually exclusive",
+		}})
+		return
+	}
+
+	rules := make([]domain.OwnershipRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		owners := rule.Owners
+		if owners == nil {
+			owners = []string{}
+		}
+		rules = append(rules, domain.OwnershipRule{Pattern: rule.Pattern, Owners: owners})
+	}
+	if req.Codeowners != "" {
+		var err error
+		rules, err = domain.ParseCodeowners(req.Codeowners)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+			return
+		}
+	}
+
+	settings, err := s.srv.SetTeamOwnershipRules(ctx, req.TeamName, rules)
+	if err != nil {
+		var invalidRulesErr *domain.InvalidOwnershipRulesError
+		var teamNotFoundErr *domain.TeamNotFoundError
+		if errors.As(err, &invalidRulesErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &teamNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTeamSettingsResponse(req.TeamName, settings))
+}
+
+func newTeamSettingsResponse(teamName string, settings *domain.TeamSettings) TeamSettingsResponse {
+	return TeamSettingsResponse{
+		TeamName:         teamName,
+		ReviewerStrategy: string(settings.ReviewerStrategy),
+		ReviewerCount:    settings.ReviewerCount,
+		MergePolicy: MergePolicyResponse{
+			MinApprovals:            settings.MergePolicy.MinApprovals,
+			BlockOnChangesRequested: settings.MergePolicy.BlockOnChangesRequested,
+			LeadIDs:                 settings.MergePolicy.LeadIDs,
+		},
+		FallbackTeams:  settings.FallbackTeams,
+		OwnershipRules: newOwnershipRulesResponse(settings.OwnershipRules),
+	}
+}
+
+func newOwnershipRulesResponse(rules []domain.OwnershipRule) []OwnershipRuleResponse {
+	response := make([]OwnershipRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, OwnershipRuleResponse{
+			Pattern: rule.Pattern,
+			Owners:  rule.Owners,
+		})
+	}
+	return response
+}
+
+func newTeamMembershipResponse(result *domain.TeamMembershipResult) TeamMembershipResponse {
+	return TeamMembershipResponse{
+		User:          newUserResponse(result.User),
+		FromTeam:      result.FromTeam,
+		ToTeam:        result.ToTeam,
+		Reassignments: newReviewerReplacementsResponse(result.Replacements),
+	}
+}
+
+func newUserResponse(user domain.User) UserResponse {
+	return UserResponse{
+		UserID:         user.Id,
+		Username:       user.Name,
+		TeamName:       user.Team,
+		IsActive:       user.IsActive,
+		MaxOpenReviews: user.MaxOpenReviews,
+	}
+}
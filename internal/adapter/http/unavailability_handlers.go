@@ -0,0 +1,173 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+type AddUnavailabilityRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	// RFC 3339, ends_at не входит в период
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type UnavailabilityResponse struct {
+	ID       int64  `json:"id"`
+	UserID   string `json:"user_id"`
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+	Reason   string `json:"reason"`
+	// true когда OPEN ревью пользователя уже переданы другим
+	HandedOver bool `json:"handed_over"`
+}
+
+type AddUnavailabilityResponse struct {
+	Unavailability UnavailabilityResponse        `json:"unavailability"`
+	Reassignments  []ReviewerReplacementResponse `json:"reassignments"`
+}
+
+func (s *GinService) AddUnavailability(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req AddUnavailabilityRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	result, err := s.srv.AddUnavailability(ctx, &domain.Unavailability{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		var invalidErr *domain.InvalidUnavailabilityError
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &invalidErr) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+				Code:    BAD_REQUEST,
+				Message: err.Error(),
+			}})
+		} else if errors.As(err, &userNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, AddUnavailabilityResponse{
+		Unavailability: newUnavailabilityResponse(result.Unavailability),
+		Reassignments:  newReviewerReplacementsResponse(result.Replacements),
+	})
+}
+
+type GetUnavailabilityResponse struct {
+	UserID         string                   `json:"user_id"`
+	Unavailability []UnavailabilityResponse `json:"unavailability"`
+}
+
+func (s *GinService) GetUnavailability(c *gin.Context) {
+	ctx := requestContext(c)
+
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: "user_id query parameter is required",
+		}})
+		return
+	}
+
+	periods, err := s.srv.GetUnavailability(ctx, userID)
+	if err != nil {
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &userNotFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	response := GetUnavailabilityResponse{
+		UserID:         userID,
+		Unavailability: make([]UnavailabilityResponse, 0, len(periods)),
+	}
+	for _, period := range periods {
+		response.Unavailability = append(response.Unavailability, newUnavailabilityResponse(period))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type DeleteUnavailabilityRequest struct {
+	ID int64 `json:"id" binding:"required"`
+}
+
+func (s *GinService) DeleteUnavailability(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req DeleteUnavailabilityRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	err := s.srv.DeleteUnavailability(ctx, req.ID)
+	if err != nil {
+		var notFoundErr *domain.UnavailabilityNotFoundError
+		if errors.As(err, &notFoundErr) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+				Code:    NOT_FOUND,
+				Message: err.Error(),
+			}})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+				Code:    UNHANDLED_SERVER_ERROR,
+				Message: err.Error(),
+			}})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": req.ID,
+	})
+}
+
+func newUnavailabilityResponse(period domain.Unavailability) UnavailabilityResponse {
+	return UnavailabilityResponse{
+		ID:         period.ID,
+		UserID:     period.UserID,
+		StartsAt:   period.StartsAt.Format(time.RFC3339),
+		EndsAt:     period.EndsAt.Format(time.RFC3339),
+		Reason:     period.Reason,
+		HandedOver: period.HandedOver,
+	}
+}
@@ -0,0 +1,300 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+type CreateWebhookRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret" binding:"required"`
+	// Пустой список - все события
+	Events []string `json:"events"`
+	// Пустое имя - события всех команд
+	TeamName string `json:"team_name"`
+}
+
+type UpdateWebhookRequest struct {
+	ID       int64    `json:"id" binding:"required"`
+	URL      string   `json:"url" binding:"required"`
+	Secret   string   `json:"secret"`
+	Events   []string `json:"events"`
+	TeamName string   `json:"team_name"`
+	// nil оставляет подписку как есть
+	Active *bool `json:"active"`
+}
+
+type WebhookQuery struct {
+	ID int64 `form:"id" binding:"required"`
+}
+
+type WebhookDeliveriesQuery struct {
+	SubscriptionID int64 `form:"subscription_id" binding:"required"`
+	Limit          int   `form:"limit"`
+}
+
+type DeleteWebhookRequest struct {
+	ID int64 `json:"id" binding:"required"`
+}
+
+// WebhookResponse never contains the secret
+type WebhookResponse struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	TeamName  string   `json:"team_name"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type WebhookAttemptResponse struct {
+	Attempt int `json:"attempt"`
+	// 0 когда ответа не было
+	StatusCode  int    `json:"status_code"`
+	Error       string `json:"error"`
+	AttemptedAt string `json:"attempted_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            int64                    `json:"id"`
+	Event         string                   `json:"event"`
+	Status        string                   `json:"status"`
+	Payload       json.RawMessage          `json:"payload"`
+	NextAttemptAt string                   `json:"next_attempt_at"`
+	LastError     string                   `json:"last_error"`
+	CreatedAt     string                   `json:"created_at"`
+	DeliveredAt   *string                  `json:"delivered_at"`
+	Attempts      []WebhookAttemptResponse `json:"attempts"`
+}
+
+type WebhookDeliveriesResponse struct {
+	SubscriptionID int64                     `json:"subscription_id"`
+	Deliveries     []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+func (s *GinService) CreateWebhook(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	subscription, err := s.srv.CreateWebhook(ctx, &domain.WebhookSubscription{
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Events:   eventTypes(req.Events),
+		TeamName: req.TeamName,
+	})
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": newWebhookResponse(*subscription),
+	})
+}
+
+func (s *GinService) GetWebhook(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var query WebhookQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	subscription, err := s.srv.GetWebhook(ctx, query.ID)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": newWebhookResponse(*subscription),
+	})
+}
+
+func (s *GinService) ListWebhooks(c *gin.Context) {
+	ctx := requestContext(c)
+
+	subscriptions, err := s.srv.ListWebhooks(ctx)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	webhooks := make([]WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		webhooks = append(webhooks, newWebhookResponse(subscription))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": webhooks,
+	})
+}
+
+func (s *GinService) UpdateWebhook(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	updated, err := s.srv.UpdateWebhook(ctx, &domain.WebhookSubscription{
+		ID:       req.ID,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Events:   eventTypes(req.Events),
+		TeamName: req.TeamName,
+	}, req.Active)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": newWebhookResponse(*updated),
+	})
+}
+
+func (s *GinService) DeleteWebhook(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var req DeleteWebhookRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	if err := s.srv.DeleteWebhook(ctx, req.ID); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": req.ID,
+	})
+}
+
+// GetWebhookDeliveries lists the latest deliveries of a subscription with every attempt.
+func (s *GinService) GetWebhookDeliveries(c *gin.Context) {
+	ctx := requestContext(c)
+
+	var query WebhookDeliveriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+		return
+	}
+
+	logs, err := s.srv.GetWebhookDeliveries(ctx, query.SubscriptionID, query.Limit)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	response := WebhookDeliveriesResponse{
+		SubscriptionID: query.SubscriptionID,
+		Deliveries:     make([]WebhookDeliveryResponse, 0, len(logs)),
+	}
+	for _, log := range logs {
+		response.Deliveries = append(response.Deliveries, newWebhookDeliveryResponse(log))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+	var invalidErr *domain.InvalidWebhookError
+	var notFoundErr *domain.WebhookNotFoundError
+	if errors.As(err, &invalidErr) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorBody{
+			Code:    BAD_REQUEST,
+			Message: err.Error(),
+		}})
+	} else if errors.As(err, &notFoundErr) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorBody{
+			Code:    NOT_FOUND,
+			Message: err.Error(),
+		}})
+	} else {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorBody{
+			Code:    UNHANDLED_SERVER_ERROR,
+			Message: err.Error(),
+		}})
+	}
+}
+
+func eventTypes(events []string) []domain.EventType {
+	types := make([]domain.EventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, domain.EventType(event))
+	}
+	return types
+}
+
+func newWebhookResponse(subscription domain.WebhookSubscription) WebhookResponse {
+	response := WebhookResponse{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Events:    make([]string, 0, len(subscription.Events)),
+		TeamName:  subscription.TeamName,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt.Format(time.RFC3339),
+	}
+	for _, eventType := range subscription.Events {
+		response.Events = append(response.Events, string(eventType))
+	}
+	return response
+}
+
+func newWebhookDeliveryResponse(log domain.WebhookDeliveryLog) WebhookDeliveryResponse {
+	delivery := log.Delivery
+	response := WebhookDeliveryResponse{
+		ID:            delivery.ID,
+		Event:         string(delivery.EventType),
+		Status:        string(delivery.Status),
+		Payload:       delivery.Payload,
+		NextAttemptAt: delivery.NextAttemptAt.Format(time.RFC3339),
+		LastError:     delivery.LastError,
+		CreatedAt:     delivery.CreatedAt.Format(time.RFC3339),
+		Attempts:      make([]WebhookAttemptResponse, 0, len(log.Attempts)),
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format(time.RFC3339)
+		response.DeliveredAt = &deliveredAt
+	}
+	for _, attempt := range log.Attempts {
+		response.Attempts = append(response.Attempts, WebhookAttemptResponse{
+			Attempt:     attempt.Attempt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			AttemptedAt: attempt.AttemptedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryAssignmentHistoryTable struct {
+	Store *Store
+}
+
+func NewAssignmentHistoryRepo(store *Store) service.AssignmentHistoryRepository {
+	return &MemoryAssignmentHistoryTable{Store: store}
+}
+
+func (r *MemoryAssignmentHistoryTable) Add(ctx context.Context, record *domain.AssignmentRecord) (*domain.AssignmentRecord, error) {
+	defer r.Store.lock()()
+
+	if _, exists := r.Store.data.pullRequests[record.PullRequestID]; !exists {
+		return nil, fmt.Errorf("pull request not found: %s", record.PullRequestID)
+	}
+
+	r.Store.data.assignmentHistory = append(r.Store.data.assignmentHistory, copyAssignmentRecord(*record))
+
+	added := copyAssignmentRecord(*record)
+	return &added, nil
+}
+
+func (r *MemoryAssignmentHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	records := []domain.AssignmentRecord{}
+	for _, record := range r.Store.data.assignmentHistory {
+		if record.PullRequestID == prID {
+			records = append(records, copyAssignmentRecord(record))
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryAuditLogTable struct {
+	Store *Store
+}
+
+func NewAuditLogRepo(store *Store) service.AuditLogRepository {
+	return &MemoryAuditLogTable{Store: store}
+}
+
+func (r *MemoryAuditLogTable) Add(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error) {
+	defer r.Store.lock()()
+
+	added := copyAuditEntry(*entry)
+	added.ID = int64(len(r.Store.data.auditLog)) + 1
+	r.Store.data.auditLog = append(r.Store.data.auditLog, added)
+
+	added = copyAuditEntry(added)
+	return &added, nil
+}
+
+func (r *MemoryAuditLogTable) Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for _, entry := range r.Store.data.auditLog {
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+		if matchesAuditFilter(entry, filter) {
+			entries = append(entries, copyAuditEntry(entry))
+		}
+	}
+
+	return entries, nil
+}
+
+func matchesAuditFilter(entry domain.AuditEntry, filter domain.AuditFilter) bool {
+	switch {
+	case entry.ID <= filter.AfterID:
+		return false
+	case filter.Actor != "" && entry.Actor != filter.Actor:
+		return false
+	case filter.Action != "" && entry.Action != filter.Action:
+		return false
+	case filter.TargetType != "" && entry.TargetType != filter.TargetType:
+		return false
+	case filter.TargetID != "" && entry.TargetID != filter.TargetID:
+		return false
+	case filter.RequestID != "" && entry.RequestID != filter.RequestID:
+		return false
+	case filter.From != nil && entry.CreatedAt.Before(*filter.From):
+		return false
+	case filter.To != nil && !entry.CreatedAt.Before(*filter.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryPullRequestTable struct {
+	Store *Store
+}
+
+func NewPullRequestRepo(store *Store) service.PullRequestRepository {
+	return &MemoryPullRequestTable{Store: store}
+}
+
+func (p *MemoryPullRequestTable) Create(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
+	defer p.Store.lock(pullRequestsTable)()
+
+	if _, exists := p.Store.data.pullRequests[pr.ID]; exists {
+		return nil, &domain.PullRequestExistsError{PullRequestID: pr.ID}
+	}
+	if _, exists := p.Store.data.users[pr.AuthorID]; !exists {
+		return nil, fmt.Errorf("error inserting pull request %s: author %s does not exist", pr.ID, pr.AuthorID)
+	}
+
+	createdPR := copyPullRequest(*pr)
+	createdPR.Version = 1
+	p.Store.data.pullRequests[createdPR.ID] = createdPR
+
+	createdPR = copyPullRequest(createdPR)
+	return &createdPR, nil
+}
+
+func (p *MemoryPullRequestTable) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	pr, exists := p.Store.data.pullRequests[prID]
+	if !exists {
+		return nil, fmt.Errorf("pull request not found: %s", prID)
+	}
+
+	pr = copyPullRequest(pr)
+	return &pr, nil
+}
+
+func (p *MemoryPullRequestTable) Exists(ctx context.Context, prID string) (bool, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	_, exists := p.Store.data.pullRequests[prID]
+	return exists, nil
+}
+
+func (p *MemoryPullRequestTable) ExistsByAuthor(ctx context.Context, authorID string) (bool, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	for _, pr := range p.Store.data.pullRequests {
+		if pr.AuthorID == authorID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *MemoryPullRequestTable) Update(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
+	defer p.Store.lock(pullRequestsTable)()
+
+	current, exists := p.Store.data.pullRequests[pr.ID]
+	if !exists || current.Version != pr.Version {
+		return nil, &domain.PullRequestConflictError{PullRequestID: pr.ID, Version: pr.Version}
+	}
+
+	updatedPR := copyPullRequest(*pr)
+	updatedPR.Version = current.Version + 1
+	p.Store.data.pullRequests[updatedPR.ID] = updatedPR
+
+	updatedPR = copyPullRequest(updatedPR)
+	return &updatedPR, nil
+}
+
+func (p *MemoryPullRequestTable) GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	var prs []domain.PullRequest
+	for _, pr := range sortedPullRequests(p.Store.data.pullRequests) {
+		if slices.Contains(pr.AssignedReviewers, userID) {
+			prs = append(prs, pr)
+		}
+	}
+
+	// Newest first, as ORDER BY created_at DESC does
+	slices.Reverse(prs)
+	return prs, nil
+}
+
+func (p *MemoryPullRequestTable) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	counts := make(map[string]int, len(userIDs))
+	for _, pr := range p.Store.data.pullRequests {
+		if pr.Status != domain.PullRequestStatusOpen {
+			continue
+		}
+		for _, reviewer := range pr.AssignedReviewers {
+			if slices.Contains(userIDs, reviewer) {
+				counts[reviewer]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+func (p *MemoryPullRequestTable) GetOpenByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	var prs []domain.PullRequest
+	for _, pr := range sortedPullRequests(p.Store.data.pullRequests) {
+		if pr.Status != domain.PullRequestStatusOpen {
+			continue
+		}
+		if slices.ContainsFunc(pr.AssignedReviewers, func(reviewer string) bool {
+			return slices.Contains(userIDs, reviewer)
+		}) {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs, nil
+}
+
+func (p *MemoryPullRequestTable) GetOpenByAuthors(ctx context.Context, authorIDs []string) ([]domain.PullRequest, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	var prs []domain.PullRequest
+	for _, pr := range sortedPullRequests(p.Store.data.pullRequests) {
+		if pr.Status == domain.PullRequestStatusOpen && slices.Contains(authorIDs, pr.AuthorID) {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs, nil
+}
+
+// sortedPullRequests returns copies of prs ordered by created_at,
+// pull requests created at the same time are ordered by id.
+func sortedPullRequests(prs map[string]domain.PullRequest) []domain.PullRequest {
+	sorted := make([]domain.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		sorted = append(sorted, copyPullRequest(pr))
+	}
+	slices.SortFunc(sorted, func(a, b domain.PullRequest) int {
+		switch {
+		case a.CreatedAt == nil && b.CreatedAt != nil:
+			return 1
+		case a.CreatedAt != nil && b.CreatedAt == nil:
+			return -1
+		case a.CreatedAt != nil && b.CreatedAt != nil:
+			if c := a.CreatedAt.Compare(*b.CreatedAt); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return sorted
+}
@@ -0,0 +1,99 @@
+package memory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+	"github.com/raccoon00/avito-pr/internal/service/repotest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		return memory.NewRepositories(memory.NewStore())
+	})
+}
+
+func TestTxManagerRollback(t *testing.T) {
+	store := memory.NewStore()
+	txManager := memory.NewTxManager(store)
+	teams := memory.NewTeamRepo(store)
+
+	errRollback := errors.New("rollback")
+	err := txManager.WithinTx(t.Context(), func(repos service.Repositories) error {
+		if _, err := repos.Teams.Create(t.Context(), &domain.Team{Name: "rolled-back"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Logf("WithinTx should return the error of fn, got %v", err)
+		t.FailNow()
+	}
+
+	_, err = teams.Get(t.Context(), "rolled-back")
+	var teamNotFoundErr *domain.TeamNotFoundError
+	if !errors.As(err, &teamNotFoundErr) {
+		t.Logf("Rolled back team should not exist, got %v", err)
+		t.FailNow()
+	}
+
+	err = txManager.WithinTx(t.Context(), func(repos service.Repositories) error {
+		_, err := repos.Teams.Create(t.Context(), &domain.Team{Name: "committed"})
+		return err
+	})
+	if err != nil {
+		t.Logf("WithinTx should succeed, got %v", err)
+		t.FailNow()
+	}
+
+	if _, err = teams.Get(t.Context(), "committed"); err != nil {
+		t.Logf("Committed team should exist, got %v", err)
+		t.FailNow()
+	}
+}
+
+func TestTxManagerAppendOnlyRollback(t *testing.T) {
+	store := memory.NewStore()
+	txManager := memory.NewTxManager(store)
+	auditLog := memory.NewAuditLogRepo(store)
+
+	add := func(action domain.AuditAction, fail bool) {
+		errRollback := errors.New("rollback")
+		err := txManager.WithinTx(t.Context(), func(repos service.Repositories) error {
+			if _, err := repos.Audit.Add(t.Context(), &domain.AuditEntry{Action: action}); err != nil {
+				return err
+			}
+			// Readers of the committed data don't see the entry yet
+			entries, err := auditLog.Find(t.Context(), domain.AuditFilter{Action: action})
+			if err != nil || len(entries) != 0 {
+				t.Logf("Uncommitted entry should not be visible, got %v, %v", entries, err)
+				t.FailNow()
+			}
+			if fail {
+				return errRollback
+			}
+			return nil
+		})
+		if fail != errors.Is(err, errRollback) || !fail && err != nil {
+			t.Logf("Unexpected WithinTx result %v", err)
+			t.FailNow()
+		}
+	}
+
+	add("FIRST", false)
+	add("ROLLED_BACK", true)
+	add("SECOND", false)
+
+	entries, err := auditLog.Find(t.Context(), domain.AuditFilter{})
+	if err != nil {
+		t.Logf("Find should succeed, got %v", err)
+		t.FailNow()
+	}
+	if len(entries) != 2 || entries[0].Action != "FIRST" || entries[1].Action != "SECOND" || entries[1].ID != 2 {
+		t.Logf("Rolled back entry should be dropped, got %v", entries)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryReviewTable struct {
+	Store *Store
+}
+
+func NewReviewRepo(store *Store) service.ReviewRepository {
+	return &MemoryReviewTable{Store: store}
+}
+
+func (r *MemoryReviewTable) Upsert(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	defer r.Store.lock(reviewsTable)()
+
+	if _, exists := r.Store.data.pullRequests[review.PullRequestID]; !exists {
+		return nil, fmt.Errorf("pull request not found: %s", review.PullRequestID)
+	}
+
+	key := reviewKey{pullRequestID: review.PullRequestID, reviewerID: review.ReviewerID}
+	upserted := *review
+	if current, exists := r.Store.data.reviews[key]; exists {
+		upserted.CreatedAt = current.CreatedAt
+	}
+	r.Store.data.reviews[key] = upserted
+
+	return &upserted, nil
+}
+
+func (r *MemoryReviewTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.Review, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.filter(func(review domain.Review) bool {
+		return review.PullRequestID == prID
+	}), nil
+}
+
+func (r *MemoryReviewTable) GetByReviewer(ctx context.Context, userID string) ([]domain.Review, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.filter(func(review domain.Review) bool {
+		return review.ReviewerID == userID
+	}), nil
+}
+
+func (r *MemoryReviewTable) DeleteByReviewers(ctx context.Context, prID string, reviewerIDs []string) error {
+	defer r.Store.lock(reviewsTable)()
+
+	for _, reviewerID := range reviewerIDs {
+		delete(r.Store.data.reviews, reviewKey{pullRequestID: prID, reviewerID: reviewerID})
+	}
+
+	return nil
+}
+
+// filter returns the matching reviews ordered by created_at,
+// reviews created at the same time are ordered by key.
+func (r *MemoryReviewTable) filter(match func(review domain.Review) bool) []domain.Review {
+	reviews := []domain.Review{}
+	for _, review := range r.Store.data.reviews {
+		if match(review) {
+			reviews = append(reviews, review)
+		}
+	}
+	slices.SortFunc(reviews, func(a, b domain.Review) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.PullRequestID, b.PullRequestID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ReviewerID, b.ReviewerID)
+	})
+	return reviews
+}
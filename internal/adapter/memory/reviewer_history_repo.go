@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryReviewerHistoryTable struct {
+	Store *Store
+}
+
+func NewReviewerHistoryRepo(store *Store) service.ReviewerHistoryRepository {
+	return &MemoryReviewerHistoryTable{Store: store}
+}
+
+func (r *MemoryReviewerHistoryTable) Add(ctx context.Context, change *domain.ReviewerChange) (*domain.ReviewerChange, error) {
+	defer r.Store.lock()()
+
+	if _, exists := r.Store.data.pullRequests[change.PullRequestID]; !exists {
+		return nil, fmt.Errorf("pull request not found: %s", change.PullRequestID)
+	}
+
+	added := *change
+	r.Store.data.reviewerHistory = append(r.Store.data.reviewerHistory, added)
+
+	return &added, nil
+}
+
+func (r *MemoryReviewerHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.ReviewerChange, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	changes := []domain.ReviewerChange{}
+	for _, change := range r.Store.data.reviewerHistory {
+		if change.PullRequestID == prID {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
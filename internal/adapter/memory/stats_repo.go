@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryStatsTables struct {
+	Store *Store
+}
+
+func NewStatsRepo(store *Store) service.StatsRepository {
+	return &MemoryStatsTables{Store: store}
+}
+
+func (s *MemoryStatsTables) GetUserAssignmentStats(ctx context.Context, teamName string) ([]domain.UserAssignmentStats, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	stats := []domain.UserAssignmentStats{}
+	for _, user := range sortedUsers(s.Store.data.users) {
+		if teamName != "" && user.Team != teamName {
+			continue
+		}
+
+		stat := domain.UserAssignmentStats{UserID: user.Id, Username: user.Name, TeamName: user.Team}
+		for _, pr := range s.Store.data.pullRequests {
+			if !slices.Contains(pr.AssignedReviewers, user.Id) {
+				continue
+			}
+			stat.Assigned++
+			switch pr.Status {
+			case domain.PullRequestStatusOpen:
+				stat.Open++
+			case domain.PullRequestStatusMerged:
+				stat.Merged++
+			case domain.PullRequestStatusDraft:
+				stat.Draft++
+			case domain.PullRequestStatusClosed:
+				stat.Closed++
+			}
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+func (s *MemoryStatsTables) GetPullRequestAssignmentStats(ctx context.Context, teamName string) ([]domain.PullRequestAssignmentStats, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	prs := sortedPullRequests(s.Store.data.pullRequests)
+	slices.Reverse(prs)
+
+	stats := []domain.PullRequestAssignmentStats{}
+	for _, pr := range prs {
+		author, exists := s.Store.data.users[pr.AuthorID]
+		if !exists || (teamName != "" && author.Team != teamName) {
+			continue
+		}
+
+		stats = append(stats, domain.PullRequestAssignmentStats{
+			PullRequestID:   pr.ID,
+			PullRequestName: pr.Name,
+			AuthorID:        pr.AuthorID,
+			Status:          pr.Status,
+			ReviewersCount:  len(pr.AssignedReviewers),
+		})
+	}
+
+	return stats, nil
+}
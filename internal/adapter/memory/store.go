@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// tables holds the rows of every "table", keyed by primary key.
+type tables struct {
+	teams        map[string]domain.TeamSettings
+	users        map[string]domain.User
+	pullRequests map[string]domain.PullRequest
+	reviews      map[reviewKey]domain.Review
+	// reviewerHistory is append-only, its order is the insertion order
+	reviewerHistory []domain.ReviewerChange
+	// assignmentHistory is append-only as well, records are never changed
+	assignmentHistory []domain.AssignmentRecord
+	// auditLog is append-only and ordered by ID
+	auditLog       []domain.AuditEntry
+	unavailability map[int64]domain.Unavailability
+	// lastUnavailabilityID plays the role of the id sequence
+	lastUnavailabilityID int64
+	webhooks             map[int64]domain.WebhookSubscription
+	lastWebhookID        int64
+	deliveries           map[int64]domain.WebhookDelivery
+	lastDeliveryID       int64
+	// deliveryAttempts is append-only, attempts of deleted deliveries are dropped
+	deliveryAttempts []domain.WebhookAttempt
+}
+
+// reviewKey is the primary key of the reviews table.
+type reviewKey struct {
+	pullRequestID string
+	reviewerID    string
+}
+
+// table names a table that a transaction copies before changing it.
+type table int
+
+const (
+	teamsTable table = iota
+	usersTable
+	pullRequestsTable
+	reviewsTable
+	unavailabilityTable
+	webhooksTable
+	deliveriesTable
+	// deliveryAttemptsTable is only needed to drop attempts, appended rows
+	// lie past the end of the committed slice
+	deliveryAttemptsTable
+)
+
+// copyTable gives t its own copy of the table. Rows are replaced and never
+// changed in place, so the copy is shallow.
+func (t *tables) copyTable(name table) {
+	switch name {
+	case teamsTable:
+		t.teams = maps.Clone(t.teams)
+	case usersTable:
+		t.users = maps.Clone(t.users)
+	case pullRequestsTable:
+		t.pullRequests = maps.Clone(t.pullRequests)
+	case reviewsTable:
+		t.reviews = maps.Clone(t.reviews)
+	case unavailabilityTable:
+		t.unavailability = maps.Clone(t.unavailability)
+	case webhooksTable:
+		t.webhooks = maps.Clone(t.webhooks)
+	case deliveriesTable:
+		t.deliveries = maps.Clone(t.deliveries)
+	case deliveryAttemptsTable:
+		t.deliveryAttempts = slices.Clone(t.deliveryAttempts)
+	}
+}
+
+// Store keeps all data in memory and is safe for concurrent use.
+// Repositories created from the same Store share the data.
+type Store struct {
+	mu   sync.RWMutex
+	data *tables
+
+	// txMu serializes transactions, so a transaction never sees
+	// changes of another one that is not committed yet
+	txMu sync.Mutex
+	// copied is set in transactions only and marks the tables copied
+	// by the transaction, the others are shared with the committed data
+	copied map[table]bool
+}
+
+// lock locks the store for a change of the changed tables and returns the
+// unlock function. A transaction copies a table before its first change.
+// Append-only tables are appended to in place: rows past the end of the
+// committed slice are not visible to its readers, and a rolled back
+// transaction leaves the committed slice as it was. Outside transactions
+// the change waits for the running transaction, which shares the tables.
+func (s *Store) lock(changed ...table) (unlock func()) {
+	inTx := s.copied != nil
+	if !inTx {
+		s.txMu.Lock()
+	}
+	s.mu.Lock()
+
+	for _, name := range changed {
+		if inTx && !s.copied[name] {
+			s.data.copyTable(name)
+			s.copied[name] = true
+		}
+	}
+
+	return func() {
+		s.mu.Unlock()
+		if !inTx {
+			s.txMu.Unlock()
+		}
+	}
+}
+
+func NewStore() *Store {
+	return &Store{data: &tables{
+		teams:        map[string]domain.TeamSettings{},
+		users:        map[string]domain.User{},
+		pullRequests: map[string]domain.PullRequest{},
+		reviews:      map[reviewKey]domain.Review{},
+
+		unavailability: map[int64]domain.Unavailability{},
+		webhooks:       map[int64]domain.WebhookSubscription{},
+		deliveries:     map[int64]domain.WebhookDelivery{},
+	}}
+}
+
+// NewRepositories binds all repositories to store, which is either the shared store or a transaction snapshot.
+func NewRepositories(store *Store) service.Repositories {
+	return service.Repositories{
+		Teams:           NewTeamRepo(store),
+		Users:           NewUserRepo(store),
+		PullRequests:    NewPullRequestRepo(store),
+		Reviews:         NewReviewRepo(store),
+		ReviewerHistory: NewReviewerHistoryRepo(store),
+		Unavailability:  NewUnavailabilityRepo(store),
+		Assignments:     NewAssignmentHistoryRepo(store),
+		Audit:           NewAuditLogRepo(store),
+		Webhooks:        NewWebhookRepo(store),
+		Deliveries:      NewWebhookDeliveryRepo(store),
+	}
+}
+
+type MemoryTxManager struct {
+	Store *Store
+}
+
+func NewTxManager(store *Store) service.TxManager {
+	return &MemoryTxManager{Store: store}
+}
+
+// WithinTx runs fn against a private view of the data, which copies only
+// the tables fn changes, and replaces the shared data with it only when fn
+// succeeds.
+func (m *MemoryTxManager) WithinTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	m.Store.txMu.Lock()
+	defer m.Store.txMu.Unlock()
+
+	m.Store.mu.RLock()
+	data := *m.Store.data
+	m.Store.mu.RUnlock()
+	tx := &Store{data: &data, copied: map[table]bool{}}
+
+	if err := fn(NewRepositories(tx)); err != nil {
+		return err
+	}
+
+	m.Store.mu.Lock()
+	m.Store.data = tx.data
+	m.Store.mu.Unlock()
+
+	return nil
+}
+
+// Rows are copied on the way in and out, so callers never share
+// slices or pointers with the stored data.
+
+func copyTeamSettings(settings domain.TeamSettings) domain.TeamSettings {
+	// Like the NOT NULL DEFAULT '{}' column, leads are never nil
+	settings.MergePolicy.LeadIDs = append([]string{}, settings.MergePolicy.LeadIDs...)
+	settings.FallbackTeams = append([]string{}, settings.FallbackTeams...)
+	rules := make([]domain.OwnershipRule, 0, len(settings.OwnershipRules))
+	for _, rule := range settings.OwnershipRules {
+		rules = append(rules, domain.OwnershipRule{Pattern: rule.Pattern, Owners: append([]string{}, rule.Owners...)})
+	}
+	settings.OwnershipRules = rules
+	return settings
+}
+
+func copyUser(user domain.User) domain.User {
+	if user.MaxOpenReviews != nil {
+		maxOpenReviews := *user.MaxOpenReviews
+		user.MaxOpenReviews = &maxOpenReviews
+	}
+	return user
+}
+
+func copyPullRequest(pr domain.PullRequest) domain.PullRequest {
+	// Like the NOT NULL DEFAULT '{}' column, reviewers are never nil
+	pr.AssignedReviewers = append([]string{}, pr.AssignedReviewers...)
+	pr.ChangedPaths = append([]string{}, pr.ChangedPaths...)
+	if pr.CreatedAt != nil {
+		createdAt := *pr.CreatedAt
+		pr.CreatedAt = &createdAt
+	}
+	if pr.MergedAt != nil {
+		mergedAt := *pr.MergedAt
+		pr.MergedAt = &mergedAt
+	}
+	// Reviews are rows of their own table, choices are not stored
+	pr.Reviews = nil
+	pr.ReviewerChoices = nil
+	return pr
+}
+
+func copyAssignmentRecord(record domain.AssignmentRecord) domain.AssignmentRecord {
+	record.Candidates = append([]string{}, record.Candidates...)
+	record.Excluded = append([]domain.ExcludedCandidate{}, record.Excluded...)
+	record.Chosen = append([]string{}, record.Chosen...)
+	return record
+}
+
+func copyAuditEntry(entry domain.AuditEntry) domain.AuditEntry {
+	entry.Before = slices.Clone(entry.Before)
+	entry.After = slices.Clone(entry.After)
+	return entry
+}
+
+func copyWebhookSubscription(subscription domain.WebhookSubscription) domain.WebhookSubscription {
+	subscription.Events = append([]domain.EventType{}, subscription.Events...)
+	return subscription
+}
+
+func copyWebhookDelivery(delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		delivery.DeliveredAt = &deliveredAt
+	}
+	return delivery
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryTeamTable struct {
+	Store *Store
+}
+
+func NewTeamRepo(store *Store) service.TeamRepository {
+	return &MemoryTeamTable{Store: store}
+}
+
+func (t *MemoryTeamTable) Create(ctx context.Context, team *domain.Team) (*domain.Team, error) {
+	defer t.Store.lock(teamsTable, usersTable)()
+
+	if _, exists := t.Store.data.teams[team.Name]; exists {
+		return nil, &domain.TeamExistsError{TeamName: team.Name}
+	}
+	t.Store.data.teams[team.Name] = copyTeamSettings(team.Settings)
+
+	newTeam := domain.Team{
+		Name:     team.Name,
+		Members:  make([]domain.User, 0, len(team.Members)),
+		Settings: copyTeamSettings(team.Settings),
+	}
+
+	for _, member := range team.Members {
+		if _, exists := t.Store.data.users[member.Id]; exists {
+			return &newTeam, fmt.Errorf("error inserting user %s: user already exists", member.Id)
+		}
+
+		user := copyUser(member)
+		t.Store.data.users[user.Id] = user
+		newTeam.Members = append(newTeam.Members, copyUser(user))
+	}
+
+	return &newTeam, nil
+}
+
+func (t *MemoryTeamTable) Get(ctx context.Context, teamName string) (*domain.Team, error) {
+	t.Store.mu.RLock()
+	defer t.Store.mu.RUnlock()
+
+	settings, exists := t.Store.data.teams[teamName]
+	if !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	team := &domain.Team{
+		Name:     teamName,
+		Members:  []domain.User{},
+		Settings: copyTeamSettings(settings),
+	}
+
+	for _, user := range sortedUsers(t.Store.data.users) {
+		if user.Team == teamName {
+			team.Members = append(team.Members, user)
+		}
+	}
+
+	return team, nil
+}
+
+func (t *MemoryTeamTable) List(ctx context.Context) ([]domain.Team, error) {
+	t.Store.mu.RLock()
+	defer t.Store.mu.RUnlock()
+
+	teamIdx := make(map[string]int, len(t.Store.data.teams))
+	teams := make([]domain.Team, 0, len(t.Store.data.teams))
+	for name, settings := range t.Store.data.teams {
+		teams = append(teams, domain.Team{Name: name, Members: []domain.User{}, Settings: copyTeamSettings(settings)})
+	}
+	slices.SortFunc(teams, func(a, b domain.Team) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i, team := range teams {
+		teamIdx[team.Name] = i
+	}
+
+	for _, user := range sortedUsers(t.Store.data.users) {
+		i := teamIdx[user.Team]
+		teams[i].Members = append(teams[i].Members, user)
+	}
+
+	return teams, nil
+}
+
+func (t *MemoryTeamTable) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	t.Store.mu.RLock()
+	defer t.Store.mu.RUnlock()
+
+	settings, exists := t.Store.data.teams[teamName]
+	if !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	settings = copyTeamSettings(settings)
+	return &settings, nil
+}
+
+// GetSettingsForUpdate needs no lock, transactions are serialized.
+func (t *MemoryTeamTable) GetSettingsForUpdate(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	return t.GetSettings(ctx, teamName)
+}
+
+func (t *MemoryTeamTable) UpdateSettings(ctx context.Context, teamName string, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	defer t.Store.lock(teamsTable)()
+
+	if _, exists := t.Store.data.teams[teamName]; !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+	t.Store.data.teams[teamName] = copyTeamSettings(*settings)
+
+	updatedSettings := copyTeamSettings(*settings)
+	return &updatedSettings, nil
+}
+
+func (t *MemoryTeamTable) Rename(ctx context.Context, teamName string, newName string) (*domain.Team, error) {
+	defer t.Store.lock(teamsTable, usersTable)()
+
+	settings, exists := t.Store.data.teams[teamName]
+	if !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+	if _, taken := t.Store.data.teams[newName]; taken {
+		return nil, &domain.TeamExistsError{TeamName: newName}
+	}
+
+	delete(t.Store.data.teams, teamName)
+	t.Store.data.teams[newName] = settings
+
+	team := &domain.Team{
+		Name:     newName,
+		Members:  []domain.User{},
+		Settings: copyTeamSettings(settings),
+	}
+	for _, user := range sortedUsers(t.Store.data.users) {
+		if user.Team != teamName {
+			continue
+		}
+		user.Team = newName
+		t.Store.data.users[user.Id] = copyUser(user)
+		team.Members = append(team.Members, user)
+	}
+
+	return team, nil
+}
+
+func (t *MemoryTeamTable) Delete(ctx context.Context, teamName string) error {
+	defer t.Store.lock(teamsTable)()
+
+	if _, exists := t.Store.data.teams[teamName]; !exists {
+		return &domain.TeamNotFoundError{TeamName: teamName}
+	}
+	// Like the users.team_name foreign key, members keep the team alive
+	for _, user := range t.Store.data.users {
+		if user.Team == teamName {
+			return &domain.TeamNotEmptyError{TeamName: teamName}
+		}
+	}
+
+	delete(t.Store.data.teams, teamName)
+	return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryUnavailabilityTable struct {
+	Store *Store
+}
+
+func NewUnavailabilityRepo(store *Store) service.UnavailabilityRepository {
+	return &MemoryUnavailabilityTable{Store: store}
+}
+
+func (r *MemoryUnavailabilityTable) Create(ctx context.Context, unavailability *domain.Unavailability) (*domain.Unavailability, error) {
+	defer r.Store.lock(unavailabilityTable)()
+
+	if _, exists := r.Store.data.users[unavailability.UserID]; !exists {
+		return nil, &domain.UserNotFoundError{UserID: unavailability.UserID}
+	}
+
+	r.Store.data.lastUnavailabilityID++
+	created := *unavailability
+	created.ID = r.Store.data.lastUnavailabilityID
+	r.Store.data.unavailability[created.ID] = created
+
+	return &created, nil
+}
+
+func (r *MemoryUnavailabilityTable) GetByID(ctx context.Context, id int64) (*domain.Unavailability, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	unavailability, exists := r.Store.data.unavailability[id]
+	if !exists {
+		return nil, &domain.UnavailabilityNotFoundError{ID: id}
+	}
+
+	return &unavailability, nil
+}
+
+func (r *MemoryUnavailabilityTable) GetByUser(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.Store.data.sortedUnavailability(func(u domain.Unavailability) bool {
+		return u.UserID == userID
+	}), nil
+}
+
+func (r *MemoryUnavailabilityTable) Delete(ctx context.Context, id int64) error {
+	defer r.Store.lock(unavailabilityTable)()
+
+	if _, exists := r.Store.data.unavailability[id]; !exists {
+		return &domain.UnavailabilityNotFoundError{ID: id}
+	}
+
+	delete(r.Store.data.unavailability, id)
+	return nil
+}
+
+func (r *MemoryUnavailabilityTable) GetPendingHandOvers(ctx context.Context, at time.Time) ([]domain.Unavailability, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.Store.data.sortedUnavailability(func(u domain.Unavailability) bool {
+		return !u.HandedOver && u.Covers(at)
+	}), nil
+}
+
+func (r *MemoryUnavailabilityTable) MarkHandedOver(ctx context.Context, id int64) error {
+	defer r.Store.lock(unavailabilityTable)()
+
+	unavailability, exists := r.Store.data.unavailability[id]
+	if !exists {
+		return &domain.UnavailabilityNotFoundError{ID: id}
+	}
+	if unavailability.HandedOver {
+		return &domain.UnavailabilityHandedOverError{ID: id}
+	}
+
+	unavailability.HandedOver = true
+	r.Store.data.unavailability[id] = unavailability
+	return nil
+}
+
+// sortedUnavailability returns the periods matching keep ordered by starts_at and id.
+func (t *tables) sortedUnavailability(keep func(domain.Unavailability) bool) []domain.Unavailability {
+	sorted := []domain.Unavailability{}
+	for _, unavailability := range t.unavailability {
+		if keep(unavailability) {
+			sorted = append(sorted, unavailability)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b domain.Unavailability) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.ID, b.ID))
+	})
+	return sorted
+}
+
+// isUnavailable reports whether a period of the user covers at.
+func (t *tables) isUnavailable(userID string, at time.Time) bool {
+	for _, unavailability := range t.unavailability {
+		if unavailability.UserID == userID && unavailability.Covers(at) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryUserTable struct {
+	Store *Store
+}
+
+func NewUserRepo(store *Store) service.UserRepository {
+	return &MemoryUserTable{Store: store}
+}
+
+func (u *MemoryUserTable) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	defer u.Store.lock(usersTable)()
+
+	user, exists := u.Store.data.users[userID]
+	if !exists {
+		return nil, &domain.UserNotFoundError{UserID: userID}
+	}
+
+	user.IsActive = isActive
+	u.Store.data.users[userID] = user
+
+	updatedUser := copyUser(user)
+	return &updatedUser, nil
+}
+
+func (u *MemoryUserTable) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	user, exists := u.Store.data.users[userID]
+	if !exists {
+		return nil, &domain.UserNotFoundError{UserID: userID}
+	}
+
+	foundUser := copyUser(user)
+	return &foundUser, nil
+}
+
+func (u *MemoryUserTable) GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserID string) ([]domain.User, error) {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	now := time.Now()
+	var users []domain.User
+	for _, user := range sortedUsers(u.Store.data.users) {
+		if user.Team == teamName && user.IsActive && user.Id != excludeUserID && !u.Store.data.isUnavailable(user.Id, now) {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+func (u *MemoryUserTable) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (*domain.User, error) {
+	defer u.Store.lock(usersTable)()
+
+	user, exists := u.Store.data.users[userID]
+	if !exists {
+		return nil, &domain.UserNotFoundError{UserID: userID}
+	}
+
+	user.MaxOpenReviews = maxOpenReviews
+	user = copyUser(user)
+	u.Store.data.users[userID] = user
+
+	updatedUser := copyUser(user)
+	return &updatedUser, nil
+}
+
+func (u *MemoryUserTable) DeactivateUsers(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	defer u.Store.lock(usersTable)()
+
+	users := []domain.User{}
+	for _, user := range sortedUsers(u.Store.data.users) {
+		if !slices.Contains(userIDs, user.Id) {
+			continue
+		}
+
+		user.IsActive = false
+		u.Store.data.users[user.Id] = copyUser(user)
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (u *MemoryUserTable) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	defer u.Store.lock(usersTable)()
+
+	if _, exists := u.Store.data.users[user.Id]; exists {
+		return nil, &domain.UserExistsError{UserID: user.Id}
+	}
+	if _, exists := u.Store.data.teams[user.Team]; !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: user.Team}
+	}
+
+	createdUser := copyUser(*user)
+	u.Store.data.users[createdUser.Id] = createdUser
+
+	createdUser = copyUser(createdUser)
+	return &createdUser, nil
+}
+
+func (u *MemoryUserTable) Upsert(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	defer u.Store.lock(usersTable)()
+
+	if _, exists := u.Store.data.teams[user.Team]; !exists {
+		return nil, false, &domain.TeamNotFoundError{TeamName: user.Team}
+	}
+
+	upsertedUser, exists := u.Store.data.users[user.Id]
+	if !exists {
+		upsertedUser = domain.User{Id: user.Id}
+	}
+	upsertedUser.Name = user.Name
+	upsertedUser.IsActive = user.IsActive
+	upsertedUser.Team = user.Team
+	u.Store.data.users[user.Id] = upsertedUser
+
+	upsertedUser = copyUser(upsertedUser)
+	return &upsertedUser, !exists, nil
+}
+
+func (u *MemoryUserTable) SetTeam(ctx context.Context, userID string, teamName string) (*domain.User, error) {
+	defer u.Store.lock(usersTable)()
+
+	user, exists := u.Store.data.users[userID]
+	if !exists {
+		return nil, &domain.UserNotFoundError{UserID: userID}
+	}
+	if _, exists := u.Store.data.teams[teamName]; !exists {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	user.Team = teamName
+	u.Store.data.users[userID] = user
+
+	updatedUser := copyUser(user)
+	return &updatedUser, nil
+}
+
+func (u *MemoryUserTable) Delete(ctx context.Context, userID string) error {
+	defer u.Store.lock(usersTable, unavailabilityTable)()
+
+	if _, exists := u.Store.data.users[userID]; !exists {
+		return &domain.UserNotFoundError{UserID: userID}
+	}
+	// Like the pr_requests.author_id foreign key, authored PRs keep the user alive
+	for _, pr := range u.Store.data.pullRequests {
+		if pr.AuthorID == userID {
+			return &domain.UserHasPullRequestsError{UserID: userID}
+		}
+	}
+
+	delete(u.Store.data.users, userID)
+	// Like ON DELETE CASCADE on user_unavailability.user_id
+	for id, unavailability := range u.Store.data.unavailability {
+		if unavailability.UserID == userID {
+			delete(u.Store.data.unavailability, id)
+		}
+	}
+	return nil
+}
+
+// sortedUsers returns copies of users ordered by user_id.
+func sortedUsers(users map[string]domain.User) []domain.User {
+	sorted := make([]domain.User, 0, len(users))
+	for _, user := range users {
+		sorted = append(sorted, copyUser(user))
+	}
+	slices.SortFunc(sorted, func(a, b domain.User) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return sorted
+}
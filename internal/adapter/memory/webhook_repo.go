@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type MemoryWebhookTable struct {
+	Store *Store
+}
+
+func NewWebhookRepo(store *Store) service.WebhookRepository {
+	return &MemoryWebhookTable{Store: store}
+}
+
+func (r *MemoryWebhookTable) Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	defer r.Store.lock(webhooksTable)()
+
+	r.Store.data.lastWebhookID++
+	created := copyWebhookSubscription(*subscription)
+	created.ID = r.Store.data.lastWebhookID
+	r.Store.data.webhooks[created.ID] = created
+
+	created = copyWebhookSubscription(created)
+	return &created, nil
+}
+
+func (r *MemoryWebhookTable) Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	subscription, exists := r.Store.data.webhooks[id]
+	if !exists {
+		return nil, &domain.WebhookNotFoundError{ID: id}
+	}
+
+	subscription = copyWebhookSubscription(subscription)
+	return &subscription, nil
+}
+
+func (r *MemoryWebhookTable) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for _, id := range slices.Sorted(maps.Keys(r.Store.data.webhooks)) {
+		subscriptions = append(subscriptions, copyWebhookSubscription(r.Store.data.webhooks[id]))
+	}
+	return subscriptions, nil
+}
+
+func (r *MemoryWebhookTable) Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	defer r.Store.lock(webhooksTable)()
+
+	current, exists := r.Store.data.webhooks[subscription.ID]
+	if !exists {
+		return nil, &domain.WebhookNotFoundError{ID: subscription.ID}
+	}
+
+	updated := copyWebhookSubscription(*subscription)
+	updated.CreatedAt = current.CreatedAt
+	r.Store.data.webhooks[updated.ID] = updated
+
+	updated = copyWebhookSubscription(updated)
+	return &updated, nil
+}
+
+func (r *MemoryWebhookTable) Delete(ctx context.Context, id int64) error {
+	defer r.Store.lock(webhooksTable, deliveriesTable, deliveryAttemptsTable)()
+
+	if _, exists := r.Store.data.webhooks[id]; !exists {
+		return &domain.WebhookNotFoundError{ID: id}
+	}
+
+	// Like ON DELETE CASCADE, the delivery log goes with the subscription
+	delete(r.Store.data.webhooks, id)
+	for deliveryID, delivery := range r.Store.data.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(r.Store.data.deliveries, deliveryID)
+		}
+	}
+	r.Store.data.deliveryAttempts = slices.DeleteFunc(r.Store.data.deliveryAttempts, func(attempt domain.WebhookAttempt) bool {
+		_, exists := r.Store.data.deliveries[attempt.DeliveryID]
+		return !exists
+	})
+	return nil
+}
+
+type MemoryWebhookDeliveryTable struct {
+	Store *Store
+}
+
+func NewWebhookDeliveryRepo(store *Store) service.WebhookDeliveryRepository {
+	return &MemoryWebhookDeliveryTable{Store: store}
+}
+
+func (r *MemoryWebhookDeliveryTable) Create(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	defer r.Store.lock(deliveriesTable)()
+
+	if _, exists := r.Store.data.webhooks[delivery.SubscriptionID]; !exists {
+		return nil, &domain.WebhookNotFoundError{ID: delivery.SubscriptionID}
+	}
+
+	r.Store.data.lastDeliveryID++
+	created := copyWebhookDelivery(*delivery)
+	created.ID = r.Store.data.lastDeliveryID
+	r.Store.data.deliveries[created.ID] = created
+
+	created = copyWebhookDelivery(created)
+	return &created, nil
+}
+
+func (r *MemoryWebhookDeliveryTable) ClaimDue(ctx context.Context, at, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	defer r.Store.lock(deliveriesTable)()
+
+	due := []domain.WebhookDelivery{}
+	for _, delivery := range r.Store.data.deliveries {
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt.After(at) {
+			continue
+		}
+		if subscription := r.Store.data.webhooks[delivery.SubscriptionID]; subscription.Active {
+			due = append(due, delivery)
+		}
+	}
+	slices.SortFunc(due, func(a, b domain.WebhookDelivery) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = until
+		r.Store.data.deliveries[due[i].ID] = due[i]
+		due[i] = copyWebhookDelivery(due[i])
+	}
+	return due, nil
+}
+
+func (r *MemoryWebhookDeliveryTable) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	defer r.Store.lock(deliveriesTable)()
+
+	current, exists := r.Store.data.deliveries[delivery.ID]
+	if !exists {
+		return fmt.Errorf("webhook delivery not found: %d", delivery.ID)
+	}
+
+	current.Status = delivery.Status
+	current.Attempts = delivery.Attempts
+	current.NextAttemptAt = delivery.NextAttemptAt
+	current.LastError = delivery.LastError
+	current.DeliveredAt = delivery.DeliveredAt
+	r.Store.data.deliveries[delivery.ID] = copyWebhookDelivery(current)
+
+	recorded := *attempt
+	recorded.DeliveryID = delivery.ID
+	r.Store.data.deliveryAttempts = append(r.Store.data.deliveryAttempts, recorded)
+	return nil
+}
+
+func (r *MemoryWebhookDeliveryTable) GetBySubscription(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDeliveryLog, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range r.Store.data.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	logs := make([]domain.WebhookDeliveryLog, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		log := domain.WebhookDeliveryLog{Delivery: copyWebhookDelivery(delivery), Attempts: []domain.WebhookAttempt{}}
+		for _, attempt := range r.Store.data.deliveryAttempts {
+			if attempt.DeliveryID == delivery.ID {
+				log.Attempts = append(log.Attempts, attempt)
+			}
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const assignmentRecordColumns = "pull_request_id, cause, COALESCE(replaced_user_id, ''), pool, team_name, strategy, candidates, excluded, chosen, decided_at"
+
+type PostgresAssignmentHistoryTable struct {
+	Conn         DBTX
+	HistoryTable string
+}
+
+func NewAssignmentHistoryRepo(
+	conn DBTX,
+	historyTable string,
+) service.AssignmentHistoryRepository {
+	return &PostgresAssignmentHistoryTable{Conn: conn, HistoryTable: historyTable}
+}
+
+// excludedCandidateRow is an element of the excluded JSON array.
+type excludedCandidateRow struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+func (r *PostgresAssignmentHistoryTable) Add(ctx context.Context, record *domain.AssignmentRecord) (*domain.AssignmentRecord, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (pull_request_id, cause, replaced_user_id, pool, team_name, strategy, candidates, excluded, chosen, decided_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10)
+		RETURNING %s`,
+		r.HistoryTable, assignmentRecordColumns,
+	)
+
+	// A nil slice would be sent as NULL, the columns are NOT NULL
+	candidates := record.Candidates
+	if candidates == nil {
+		candidates = []string{}
+	}
+	chosen := record.Chosen
+	if chosen == nil {
+		chosen = []string{}
+	}
+	excluded := make([]excludedCandidateRow, 0, len(record.Excluded))
+	for _, candidate := range record.Excluded {
+		excluded = append(excluded, excludedCandidateRow{UserID: candidate.UserID, Reason: string(candidate.Reason)})
+	}
+
+	row := r.Conn.QueryRow(ctx, insertQuery,
+		record.PullRequestID,
+		string(record.Cause),
+		record.ReplacedUserID,
+		string(record.Pool),
+		record.Team,
+		string(record.Strategy),
+		candidates,
+		excluded,
+		chosen,
+		record.DecidedAt,
+	)
+
+	added, err := scanAssignmentRecord(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, fmt.Errorf("pull request not found: %s", record.PullRequestID)
+			}
+		}
+		return nil, fmt.Errorf("error adding assignment record: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *PostgresAssignmentHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = $1 ORDER BY id",
+		assignmentRecordColumns, r.HistoryTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	records := []domain.AssignmentRecord{}
+	for rows.Next() {
+		record, err := scanAssignmentRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning assignment record: %w", err)
+		}
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment history: %w", err)
+	}
+
+	return records, nil
+}
+
+func scanAssignmentRecord(row pgx.Row) (*domain.AssignmentRecord, error) {
+	var record domain.AssignmentRecord
+	var cause, pool, strategy string
+	var excluded []excludedCandidateRow
+	err := row.Scan(
+		&record.PullRequestID,
+		&cause,
+		&record.ReplacedUserID,
+		&pool,
+		&record.Team,
+		&strategy,
+		&record.Candidates,
+		&excluded,
+		&record.Chosen,
+		&record.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Cause = domain.AssignmentCause(cause)
+	record.Pool = domain.ReviewerReason(pool)
+	record.Strategy = domain.ReviewerStrategy(strategy)
+	record.Excluded = make([]domain.ExcludedCandidate, 0, len(excluded))
+	for _, candidate := range excluded {
+		record.Excluded = append(record.Excluded, domain.ExcludedCandidate{
+			UserID: candidate.UserID,
+			Reason: domain.ExclusionReason(candidate.Reason),
+		})
+	}
+	return &record, nil
+}
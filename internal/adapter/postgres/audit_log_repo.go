@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const auditEntryColumns = "id, actor, action, target_type, target_id, before, after, request_id, created_at"
+
+type PostgresAuditLogTable struct {
+	Conn     DBTX
+	LogTable string
+}
+
+func NewAuditLogRepo(
+	conn DBTX,
+	logTable string,
+) service.AuditLogRepository {
+	return &PostgresAuditLogTable{Conn: conn, LogTable: logTable}
+}
+
+func (r *PostgresAuditLogTable) Add(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (actor, action, target_type, target_id, before, after, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING %s`,
+		r.LogTable, auditEntryColumns,
+	)
+
+	row := r.Conn.QueryRow(ctx, insertQuery,
+		entry.Actor,
+		string(entry.Action),
+		string(entry.TargetType),
+		entry.TargetID,
+		encodeSnapshot(entry.Before),
+		encodeSnapshot(entry.After),
+		entry.RequestID,
+		entry.CreatedAt,
+	)
+
+	added, err := scanAuditEntry(row)
+	if err != nil {
+		return nil, fmt.Errorf("error adding audit entry: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *PostgresAuditLogTable) Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	conditions := []string{"id > $1"}
+	args := []any{filter.AfterID}
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = $%d", string(filter.Action))
+	}
+	if filter.TargetType != "" {
+		where("target_type = $%d", string(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		where("target_id = $%d", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		where("request_id = $%d", filter.RequestID)
+	}
+	if filter.From != nil {
+		where("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where("created_at < $%d", *filter.To)
+	}
+
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY id",
+		auditEntryColumns, r.LogTable, strings.Join(conditions, " AND "),
+	)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		selectQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.Conn.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// encodeSnapshot sends a missing snapshot as NULL and an existing one as
+// JSON text, so it is not encoded again as a JSON string.
+func encodeSnapshot(snapshot json.RawMessage) any {
+	if snapshot == nil {
+		return nil
+	}
+	return string(snapshot)
+}
+
+func scanAuditEntry(row pgx.Row) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var action, targetType string
+	var before, after []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&action,
+		&targetType,
+		&entry.TargetID,
+		&before,
+		&after,
+		&entry.RequestID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Action = domain.AuditAction(action)
+	entry.TargetType = domain.AuditTargetType(targetType)
+	if before != nil {
+		entry.Before = json.RawMessage(before)
+	}
+	if after != nil {
+		entry.After = json.RawMessage(after)
+	}
+	return &entry, nil
+}
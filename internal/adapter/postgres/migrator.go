@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultMigrationsTable is the table golang-migrate keeps the schema version in,
+// databases migrated by the migrate container are picked up as they are.
+const DefaultMigrationsTable = "schema_migrations"
+
+// migrationLockID is the advisory lock held while a migration runs,
+// so replicas starting at the same time do not migrate twice
+const migrationLockID = 7294071032
+
+var migrationFileRe = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+
+var ErrNoMigrationApplied = errors.New("no migration applied")
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// DirtyMigrationError means a migration failed halfway outside of this service,
+// the schema has to be fixed by hand before migrating further.
+type DirtyMigrationError struct {
+	Version uint64
+}
+
+func (e *DirtyMigrationError) Error() string {
+	return fmt.Sprintf("schema version %d is dirty, fix the schema and the %s table by hand", e.Version, DefaultMigrationsTable)
+}
+
+// LoadMigrations reads migrations from fsys, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error listing migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing version of migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, match[2], version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type PostgresMigrator struct {
+	Pool       *pgxpool.Pool
+	Migrations []Migration
+	Table      string
+}
+
+func NewMigrator(pool *pgxpool.Pool, migrations []Migration, table string) *PostgresMigrator {
+	return &PostgresMigrator{Pool: pool, Migrations: migrations, Table: table}
+}
+
+// Up applies at most steps pending migrations, all of them when steps is not positive.
+// It returns the migrations that were applied.
+func (m *PostgresMigrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	for steps <= 0 || len(applied) < steps {
+		migration, err := m.step(ctx, true)
+		if err != nil {
+			return applied, err
+		}
+		if migration == nil {
+			break
+		}
+		applied = append(applied, *migration)
+	}
+	return applied, nil
+}
+
+// Down reverts at most steps applied migrations, all of them when steps is not positive.
+// It returns the migrations that were reverted.
+func (m *PostgresMigrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	for steps <= 0 || len(reverted) < steps {
+		migration, err := m.step(ctx, false)
+		if err != nil {
+			return reverted, err
+		}
+		if migration == nil {
+			break
+		}
+		reverted = append(reverted, *migration)
+	}
+	return reverted, nil
+}
+
+// Version returns the version of the last applied migration and whether it is dirty,
+// ErrNoMigrationApplied is returned for an empty schema.
+func (m *PostgresMigrator) Version(ctx context.Context) (uint64, bool, error) {
+	if err := m.createTable(ctx, m.Pool); err != nil {
+		return 0, false, err
+	}
+	return m.readVersion(ctx, m.Pool)
+}
+
+func (m *PostgresMigrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	version, _, err := m.Version(ctx)
+	if err != nil && !errors.Is(err, ErrNoMigrationApplied) {
+		return nil, err
+	}
+	applied := err == nil
+
+	statuses := make([]MigrationStatus, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   applied && migration.Version <= version,
+		})
+	}
+	return statuses, nil
+}
+
+// step applies the next migration or reverts the current one in a transaction,
+// returning nil when there is nothing left to do.
+func (m *PostgresMigrator) step(ctx context.Context, up bool) (*Migration, error) {
+	tx, err := m.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting migration transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("error acquiring migration lock: %w", err)
+	}
+	if err := m.createTable(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := m.readVersion(ctx, tx)
+	hasVersion := err == nil
+	if err != nil && !errors.Is(err, ErrNoMigrationApplied) {
+		return nil, err
+	}
+	if dirty {
+		return nil, &DirtyMigrationError{Version: version}
+	}
+
+	current := -1
+	if hasVersion {
+		current = m.index(version)
+		if current < 0 {
+			return nil, fmt.Errorf("applied schema version %d has no migration file", version)
+		}
+	}
+
+	var migration Migration
+	var query string
+	var newVersion *uint64
+	if up {
+		if current+1 >= len(m.Migrations) {
+			return nil, nil
+		}
+		migration = m.Migrations[current+1]
+		query = migration.Up
+		newVersion = &migration.Version
+	} else {
+		if current < 0 {
+			return nil, nil
+		}
+		migration = m.Migrations[current]
+		query = migration.Down
+		if current > 0 {
+			newVersion = &m.Migrations[current-1].Version
+		}
+	}
+
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return nil, fmt.Errorf("error running migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if err := m.writeVersion(ctx, tx, newVersion); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return &migration, nil
+}
+
+func (m *PostgresMigrator) index(version uint64) int {
+	for i, migration := range m.Migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// createTable uses the same layout as golang-migrate:
+// at most one row with the current version, no rows for an empty schema.
+func (m *PostgresMigrator) createTable(ctx context.Context, conn DBTX) error {
+	createQuery := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)",
+		m.Table,
+	)
+	if _, err := conn.Exec(ctx, createQuery); err != nil {
+		return fmt.Errorf("error creating %s table: %w", m.Table, err)
+	}
+	return nil
+}
+
+func (m *PostgresMigrator) readVersion(ctx context.Context, conn DBTX) (uint64, bool, error) {
+	selectQuery := fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", m.Table)
+
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, selectQuery).Scan(&version, &dirty)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, false, ErrNoMigrationApplied
+		}
+		return 0, false, fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	return uint64(version), dirty, nil
+}
+
+func (m *PostgresMigrator) writeVersion(ctx context.Context, conn DBTX, version *uint64) error {
+	if _, err := conn.Exec(ctx, fmt.Sprintf("TRUNCATE %s", m.Table)); err != nil {
+		return fmt.Errorf("error clearing schema version: %w", err)
+	}
+	if version == nil {
+		return nil
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES ($1, false)", m.Table)
+	if _, err := conn.Exec(ctx, insertQuery, int64(*version)); err != nil {
+		return fmt.Errorf("error writing schema version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package postgres_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/postgres"
+	"github.com/raccoon00/avito-pr/migrations"
+)
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	loaded, err := postgres.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Logf("Could not load migrations: %v", err)
+		t.FailNow()
+	}
+	if len(loaded) == 0 {
+		t.Logf("No migrations are embedded")
+		t.FailNow()
+	}
+
+	for i, migration := range loaded {
+		// golang-migrate reads 01_create_teams_table as version 1
+		if migration.Version != uint64(i+1) {
+			t.Logf("Expected migration %d to have version %d, got %d", i, i+1, migration.Version)
+			t.FailNow()
+		}
+		if migration.Up == "" || migration.Down == "" {
+			t.Logf("Migration %d_%s should have both up and down files", migration.Version, migration.Name)
+			t.FailNow()
+		}
+	}
+}
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_second.up.sql":  {Data: []byte("SELECT 10")},
+		"2_first.up.sql":    {Data: []byte("SELECT 2")},
+		"2_first.down.sql":  {Data: []byte("SELECT -2")},
+		"migrations.go":     {Data: []byte("package migrations")},
+		"10_second.down.sq": {Data: []byte("not a migration")},
+	}
+
+	loaded, err := postgres.LoadMigrations(fsys)
+	if err != nil {
+		t.Logf("Could not load migrations: %v", err)
+		t.FailNow()
+	}
+	if len(loaded) != 2 || loaded[0].Version != 2 || loaded[1].Version != 10 {
+		t.Logf("Expected versions 2 and 10, got %+v", loaded)
+		t.FailNow()
+	}
+	if loaded[0].Name != "first" || loaded[0].Down != "SELECT -2" || loaded[1].Down != "" {
+		t.Logf("Unexpected migration contents %+v", loaded)
+		t.FailNow()
+	}
+}
+
+func TestLoadMigrationsRejectsSharedVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_first.up.sql": {Data: []byte("SELECT 1")},
+		"1_other.up.sql": {Data: []byte("SELECT 1")},
+	}
+
+	if _, err := postgres.LoadMigrations(fsys); err == nil {
+		t.Logf("Two migrations with the same version should be rejected")
+		t.FailNow()
+	}
+}
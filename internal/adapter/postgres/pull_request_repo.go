@@ -7,18 +7,17 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/raccoon00/avito-pr/internal/domain"
 	"github.com/raccoon00/avito-pr/internal/service"
 )
 
 type PostgresPullRequestTable struct {
-	Conn    *pgxpool.Pool
+	Conn    DBTX
 	PRTable string
 }
 
 func NewPullRequestRepo(
-	conn *pgxpool.Pool,
+	conn DBTX,
 	prTable string,
 ) service.PullRequestRepository {
 	return &PostgresPullRequestTable{Conn: conn, PRTable: prTable}
@@ -26,14 +25,20 @@ func NewPullRequestRepo(
 
 func (p *PostgresPullRequestTable) Create(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
 	insertQuery := fmt.Sprintf(
-		"INSERT INTO %s (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at",
+		"INSERT INTO %s (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, changed_paths) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version",
 		p.PRTable,
 	)
 
+	// A nil slice would be sent as NULL, the column is NOT NULL DEFAULT '{}'
+	changedPaths := pr.ChangedPaths
+	if changedPaths == nil {
+		changedPaths = []string{}
+	}
+
 	row := p.Conn.QueryRow(
 		ctx,
 		insertQuery,
-		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.AssignedReviewers, pr.CreatedAt, pr.MergedAt,
+		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), pr.AssignedReviewers, pr.CreatedAt, pr.MergedAt, changedPaths,
 	)
 
 	var createdPR domain.PullRequest
@@ -44,8 +49,10 @@ func (p *PostgresPullRequestTable) Create(ctx context.Context, pr *domain.PullRe
 		&createdPR.AuthorID,
 		&status,
 		&createdPR.AssignedReviewers,
+		&createdPR.ChangedPaths,
 		&createdPR.CreatedAt,
 		&createdPR.MergedAt,
+		&createdPR.Version,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -63,7 +70,7 @@ func (p *PostgresPullRequestTable) Create(ctx context.Context, pr *domain.PullRe
 
 func (p *PostgresPullRequestTable) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
 	selectQuery := fmt.Sprintf(
-		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at FROM %s WHERE pull_request_id = $1",
+		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version FROM %s WHERE pull_request_id = $1",
 		p.PRTable,
 	)
 
@@ -77,8 +84,10 @@ func (p *PostgresPullRequestTable) GetByID(ctx context.Context, prID string) (*d
 		&pr.AuthorID,
 		&status,
 		&pr.AssignedReviewers,
+		&pr.ChangedPaths,
 		&pr.CreatedAt,
 		&pr.MergedAt,
+		&pr.Version,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -108,16 +117,33 @@ func (p *PostgresPullRequestTable) Exists(ctx context.Context, prID string) (boo
 	return exists, nil
 }
 
+func (p *PostgresPullRequestTable) ExistsByAuthor(ctx context.Context, authorID string) (bool, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT EXISTS(SELECT 1 FROM %s WHERE author_id = $1)",
+		p.PRTable,
+	)
+
+	row := p.Conn.QueryRow(ctx, selectQuery, authorID)
+
+	var exists bool
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking if author has PRs: %w", err)
+	}
+
+	return exists, nil
+}
+
 func (p *PostgresPullRequestTable) Update(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
 	updateQuery := fmt.Sprintf(
-		"UPDATE %s SET pull_request_name = $1, author_id = $2, status = $3, assigned_reviewers = $4, created_at = $5, merged_at = $6 WHERE pull_request_id = $7 RETURNING pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at",
+		"UPDATE %s SET pull_request_name = $1, author_id = $2, status = $3, assigned_reviewers = $4, created_at = $5, merged_at = $6, version = version + 1 WHERE pull_request_id = $7 AND version = $8 RETURNING pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version",
 		p.PRTable,
 	)
 
 	row := p.Conn.QueryRow(
 		ctx,
 		updateQuery,
-		pr.Name, pr.AuthorID, string(pr.Status), pr.AssignedReviewers, pr.CreatedAt, pr.MergedAt, pr.ID,
+		pr.Name, pr.AuthorID, string(pr.Status), pr.AssignedReviewers, pr.CreatedAt, pr.MergedAt, pr.ID, pr.Version,
 	)
 
 	var updatedPR domain.PullRequest
@@ -128,10 +154,16 @@ func (p *PostgresPullRequestTable) Update(ctx context.Context, pr *domain.PullRe
 		&updatedPR.AuthorID,
 		&status,
 		&updatedPR.AssignedReviewers,
+		&updatedPR.ChangedPaths,
 		&updatedPR.CreatedAt,
 		&updatedPR.MergedAt,
+		&updatedPR.Version,
 	)
 	if err != nil {
+		// Nothing matched the version we read, someone has updated the PR since
+		if err == pgx.ErrNoRows {
+			return nil, &domain.PullRequestConflictError{PullRequestID: pr.ID, Version: pr.Version}
+		}
 		return nil, fmt.Errorf("error updating pull request: %w", err)
 	}
 
@@ -141,7 +173,7 @@ func (p *PostgresPullRequestTable) Update(ctx context.Context, pr *domain.PullRe
 
 func (p *PostgresPullRequestTable) GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
 	selectQuery := fmt.Sprintf(
-		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at FROM %s WHERE $1 = ANY(assigned_reviewers) ORDER BY created_at DESC",
+		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version FROM %s WHERE $1 = ANY(assigned_reviewers) ORDER BY created_at DESC",
 		p.PRTable,
 	)
 
@@ -161,8 +193,122 @@ func (p *PostgresPullRequestTable) GetByReviewer(ctx context.Context, userID str
 			&pr.AuthorID,
 			&status,
 			&pr.AssignedReviewers,
+			&pr.ChangedPaths,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+			&pr.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pull request: %w", err)
+		}
+		pr.Status = domain.PullRequestStatus(status)
+		prs = append(prs, pr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (p *PostgresPullRequestTable) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	// assigned_reviewers && $1 lets Postgres use the GIN index on the array
+	countQuery := fmt.Sprintf(
+		"SELECT reviewer, COUNT(*) FROM %s, unnest(assigned_reviewers) AS reviewer WHERE status = 'OPEN' AND assigned_reviewers && $1 AND reviewer = ANY($1) GROUP BY reviewer",
+		p.PRTable,
+	)
+
+	rows, err := p.Conn.Query(ctx, countQuery, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error counting open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var reviewer string
+		var count int
+		if err := rows.Scan(&reviewer, &count); err != nil {
+			return nil, fmt.Errorf("error scanning open review count: %w", err)
+		}
+		counts[reviewer] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open review counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (p *PostgresPullRequestTable) GetOpenByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version FROM %s WHERE status = 'OPEN' AND assigned_reviewers && $1 ORDER BY created_at",
+		p.PRTable,
+	)
+
+	rows, err := p.Conn.Query(ctx, selectQuery, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open PRs by reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.PullRequest
+	for rows.Next() {
+		var pr domain.PullRequest
+		var status string
+		err := rows.Scan(
+			&pr.ID,
+			&pr.Name,
+			&pr.AuthorID,
+			&status,
+			&pr.AssignedReviewers,
+			&pr.ChangedPaths,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+			&pr.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pull request: %w", err)
+		}
+		pr.Status = domain.PullRequestStatus(status)
+		prs = append(prs, pr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (p *PostgresPullRequestTable) GetOpenByAuthors(ctx context.Context, authorIDs []string) ([]domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version FROM %s WHERE status = 'OPEN' AND author_id = ANY($1) ORDER BY created_at",
+		p.PRTable,
+	)
+
+	rows, err := p.Conn.Query(ctx, selectQuery, authorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open PRs by authors: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.PullRequest
+	for rows.Next() {
+		var pr domain.PullRequest
+		var status string
+		err := rows.Scan(
+			&pr.ID,
+			&pr.Name,
+			&pr.AuthorID,
+			&status,
+			&pr.AssignedReviewers,
+			&pr.ChangedPaths,
 			&pr.CreatedAt,
 			&pr.MergedAt,
+			&pr.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning pull request: %w", err)
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const reviewColumns = "pull_request_id, reviewer_id, verdict, created_at, updated_at"
+
+type PostgresReviewTable struct {
+	Conn        DBTX
+	ReviewTable string
+}
+
+func NewReviewRepo(
+	conn DBTX,
+	reviewTable string,
+) service.ReviewRepository {
+	return &PostgresReviewTable{Conn: conn, ReviewTable: reviewTable}
+}
+
+func (r *PostgresReviewTable) Upsert(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	upsertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (pull_request_id, reviewer_id) DO UPDATE SET verdict = EXCLUDED.verdict, updated_at = EXCLUDED.updated_at RETURNING %s",
+		r.ReviewTable, reviewColumns, reviewColumns,
+	)
+
+	row := r.Conn.QueryRow(
+		ctx,
+		upsertQuery,
+		review.PullRequestID, review.ReviewerID, string(review.Verdict), review.CreatedAt, review.UpdatedAt,
+	)
+
+	upserted, err := scanReview(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, fmt.Errorf("pull request not found: %s", review.PullRequestID)
+			}
+		}
+		return nil, fmt.Errorf("error upserting review: %w", err)
+	}
+
+	return upserted, nil
+}
+
+func (r *PostgresReviewTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.Review, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = $1 ORDER BY created_at, reviewer_id",
+		reviewColumns, r.ReviewTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews by PR: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *PostgresReviewTable) GetByReviewer(ctx context.Context, userID string) ([]domain.Review, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE reviewer_id = $1 ORDER BY created_at, pull_request_id",
+		reviewColumns, r.ReviewTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews by reviewer: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *PostgresReviewTable) DeleteByReviewers(ctx context.Context, prID string, reviewerIDs []string) error {
+	deleteQuery := fmt.Sprintf(
+		"DELETE FROM %s WHERE pull_request_id = $1 AND reviewer_id = ANY($2)",
+		r.ReviewTable,
+	)
+
+	_, err := r.Conn.Exec(ctx, deleteQuery, prID, reviewerIDs)
+	if err != nil {
+		return fmt.Errorf("error deleting reviews: %w", err)
+	}
+
+	return nil
+}
+
+func scanReview(row pgx.Row) (*domain.Review, error) {
+	var review domain.Review
+	var verdict string
+	err := row.Scan(
+		&review.PullRequestID,
+		&review.ReviewerID,
+		&verdict,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Verdict = domain.ReviewVerdict(verdict)
+	return &review, nil
+}
+
+func scanReviews(rows pgx.Rows) ([]domain.Review, error) {
+	reviews := []domain.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, nil
+}
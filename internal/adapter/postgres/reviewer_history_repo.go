@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const reviewerChangeColumns = "pull_request_id, user_id, action, changed_at"
+
+type PostgresReviewerHistoryTable struct {
+	Conn         DBTX
+	HistoryTable string
+}
+
+func NewReviewerHistoryRepo(
+	conn DBTX,
+	historyTable string,
+) service.ReviewerHistoryRepository {
+	return &PostgresReviewerHistoryTable{Conn: conn, HistoryTable: historyTable}
+}
+
+func (r *PostgresReviewerHistoryTable) Add(ctx context.Context, change *domain.ReviewerChange) (*domain.ReviewerChange, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES ($1, $2, $3, $4) RETURNING %s",
+		r.HistoryTable, reviewerChangeColumns, reviewerChangeColumns,
+	)
+
+	row := r.Conn.QueryRow(ctx, insertQuery, change.PullRequestID, change.UserID, string(change.Action), change.ChangedAt)
+
+	added, err := scanReviewerChange(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, fmt.Errorf("pull request not found: %s", change.PullRequestID)
+			}
+		}
+		return nil, fmt.Errorf("error adding reviewer change: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *PostgresReviewerHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.ReviewerChange, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = $1 ORDER BY id",
+		reviewerChangeColumns, r.HistoryTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviewer history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []domain.ReviewerChange{}
+	for rows.Next() {
+		change, err := scanReviewerChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning reviewer change: %w", err)
+		}
+		changes = append(changes, *change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer history: %w", err)
+	}
+
+	return changes, nil
+}
+
+func scanReviewerChange(row pgx.Row) (*domain.ReviewerChange, error) {
+	var change domain.ReviewerChange
+	var action string
+	err := row.Scan(&change.PullRequestID, &change.UserID, &action, &change.ChangedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Action = domain.ReviewerChangeAction(action)
+	return &change, nil
+}
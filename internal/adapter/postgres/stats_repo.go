@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type PostgresStatsTables struct {
+	Conn       DBTX
+	UsersTable string
+	PRTable    string
+}
+
+func NewStatsRepo(
+	conn DBTX,
+	usersTable string,
+	prTable string,
+) service.StatsRepository {
+	return &PostgresStatsTables{Conn: conn, UsersTable: usersTable, PRTable: prTable}
+}
+
+func (s *PostgresStatsTables) GetUserAssignmentStats(ctx context.Context, teamName string) ([]domain.UserAssignmentStats, error) {
+	selectQuery := fmt.Sprintf(
+		`SELECT u.user_id, u.username, u.team_name,
+			COUNT(p.pull_request_id),
+			COUNT(p.pull_request_id) FILTER (WHERE p.status = 'OPEN'),
+			COUNT(p.pull_request_id) FILTER (WHERE p.status = 'MERGED'),
+			COUNT(p.pull_request_id) FILTER (WHERE p.status = 'DRAFT'),
+			COUNT(p.pull_request_id) FILTER (WHERE p.status = 'CLOSED')
+		FROM %s u
+		LEFT JOIN %s p ON p.assigned_reviewers @> ARRAY[u.user_id]
+		WHERE $1 = '' OR u.team_name = $1
+		GROUP BY u.user_id, u.username, u.team_name
+		ORDER BY u.user_id`,
+		s.UsersTable, s.PRTable,
+	)
+
+	rows, err := s.Conn.Query(ctx, selectQuery, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("error querying user assignment stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []domain.UserAssignmentStats{}
+	for rows.Next() {
+		var stat domain.UserAssignmentStats
+		err := rows.Scan(&stat.UserID, &stat.Username, &stat.TeamName, &stat.Assigned, &stat.Open, &stat.Merged, &stat.Draft, &stat.Closed)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user assignment stats: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user assignment stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (s *PostgresStatsTables) GetPullRequestAssignmentStats(ctx context.Context, teamName string) ([]domain.PullRequestAssignmentStats, error) {
+	selectQuery := fmt.Sprintf(
+		`SELECT p.pull_request_id, p.pull_request_name, p.author_id, p.status, cardinality(p.assigned_reviewers)
+		FROM %s p
+		JOIN %s a ON a.user_id = p.author_id
+		WHERE $1 = '' OR a.team_name = $1
+		ORDER BY p.created_at DESC`,
+		s.PRTable, s.UsersTable,
+	)
+
+	rows, err := s.Conn.Query(ctx, selectQuery, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pull request assignment stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []domain.PullRequestAssignmentStats{}
+	for rows.Next() {
+		var stat domain.PullRequestAssignmentStats
+		var status string
+		err := rows.Scan(&stat.PullRequestID, &stat.PullRequestName, &stat.AuthorID, &status, &stat.ReviewersCount)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pull request assignment stats: %w", err)
+		}
+		stat.Status = domain.PullRequestStatus(status)
+		stats = append(stats, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull request assignment stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -5,20 +5,22 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/raccoon00/avito-pr/internal/domain"
 	"github.com/raccoon00/avito-pr/internal/service"
 )
 
+const teamSettingsColumns = "reviewer_strategy, reviewer_count, min_approvals, block_on_changes_requested, merge_lead_ids, fallback_teams, ownership_rules"
+
 type PostgresTeamTable struct {
-	Conn       *pgxpool.Pool
+	Conn       DBTX
 	TeamTable  string
 	UsersTable string
 }
 
 func NewTeamRepo(
-	conn *pgxpool.Pool,
+	conn DBTX,
 	teamTable string,
 	usersTable string,
 ) service.TeamRepository {
@@ -26,29 +28,37 @@ func NewTeamRepo(
 }
 
 func (t *PostgresTeamTable) Create(ctx context.Context, team *domain.Team) (*domain.Team, error) {
-	insertTeamQuery := fmt.Sprintf("INSERT INTO %s (team_name) VALUES ($1) RETURNING team_name", t.TeamTable)
+	insertTeamQuery := fmt.Sprintf(
+		"INSERT INTO %s (team_name, %s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING team_name, %s",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns,
+	)
 	row := t.Conn.QueryRow(
 		ctx,
 		insertTeamQuery,
-		team.Name,
+		append([]any{team.Name}, teamSettingsArgs(&team.Settings)...)...,
 	)
 
 	var team_name string
-	err := row.Scan(&team_name)
+	var settings teamSettingsRow
+	err := row.Scan(append([]any{&team_name}, settings.dest()...)...)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
-			if pgErr.Code == "42710" { // duplicate_object
+			if pgErr.Code == "23505" { // unique_violation
 				return nil, &domain.TeamExistsError{TeamName: team.Name}
 			}
 		}
 		return nil, fmt.Errorf("Unhandled error inserting team into Postgres Team table: %w", err)
 	}
-	newTeam := domain.Team{Name: team_name, Members: make([]domain.User, 0, len(team.Members))}
+	newTeam := domain.Team{
+		Name:     team_name,
+		Members:  make([]domain.User, 0, len(team.Members)),
+		Settings: settings.settings(),
+	}
 
 	insertUser := fmt.Sprintf(
-		"INSERT INTO %v (user_id, username, is_active, team_name) VALUES ($1, $2, $3, $4) RETURNING user_id, username, is_active, team_name",
+		"INSERT INTO %v (user_id, username, is_active, team_name) VALUES ($1, $2, $3, $4) RETURNING user_id, username, is_active, team_name, max_open_reviews",
 		t.UsersTable,
 	)
 	for _, member := range team.Members {
@@ -59,7 +69,7 @@ func (t *PostgresTeamTable) Create(ctx context.Context, team *domain.Team) (*dom
 		)
 
 		var user domain.User
-		err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team)
+		err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
 
 		if err != nil {
 			return &newTeam, fmt.Errorf("Unhandled error inerting user into Postgres Users table: %w", err)
@@ -72,16 +82,20 @@ func (t *PostgresTeamTable) Create(ctx context.Context, team *domain.Team) (*dom
 }
 
 func (t *PostgresTeamTable) Get(ctx context.Context, teamName string) (*domain.Team, error) {
-	checkTeamQuery := fmt.Sprintf("SELECT team_name FROM %s WHERE team_name = $1", t.TeamTable)
+	checkTeamQuery := fmt.Sprintf("SELECT team_name, %s FROM %s WHERE team_name = $1", teamSettingsColumns, t.TeamTable)
 	row := t.Conn.QueryRow(ctx, checkTeamQuery, teamName)
 
 	var teamNameFromDB string
-	err := row.Scan(&teamNameFromDB)
+	var settings teamSettingsRow
+	err := row.Scan(append([]any{&teamNameFromDB}, settings.dest()...)...)
 	if err != nil {
-		return nil, fmt.Errorf("team not found: %w", err)
+		if err == pgx.ErrNoRows {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error getting team: %w", err)
 	}
 
-	getUsersQuery := fmt.Sprintf("SELECT user_id, username, is_active, team_name FROM %s WHERE team_name = $1", t.UsersTable)
+	getUsersQuery := fmt.Sprintf("SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s WHERE team_name = $1", t.UsersTable)
 	rows, err := t.Conn.Query(ctx, getUsersQuery, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("error querying users for team: %w", err)
@@ -89,13 +103,14 @@ func (t *PostgresTeamTable) Get(ctx context.Context, teamName string) (*domain.T
 	defer rows.Close()
 
 	team := &domain.Team{
-		Name:    teamName,
-		Members: []domain.User{},
+		Name:     teamName,
+		Members:  []domain.User{},
+		Settings: settings.settings(),
 	}
 
 	for rows.Next() {
 		var user domain.User
-		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team)
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning user: %w", err)
 		}
@@ -108,3 +123,223 @@ func (t *PostgresTeamTable) Get(ctx context.Context, teamName string) (*domain.T
 
 	return team, nil
 }
+
+func (t *PostgresTeamTable) List(ctx context.Context) ([]domain.Team, error) {
+	teamsQuery := fmt.Sprintf("SELECT team_name, %s FROM %s ORDER BY team_name", teamSettingsColumns, t.TeamTable)
+	rows, err := t.Conn.Query(ctx, teamsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying teams: %w", err)
+	}
+	defer rows.Close()
+
+	teamIdx := make(map[string]int)
+	teams := []domain.Team{}
+	for rows.Next() {
+		var teamName string
+		var settings teamSettingsRow
+		if err := rows.Scan(append([]any{&teamName}, settings.dest()...)...); err != nil {
+			return nil, fmt.Errorf("error scanning team: %w", err)
+		}
+		teamIdx[teamName] = len(teams)
+		teams = append(teams, domain.Team{
+			Name:     teamName,
+			Members:  []domain.User{},
+			Settings: settings.settings(),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating teams: %w", err)
+	}
+
+	usersQuery := fmt.Sprintf("SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s ORDER BY user_id", t.UsersTable)
+	rows, err = t.Conn.Query(ctx, usersQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		i := teamIdx[user.Team]
+		teams[i].Members = append(teams[i].Members, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return teams, nil
+}
+
+func (t *PostgresTeamTable) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	return t.getSettings(ctx, teamName, "")
+}
+
+func (t *PostgresTeamTable) GetSettingsForUpdate(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	return t.getSettings(ctx, teamName, "FOR UPDATE")
+}
+
+// getSettings reads the settings row, lock is an optional locking clause.
+func (t *PostgresTeamTable) getSettings(ctx context.Context, teamName string, lock string) (*domain.TeamSettings, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE team_name = $1 %s", teamSettingsColumns, t.TeamTable, lock)
+	row := t.Conn.QueryRow(ctx, selectQuery, teamName)
+
+	var settings teamSettingsRow
+	err := row.Scan(settings.dest()...)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error getting team settings: %w", err)
+	}
+
+	teamSettings := settings.settings()
+	return &teamSettings, nil
+}
+
+func (t *PostgresTeamTable) UpdateSettings(ctx context.Context, teamName string, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET (%s) = ($2, $3, $4, $5, $6, $7, $8) WHERE team_name = $1 RETURNING %s",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns,
+	)
+	row := t.Conn.QueryRow(ctx, updateQuery, append([]any{teamName}, teamSettingsArgs(settings)...)...)
+
+	var updated teamSettingsRow
+	err := row.Scan(updated.dest()...)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error updating team settings: %w", err)
+	}
+
+	updatedSettings := updated.settings()
+	return &updatedSettings, nil
+}
+
+func (t *PostgresTeamTable) Rename(ctx context.Context, teamName string, newName string) (*domain.Team, error) {
+	// users.team_name references the old row, so the team is copied under
+	// the new name, members are moved over and only then the old row is dropped
+	copyTeamQuery := fmt.Sprintf(
+		"INSERT INTO %s (team_name, %s) SELECT $2, %s FROM %s WHERE team_name = $1",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns, t.TeamTable,
+	)
+	tag, err := t.Conn.Exec(ctx, copyTeamQuery, teamName, newName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" { // unique_violation
+				return nil, &domain.TeamExistsError{TeamName: newName}
+			}
+		}
+		return nil, fmt.Errorf("error copying team: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	moveUsersQuery := fmt.Sprintf("UPDATE %s SET team_name = $1 WHERE team_name = $2", t.UsersTable)
+	if _, err := t.Conn.Exec(ctx, moveUsersQuery, newName, teamName); err != nil {
+		return nil, fmt.Errorf("error moving users to renamed team: %w", err)
+	}
+
+	deleteTeamQuery := fmt.Sprintf("DELETE FROM %s WHERE team_name = $1", t.TeamTable)
+	if _, err := t.Conn.Exec(ctx, deleteTeamQuery, teamName); err != nil {
+		return nil, fmt.Errorf("error deleting renamed team: %w", err)
+	}
+
+	return t.Get(ctx, newName)
+}
+
+func (t *PostgresTeamTable) Delete(ctx context.Context, teamName string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE team_name = $1", t.TeamTable)
+
+	tag, err := t.Conn.Exec(ctx, deleteQuery, teamName)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return &domain.TeamNotEmptyError{TeamName: teamName}
+			}
+		}
+		return fmt.Errorf("error deleting team: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	return nil
+}
+
+// teamSettingsRow receives the teamSettingsColumns of a scanned row.
+type teamSettingsRow struct {
+	strategy                string
+	reviewerCount           int
+	minApprovals            int
+	blockOnChangesRequested bool
+	mergeLeadIDs            []string
+	fallbackTeams           []string
+	ownershipRules          []ownershipRuleRow
+}
+
+// ownershipRuleRow is an element of the ownership_rules JSON array.
+type ownershipRuleRow struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+func (r *teamSettingsRow) dest() []any {
+	return []any{&r.strategy, &r.reviewerCount, &r.minApprovals, &r.blockOnChangesRequested, &r.mergeLeadIDs, &r.fallbackTeams, &r.ownershipRules}
+}
+
+func (r *teamSettingsRow) settings() domain.TeamSettings {
+	settings := domain.TeamSettings{
+		ReviewerStrategy: domain.ReviewerStrategy(r.strategy),
+		ReviewerCount:    r.reviewerCount,
+		MergePolicy: domain.MergePolicy{
+			MinApprovals:            r.minApprovals,
+			BlockOnChangesRequested: r.blockOnChangesRequested,
+			LeadIDs:                 r.mergeLeadIDs,
+		},
+		FallbackTeams:  r.fallbackTeams,
+		OwnershipRules: make([]domain.OwnershipRule, 0, len(r.ownershipRules)),
+	}
+	for _, rule := range r.ownershipRules {
+		owners := rule.Owners
+		if owners == nil {
+			owners = []string{}
+		}
+		settings.OwnershipRules = append(settings.OwnershipRules, domain.OwnershipRule{Pattern: rule.Pattern, Owners: owners})
+	}
+	return settings
+}
+
+// teamSettingsArgs returns the values of teamSettingsColumns in their order.
+func teamSettingsArgs(settings *domain.TeamSettings) []any {
+	// A nil slice would be sent as NULL, the columns are NOT NULL DEFAULT '{}'
+	leadIDs := settings.MergePolicy.LeadIDs
+	if leadIDs == nil {
+		leadIDs = []string{}
+	}
+	fallbackTeams := settings.FallbackTeams
+	if fallbackTeams == nil {
+		fallbackTeams = []string{}
+	}
+	// The JSONB column is sent through json.Marshal
+	ownershipRules := make([]ownershipRuleRow, 0, len(settings.OwnershipRules))
+	for _, rule := range settings.OwnershipRules {
+		ownershipRules = append(ownershipRules, ownershipRuleRow{Pattern: rule.Pattern, Owners: rule.Owners})
+	}
+	return []any{
+		string(settings.ReviewerStrategy),
+		settings.ReviewerCount,
+		settings.MergePolicy.MinApprovals,
+		settings.MergePolicy.BlockOnChangesRequested,
+		leadIDs,
+		fallbackTeams,
+		ownershipRules,
+	}
+}
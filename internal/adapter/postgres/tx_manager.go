@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// DBTX is implemented by both *pgxpool.Pool and pgx.Tx,
+// so the same repository code works inside and outside a transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type Tables struct {
+	Teams           string
+	Users           string
+	PullRequests    string
+	Reviews         string
+	ReviewerHistory string
+	Unavailability  string
+	Assignments     string
+	Audit           string
+	Webhooks        string
+	Deliveries      string
+	// DeliveryAttempts is the log of delivery attempts
+	DeliveryAttempts string
+}
+
+func DefaultTables() Tables {
+	return Tables{
+		Teams:           "teams",
+		Users:           "users",
+		PullRequests:    "pr_requests",
+		Reviews:         "pr_reviews",
+		ReviewerHistory: "pr_reviewer_history",
+		Unavailability:  "user_unavailability",
+		Assignments:     "pr_assignment_history",
+		Audit:           "audit_log",
+		Webhooks:        "webhook_subscriptions",
+		Deliveries:      "webhook_deliveries",
+
+		DeliveryAttempts: "webhook_delivery_attempts",
+	}
+}
+
+// NewRepositories binds all repositories to conn, which is either the pool or a transaction.
+func NewRepositories(conn DBTX, tables Tables) service.Repositories {
+	return service.Repositories{
+		Teams:           NewTeamRepo(conn, tables.Teams, tables.Users),
+		Users:           NewUserRepo(conn, tables.Users, tables.Unavailability),
+		PullRequests:    NewPullRequestRepo(conn, tables.PullRequests),
+		Reviews:         NewReviewRepo(conn, tables.Reviews),
+		ReviewerHistory: NewReviewerHistoryRepo(conn, tables.ReviewerHistory),
+		Unavailability:  NewUnavailabilityRepo(conn, tables.Unavailability),
+		Assignments:     NewAssignmentHistoryRepo(conn, tables.Assignments),
+		Audit:           NewAuditLogRepo(conn, tables.Audit),
+		Webhooks:        NewWebhookRepo(conn, tables.Webhooks),
+		Deliveries:      NewWebhookDeliveryRepo(conn, tables.Deliveries, tables.DeliveryAttempts, tables.Webhooks),
+	}
+}
+
+type PostgresTxManager struct {
+	Pool   *pgxpool.Pool
+	Tables Tables
+}
+
+func NewTxManager(pool *pgxpool.Pool, tables Tables) service.TxManager {
+	return &PostgresTxManager{Pool: pool, Tables: tables}
+}
+
+func (m *PostgresTxManager) WithinTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	tx, err := m.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(NewRepositories(tx, m.Tables)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
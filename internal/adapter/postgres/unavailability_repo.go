@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const unavailabilityColumns = "id, user_id, starts_at, ends_at, reason, handed_over"
+
+type PostgresUnavailabilityTable struct {
+	Conn                DBTX
+	UnavailabilityTable string
+}
+
+func NewUnavailabilityRepo(
+	conn DBTX,
+	unavailabilityTable string,
+) service.UnavailabilityRepository {
+	return &PostgresUnavailabilityTable{Conn: conn, UnavailabilityTable: unavailabilityTable}
+}
+
+func (r *PostgresUnavailabilityTable) Create(ctx context.Context, unavailability *domain.Unavailability) (*domain.Unavailability, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, starts_at, ends_at, reason, handed_over) VALUES ($1, $2, $3, $4, $5) RETURNING %s",
+		r.UnavailabilityTable, unavailabilityColumns,
+	)
+
+	row := r.Conn.QueryRow(
+		ctx,
+		insertQuery,
+		unavailability.UserID,
+		unavailability.StartsAt,
+		unavailability.EndsAt,
+		unavailability.Reason,
+		unavailability.HandedOver,
+	)
+
+	created, err := scanUnavailability(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, &domain.UserNotFoundError{UserID: unavailability.UserID}
+			}
+		}
+		return nil, fmt.Errorf("error creating unavailability: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *PostgresUnavailabilityTable) GetByID(ctx context.Context, id int64) (*domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", unavailabilityColumns, r.UnavailabilityTable)
+
+	unavailability, err := scanUnavailability(r.Conn.QueryRow(ctx, selectQuery, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.UnavailabilityNotFoundError{ID: id}
+		}
+		return nil, fmt.Errorf("error querying unavailability: %w", err)
+	}
+
+	return unavailability, nil
+}
+
+func (r *PostgresUnavailabilityTable) GetByUser(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE user_id = $1 ORDER BY starts_at, id",
+		unavailabilityColumns, r.UnavailabilityTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unavailability: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUnavailabilities(rows)
+}
+
+func (r *PostgresUnavailabilityTable) Delete(ctx context.Context, id int64) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.UnavailabilityTable)
+
+	tag, err := r.Conn.Exec(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting unavailability: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return &domain.UnavailabilityHandedOverError{ID: id}
+	}
+
+	return nil
+}
+
+func (r *PostgresUnavailabilityTable) GetPendingHandOvers(ctx context.Context, at time.Time) ([]domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE handed_over = false AND starts_at <= $1 AND ends_at > $1 ORDER BY starts_at, id",
+		unavailabilityColumns, r.UnavailabilityTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, at)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending hand-overs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUnavailabilities(rows)
+}
+
+func (r *PostgresUnavailabilityTable) MarkHandedOver(ctx context.Context, id int64) error {
+	updateQuery := fmt.Sprintf("UPDATE %s SET handed_over = true WHERE id = $1 AND NOT handed_over", r.UnavailabilityTable)
+
+	tag, err := r.Conn.Exec(ctx, updateQuery, id)
+	if err != nil {
+		return fmt.Errorf("error marking unavailability as handed over: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return &domain.UnavailabilityHandedOverError{ID: id}
+	}
+
+	return nil
+}
+
+func scanUnavailabilities(rows pgx.Rows) ([]domain.Unavailability, error) {
+	unavailabilities := []domain.Unavailability{}
+	for rows.Next() {
+		unavailability, err := scanUnavailability(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning unavailability: %w", err)
+		}
+		unavailabilities = append(unavailabilities, *unavailability)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unavailability: %w", err)
+	}
+
+	return unavailabilities, nil
+}
+
+func scanUnavailability(row pgx.Row) (*domain.Unavailability, error) {
+	var unavailability domain.Unavailability
+	err := row.Scan(
+		&unavailability.ID,
+		&unavailability.UserID,
+		&unavailability.StartsAt,
+		&unavailability.EndsAt,
+		&unavailability.Reason,
+		&unavailability.HandedOver,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unavailability, nil
+}
@@ -2,35 +2,39 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/raccoon00/avito-pr/internal/domain"
 	"github.com/raccoon00/avito-pr/internal/service"
 )
 
 type PostgresUserTable struct {
-	Conn       *pgxpool.Pool
-	UsersTable string
+	Conn                DBTX
+	UsersTable          string
+	UnavailabilityTable string
 }
 
 func NewUserRepo(
-	conn *pgxpool.Pool,
+	conn DBTX,
 	usersTable string,
+	unavailabilityTable string,
 ) service.UserRepository {
-	return &PostgresUserTable{Conn: conn, UsersTable: usersTable}
+	return &PostgresUserTable{Conn: conn, UsersTable: usersTable, UnavailabilityTable: unavailabilityTable}
 }
 
 func (u *PostgresUserTable) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	updateQuery := fmt.Sprintf(
-		"UPDATE %s SET is_active = $1 WHERE user_id = $2 RETURNING user_id, username, is_active, team_name",
+		"UPDATE %s SET is_active = $1 WHERE user_id = $2 RETURNING user_id, username, is_active, team_name, max_open_reviews",
 		u.UsersTable,
 	)
 
 	row := u.Conn.QueryRow(ctx, updateQuery, isActive, userID)
 
 	var user domain.User
-	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team)
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
@@ -40,16 +44,19 @@ func (u *PostgresUserTable) SetIsActive(ctx context.Context, userID string, isAc
 
 func (u *PostgresUserTable) GetByID(ctx context.Context, userID string) (*domain.User, error) {
 	selectQuery := fmt.Sprintf(
-		"SELECT user_id, username, is_active, team_name FROM %s WHERE user_id = $1",
+		"SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s WHERE user_id = $1",
 		u.UsersTable,
 	)
 
 	row := u.Conn.QueryRow(ctx, selectQuery, userID)
 
 	var user domain.User
-	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team)
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		if err == pgx.ErrNoRows {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		return nil, fmt.Errorf("error getting user: %w", err)
 	}
 
 	return &user, nil
@@ -57,8 +64,11 @@ func (u *PostgresUserTable) GetByID(ctx context.Context, userID string) (*domain
 
 func (u *PostgresUserTable) GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserID string) ([]domain.User, error) {
 	selectQuery := fmt.Sprintf(
-		"SELECT user_id, username, is_active, team_name FROM %s WHERE team_name = $1 AND is_active = true AND user_id != $2 ORDER BY user_id",
-		u.UsersTable,
+		`SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s u
+		WHERE team_name = $1 AND is_active = true AND user_id != $2
+			AND NOT EXISTS (SELECT 1 FROM %s a WHERE a.user_id = u.user_id AND a.starts_at <= now() AND a.ends_at > now())
+		ORDER BY user_id`,
+		u.UsersTable, u.UnavailabilityTable,
 	)
 
 	rows, err := u.Conn.Query(ctx, selectQuery, teamName, excludeUserID)
@@ -70,7 +80,56 @@ func (u *PostgresUserTable) GetActiveTeamMembers(ctx context.Context, teamName s
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team)
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (u *PostgresUserTable) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (*domain.User, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET max_open_reviews = $1 WHERE user_id = $2 RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRow(ctx, updateQuery, maxOpenReviews, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		return nil, fmt.Errorf("error updating user review limit: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *PostgresUserTable) DeactivateUsers(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	deactivateQuery := fmt.Sprintf(
+		"UPDATE %s SET is_active = false WHERE user_id = ANY($1) RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	rows, err := u.Conn.Query(ctx, deactivateQuery, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error deactivating users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning user: %w", err)
 		}
@@ -83,3 +142,98 @@ func (u *PostgresUserTable) GetActiveTeamMembers(ctx context.Context, teamName s
 
 	return users, nil
 }
+
+func (u *PostgresUserTable) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, username, is_active, team_name, max_open_reviews) VALUES ($1, $2, $3, $4, $5) RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRow(ctx, insertQuery, user.Id, user.Name, user.IsActive, user.Team, user.MaxOpenReviews)
+
+	var createdUser domain.User
+	err := row.Scan(&createdUser.Id, &createdUser.Name, &createdUser.IsActive, &createdUser.Team, &createdUser.MaxOpenReviews)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation
+				return nil, &domain.UserExistsError{UserID: user.Id}
+			case "23503": // foreign_key_violation
+				return nil, &domain.TeamNotFoundError{TeamName: user.Team}
+			}
+		}
+		return nil, fmt.Errorf("error inserting user: %w", err)
+	}
+
+	return &createdUser, nil
+}
+
+func (u *PostgresUserTable) Upsert(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	// xmax is zero only for a row version written by a plain insert
+	upsertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, username, is_active, team_name) VALUES ($1, $2, $3, $4) ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, is_active = EXCLUDED.is_active, team_name = EXCLUDED.team_name RETURNING user_id, username, is_active, team_name, max_open_reviews, (xmax = 0)",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRow(ctx, upsertQuery, user.Id, user.Name, user.IsActive, user.Team)
+
+	var upsertedUser domain.User
+	var created bool
+	err := row.Scan(&upsertedUser.Id, &upsertedUser.Name, &upsertedUser.IsActive, &upsertedUser.Team, &upsertedUser.MaxOpenReviews, &created)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			return nil, false, &domain.TeamNotFoundError{TeamName: user.Team}
+		}
+		return nil, false, fmt.Errorf("error upserting user: %w", err)
+	}
+
+	return &upsertedUser, created, nil
+}
+
+func (u *PostgresUserTable) SetTeam(ctx context.Context, userID string, teamName string) (*domain.User, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET team_name = $1 WHERE user_id = $2 RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRow(ctx, updateQuery, teamName, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, &domain.TeamNotFoundError{TeamName: teamName}
+			}
+		}
+		return nil, fmt.Errorf("error moving user to team: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *PostgresUserTable) Delete(ctx context.Context, userID string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", u.UsersTable)
+
+	tag, err := u.Conn.Exec(ctx, deleteQuery, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return &domain.UserHasPullRequestsError{UserID: userID}
+			}
+		}
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &domain.UserNotFoundError{UserID: userID}
+	}
+
+	return nil
+}
@@ -0,0 +1,389 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const (
+	webhookSubscriptionColumns = "id, url, secret, events, team_name, active, created_at"
+	webhookDeliveryColumns     = "id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at"
+	webhookAttemptColumns      = "delivery_id, attempt, status_code, error, attempted_at"
+)
+
+type PostgresWebhookTable struct {
+	Conn              DBTX
+	SubscriptionTable string
+}
+
+func NewWebhookRepo(
+	conn DBTX,
+	subscriptionTable string,
+) service.WebhookRepository {
+	return &PostgresWebhookTable{Conn: conn, SubscriptionTable: subscriptionTable}
+}
+
+func (r *PostgresWebhookTable) Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (url, secret, events, team_name, active, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING %s",
+		r.SubscriptionTable, webhookSubscriptionColumns,
+	)
+
+	row := r.Conn.QueryRow(ctx, insertQuery,
+		subscription.URL,
+		subscription.Secret,
+		eventNames(subscription.Events),
+		subscription.TeamName,
+		subscription.Active,
+		subscription.CreatedAt,
+	)
+
+	created, err := scanWebhookSubscription(row)
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook subscription: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *PostgresWebhookTable) Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", webhookSubscriptionColumns, r.SubscriptionTable)
+
+	subscription, err := scanWebhookSubscription(r.Conn.QueryRow(ctx, selectQuery, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.WebhookNotFoundError{ID: id}
+		}
+		return nil, fmt.Errorf("error querying webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *PostgresWebhookTable) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s ORDER BY id", webhookSubscriptionColumns, r.SubscriptionTable)
+
+	rows, err := r.Conn.Query(ctx, selectQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresWebhookTable) Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET url = $1, secret = $2, events = $3, team_name = $4, active = $5 WHERE id = $6 RETURNING %s",
+		r.SubscriptionTable, webhookSubscriptionColumns,
+	)
+
+	row := r.Conn.QueryRow(ctx, updateQuery,
+		subscription.URL,
+		subscription.Secret,
+		eventNames(subscription.Events),
+		subscription.TeamName,
+		subscription.Active,
+		subscription.ID,
+	)
+
+	updated, err := scanWebhookSubscription(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, &domain.WebhookNotFoundError{ID: subscription.ID}
+		}
+		return nil, fmt.Errorf("error updating webhook subscription: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *PostgresWebhookTable) Delete(ctx context.Context, id int64) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.SubscriptionTable)
+
+	tag, err := r.Conn.Exec(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return &domain.WebhookNotFoundError{ID: id}
+	}
+
+	return nil
+}
+
+// eventNames converts events for the TEXT[] column, never returning nil.
+func eventNames(events []domain.EventType) []string {
+	names := make([]string, 0, len(events))
+	for _, eventType := range events {
+		names = append(names, string(eventType))
+	}
+	return names
+}
+
+func scanWebhookSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	var events []string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.Secret,
+		&events,
+		&subscription.TeamName,
+		&subscription.Active,
+		&subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Events = make([]domain.EventType, 0, len(events))
+	for _, eventType := range events {
+		subscription.Events = append(subscription.Events, domain.EventType(eventType))
+	}
+	return &subscription, nil
+}
+
+type PostgresWebhookDeliveryTable struct {
+	Conn              DBTX
+	DeliveryTable     string
+	AttemptTable      string
+	SubscriptionTable string
+}
+
+func NewWebhookDeliveryRepo(
+	conn DBTX,
+	deliveryTable string,
+	attemptTable string,
+	subscriptionTable string,
+) service.WebhookDeliveryRepository {
+	return &PostgresWebhookDeliveryTable{
+		Conn:              conn,
+		DeliveryTable:     deliveryTable,
+		AttemptTable:      attemptTable,
+		SubscriptionTable: subscriptionTable,
+	}
+}
+
+func (r *PostgresWebhookDeliveryTable) Create(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (subscription_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING %s`,
+		r.DeliveryTable, webhookDeliveryColumns,
+	)
+
+	row := r.Conn.QueryRow(ctx, insertQuery,
+		delivery.SubscriptionID,
+		string(delivery.EventType),
+		// Sent as JSON text, so it is not encoded again as a JSON string
+		string(delivery.Payload),
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.CreatedAt,
+		delivery.DeliveredAt,
+	)
+
+	created, err := scanWebhookDelivery(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23503" { // foreign_key_violation
+				return nil, &domain.WebhookNotFoundError{ID: delivery.SubscriptionID}
+			}
+		}
+		return nil, fmt.Errorf("error creating webhook delivery: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *PostgresWebhookDeliveryTable) ClaimDue(ctx context.Context, at, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	// SKIP LOCKED leaves the deliveries another worker is claiming to it
+	selectQuery := fmt.Sprintf(
+		`SELECT %s FROM %s
+		WHERE status = $1 AND next_attempt_at <= $2 AND subscription_id IN (SELECT id FROM %s WHERE active)
+		ORDER BY next_attempt_at, id LIMIT $3
+		FOR UPDATE SKIP LOCKED`,
+		webhookDeliveryColumns, r.DeliveryTable, r.SubscriptionTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, string(domain.WebhookDeliveryPending), at, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	ids := []int64{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		delivery.NextAttemptAt = until
+		deliveries = append(deliveries, *delivery)
+		ids = append(ids, delivery.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	claimQuery := fmt.Sprintf("UPDATE %s SET next_attempt_at = $1 WHERE id = ANY($2)", r.DeliveryTable)
+	if _, err := r.Conn.Exec(ctx, claimQuery, until, ids); err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *PostgresWebhookDeliveryTable) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5 WHERE id = $6",
+		r.DeliveryTable,
+	)
+
+	tag, err := r.Conn.Exec(ctx, updateQuery,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook delivery not found: %d", delivery.ID)
+	}
+
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (delivery_id, attempt, status_code, error, attempted_at) VALUES ($1, $2, $3, $4, $5)",
+		r.AttemptTable,
+	)
+
+	_, err = r.Conn.Exec(ctx, insertQuery,
+		delivery.ID,
+		attempt.Attempt,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookDeliveryTable) GetBySubscription(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDeliveryLog, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE subscription_id = $1 ORDER BY id DESC LIMIT $2",
+		webhookDeliveryColumns, r.DeliveryTable,
+	)
+
+	rows, err := r.Conn.Query(ctx, selectQuery, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []domain.WebhookDeliveryLog{}
+	indexes := map[int64]int{}
+	deliveryIDs := []int64{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		indexes[delivery.ID] = len(logs)
+		deliveryIDs = append(deliveryIDs, delivery.ID)
+		logs = append(logs, domain.WebhookDeliveryLog{Delivery: *delivery, Attempts: []domain.WebhookAttempt{}})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	attemptsQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE delivery_id = ANY($1) ORDER BY delivery_id, attempt",
+		webhookAttemptColumns, r.AttemptTable,
+	)
+
+	attemptRows, err := r.Conn.Query(ctx, attemptsQuery, deliveryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook attempts: %w", err)
+	}
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var attempt domain.WebhookAttempt
+		err := attemptRows.Scan(&attempt.DeliveryID, &attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attempt.AttemptedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook attempt: %w", err)
+		}
+		log := &logs[indexes[attempt.DeliveryID]]
+		log.Attempts = append(log.Attempts, attempt)
+	}
+
+	if err := attemptRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook attempts: %w", err)
+	}
+
+	return logs, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var eventType, status string
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&eventType,
+		&payload,
+		&status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.EventType = domain.EventType(eventType)
+	delivery.Payload = json.RawMessage(payload)
+	delivery.Status = domain.WebhookDeliveryStatus(status)
+	return &delivery, nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const assignmentRecordColumns = "pull_request_id, cause, COALESCE(replaced_user_id, ''), pool, team_name, strategy, candidates, excluded, chosen, decided_at"
+
+type SQLiteAssignmentHistoryTable struct {
+	Conn         DBTX
+	HistoryTable string
+}
+
+func NewAssignmentHistoryRepo(
+	conn DBTX,
+	historyTable string,
+) service.AssignmentHistoryRepository {
+	return &SQLiteAssignmentHistoryTable{Conn: conn, HistoryTable: historyTable}
+}
+
+// excludedCandidateRow is an element of the excluded JSON array.
+type excludedCandidateRow struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+func (r *SQLiteAssignmentHistoryTable) Add(ctx context.Context, record *domain.AssignmentRecord) (*domain.AssignmentRecord, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (pull_request_id, cause, replaced_user_id, pool, team_name, strategy, candidates, excluded, chosen, decided_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?)
+		RETURNING %s`,
+		r.HistoryTable, assignmentRecordColumns,
+	)
+
+	candidates, err := encodeIDs(record.Candidates)
+	if err != nil {
+		return nil, err
+	}
+	chosen, err := encodeIDs(record.Chosen)
+	if err != nil {
+		return nil, err
+	}
+	excludedRows := make([]excludedCandidateRow, 0, len(record.Excluded))
+	for _, candidate := range record.Excluded {
+		excludedRows = append(excludedRows, excludedCandidateRow{UserID: candidate.UserID, Reason: string(candidate.Reason)})
+	}
+	excluded, err := json.Marshal(excludedRows)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding excluded candidates: %w", err)
+	}
+
+	row := r.Conn.QueryRowContext(ctx, insertQuery,
+		record.PullRequestID,
+		string(record.Cause),
+		record.ReplacedUserID,
+		string(record.Pool),
+		record.Team,
+		string(record.Strategy),
+		candidates,
+		string(excluded),
+		chosen,
+		formatTime(&record.DecidedAt),
+	)
+
+	added, err := scanAssignmentRecord(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, fmt.Errorf("pull request not found: %s", record.PullRequestID)
+		}
+		return nil, fmt.Errorf("error adding assignment record: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *SQLiteAssignmentHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = ? ORDER BY id",
+		assignmentRecordColumns, r.HistoryTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	records := []domain.AssignmentRecord{}
+	for rows.Next() {
+		record, err := scanAssignmentRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning assignment record: %w", err)
+		}
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment history: %w", err)
+	}
+
+	return records, nil
+}
+
+func scanAssignmentRecord(row rowScanner) (*domain.AssignmentRecord, error) {
+	var record domain.AssignmentRecord
+	var cause, pool, strategy, candidates, excluded, chosen, decidedAt string
+	err := row.Scan(
+		&record.PullRequestID,
+		&cause,
+		&record.ReplacedUserID,
+		&pool,
+		&record.Team,
+		&strategy,
+		&candidates,
+		&excluded,
+		&chosen,
+		&decidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Cause = domain.AssignmentCause(cause)
+	record.Pool = domain.ReviewerReason(pool)
+	record.Strategy = domain.ReviewerStrategy(strategy)
+	record.Candidates = []string{}
+	if err := json.Unmarshal([]byte(candidates), &record.Candidates); err != nil {
+		return nil, fmt.Errorf("error decoding candidates of assignment on PR %s: %w", record.PullRequestID, err)
+	}
+	record.Chosen = []string{}
+	if err := json.Unmarshal([]byte(chosen), &record.Chosen); err != nil {
+		return nil, fmt.Errorf("error decoding chosen reviewers of assignment on PR %s: %w", record.PullRequestID, err)
+	}
+	var excludedRows []excludedCandidateRow
+	if err := json.Unmarshal([]byte(excluded), &excludedRows); err != nil {
+		return nil, fmt.Errorf("error decoding excluded candidates of assignment on PR %s: %w", record.PullRequestID, err)
+	}
+	record.Excluded = make([]domain.ExcludedCandidate, 0, len(excludedRows))
+	for _, candidate := range excludedRows {
+		record.Excluded = append(record.Excluded, domain.ExcludedCandidate{
+			UserID: candidate.UserID,
+			Reason: domain.ExclusionReason(candidate.Reason),
+		})
+	}
+	if record.DecidedAt, err = time.Parse(time.RFC3339Nano, decidedAt); err != nil {
+		return nil, fmt.Errorf("error decoding time of assignment on PR %s: %w", record.PullRequestID, err)
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const auditEntryColumns = "id, actor, action, target_type, target_id, before, after, request_id, created_at"
+
+type SQLiteAuditLogTable struct {
+	Conn     DBTX
+	LogTable string
+}
+
+func NewAuditLogRepo(
+	conn DBTX,
+	logTable string,
+) service.AuditLogRepository {
+	return &SQLiteAuditLogTable{Conn: conn, LogTable: logTable}
+}
+
+func (r *SQLiteAuditLogTable) Add(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (actor, action, target_type, target_id, before, after, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING %s`,
+		r.LogTable, auditEntryColumns,
+	)
+
+	row := r.Conn.QueryRowContext(ctx, insertQuery,
+		entry.Actor,
+		string(entry.Action),
+		string(entry.TargetType),
+		entry.TargetID,
+		encodeSnapshot(entry.Before),
+		encodeSnapshot(entry.After),
+		entry.RequestID,
+		formatTime(&entry.CreatedAt),
+	)
+
+	added, err := scanAuditEntry(row)
+	if err != nil {
+		return nil, fmt.Errorf("error adding audit entry: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *SQLiteAuditLogTable) Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	conditions := []string{"id > ?"}
+	args := []any{filter.AfterID}
+	where := func(condition string, arg any) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if filter.Actor != "" {
+		where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = ?", string(filter.Action))
+	}
+	if filter.TargetType != "" {
+		where("target_type = ?", string(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		where("created_at >= ?", formatTime(filter.From))
+	}
+	if filter.To != nil {
+		where("created_at < ?", formatTime(filter.To))
+	}
+
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY id",
+		auditEntryColumns, r.LogTable, strings.Join(conditions, " AND "),
+	)
+	if filter.Limit > 0 {
+		selectQuery += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// encodeSnapshot stores a missing snapshot as NULL.
+func encodeSnapshot(snapshot json.RawMessage) any {
+	if snapshot == nil {
+		return nil
+	}
+	return string(snapshot)
+}
+
+func scanAuditEntry(row rowScanner) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var action, targetType, createdAt string
+	var before, after sql.NullString
+	err := row.Scan(
+		&entry.ID,
+		&entry.Actor,
+		&action,
+		&targetType,
+		&entry.TargetID,
+		&before,
+		&after,
+		&entry.RequestID,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Action = domain.AuditAction(action)
+	entry.TargetType = domain.AuditTargetType(targetType)
+	if before.Valid {
+		entry.Before = json.RawMessage(before.String)
+	}
+	if after.Valid {
+		entry.After = json.RawMessage(after.String)
+	}
+	if entry.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("error decoding time of audit entry %d: %w", entry.ID, err)
+	}
+
+	return &entry, nil
+}
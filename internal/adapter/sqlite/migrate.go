@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations/*.up.sql
+var migrationsFS embed.FS
+
+// Migrate applies the embedded migrations that were not applied yet.
+// Applied migrations are recorded in the schema_migrations table,
+// every migration runs in its own transaction.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (name TEXT PRIMARY KEY)")
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %w", err)
+	}
+
+	names, err := fs.Glob(migrationsFS, "migrations/*.up.sql")
+	if err != nil {
+		return fmt.Errorf("error listing migrations: %w", err)
+	}
+	// Names start with a zero padded number, so this is the order they were written in
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := applyMigration(ctx, db, strings.TrimPrefix(name, "migrations/")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, name string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting migration %s: %w", name, err)
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	row := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE name = ?)", name)
+	if err := row.Scan(&applied); err != nil {
+		return fmt.Errorf("error checking migration %s: %w", name, err)
+	}
+	if applied {
+		return nil
+	}
+
+	migration, err := migrationsFS.ReadFile("migrations/" + name)
+	if err != nil {
+		return fmt.Errorf("error reading migration %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, string(migration)); err != nil {
+		return fmt.Errorf("error applying migration %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (name) VALUES (?)", name); err != nil {
+		return fmt.Errorf("error recording migration %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing migration %s: %w", name, err)
+	}
+
+	return nil
+}
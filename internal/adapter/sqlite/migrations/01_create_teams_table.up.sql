@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY,
+    reviewer_strategy TEXT NOT NULL DEFAULT 'RANDOM'
+);
@@ -0,0 +1,15 @@
+-- SQLite has no arrays, assigned_reviewers is a JSON array of user ids
+CREATE TABLE IF NOT EXISTS pr_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED')),
+    assigned_reviewers TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(assigned_reviewers) AND json_type(assigned_reviewers) = 'array'),
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    merged_at TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT max_reviewers CHECK (json_array_length(assigned_reviewers) <= 2)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_requests_author_id ON pr_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_requests_status ON pr_requests(status);
@@ -0,0 +1,21 @@
+-- SQLite cannot change a CHECK constraint, the table is rebuilt with the new one
+CREATE TABLE pr_requests_new (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED', 'CLOSED', 'DRAFT')),
+    assigned_reviewers TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(assigned_reviewers) AND json_type(assigned_reviewers) = 'array'),
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    merged_at TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT max_reviewers CHECK (json_array_length(assigned_reviewers) <= 2)
+);
+
+INSERT INTO pr_requests_new (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, version)
+SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, version FROM pr_requests;
+
+DROP TABLE pr_requests;
+ALTER TABLE pr_requests_new RENAME TO pr_requests;
+
+CREATE INDEX IF NOT EXISTS idx_pr_requests_author_id ON pr_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_requests_status ON pr_requests(status);
@@ -0,0 +1,11 @@
+-- reviewer_id has no foreign key, like assigned_reviewers, so removed users keep their reviews
+CREATE TABLE IF NOT EXISTS pr_reviews (
+    pull_request_id TEXT NOT NULL REFERENCES pr_requests(pull_request_id),
+    reviewer_id TEXT NOT NULL,
+    verdict TEXT NOT NULL CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_reviewer_id ON pr_reviews(reviewer_id);
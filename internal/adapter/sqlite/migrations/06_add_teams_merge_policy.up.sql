@@ -0,0 +1,4 @@
+-- merge_lead_ids is a JSON array of user ids, like assigned_reviewers
+ALTER TABLE teams ADD COLUMN min_approvals INTEGER NOT NULL DEFAULT 0 CHECK (min_approvals >= 0);
+ALTER TABLE teams ADD COLUMN block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE teams ADD COLUMN merge_lead_ids TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(merge_lead_ids) AND json_type(merge_lead_ids) = 'array');
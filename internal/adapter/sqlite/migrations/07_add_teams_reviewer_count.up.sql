@@ -0,0 +1,42 @@
+-- The number of reviewers is a team setting validated by the service, it replaces the max_reviewers constraint
+ALTER TABLE teams ADD COLUMN reviewer_count INTEGER NOT NULL DEFAULT 2;
+
+-- SQLite cannot drop a CHECK constraint, the table is rebuilt without it.
+-- pr_reviews references pr_requests, so its rows are set aside while both tables are recreated
+CREATE TABLE pr_reviews_backup AS SELECT * FROM pr_reviews;
+DROP TABLE pr_reviews;
+
+CREATE TABLE pr_requests_new (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED', 'CLOSED', 'DRAFT')),
+    assigned_reviewers TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(assigned_reviewers) AND json_type(assigned_reviewers) = 'array'),
+    created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    merged_at TEXT,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO pr_requests_new (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, version)
+SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, version FROM pr_requests;
+
+DROP TABLE pr_requests;
+ALTER TABLE pr_requests_new RENAME TO pr_requests;
+
+CREATE INDEX IF NOT EXISTS idx_pr_requests_author_id ON pr_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_requests_status ON pr_requests(status);
+
+CREATE TABLE pr_reviews (
+    pull_request_id TEXT NOT NULL REFERENCES pr_requests(pull_request_id),
+    reviewer_id TEXT NOT NULL,
+    verdict TEXT NOT NULL CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, reviewer_id)
+);
+
+INSERT INTO pr_reviews (pull_request_id, reviewer_id, verdict, created_at, updated_at)
+SELECT pull_request_id, reviewer_id, verdict, created_at, updated_at FROM pr_reviews_backup;
+DROP TABLE pr_reviews_backup;
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_reviewer_id ON pr_reviews(reviewer_id);
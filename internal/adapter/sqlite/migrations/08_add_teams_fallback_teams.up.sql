@@ -0,0 +1,2 @@
+-- fallback_teams is a JSON array of team names, like merge_lead_ids
+ALTER TABLE teams ADD COLUMN fallback_teams TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(fallback_teams) AND json_type(fallback_teams) = 'array');
@@ -0,0 +1,10 @@
+-- user_id has no foreign key, like assigned_reviewers, so removed users keep their history
+CREATE TABLE IF NOT EXISTS pr_reviewer_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pr_requests(pull_request_id),
+    user_id TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('ADDED', 'REMOVED')),
+    changed_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_history_pull_request_id ON pr_reviewer_history(pull_request_id);
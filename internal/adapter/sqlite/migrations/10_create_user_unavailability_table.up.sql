@@ -0,0 +1,12 @@
+-- ends_at is exclusive, handed_over is set once the user's OPEN reviews are reassigned
+CREATE TABLE IF NOT EXISTS user_unavailability (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TEXT NOT NULL,
+    ends_at TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    handed_over BOOLEAN NOT NULL DEFAULT false,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_unavailability_user_id ON user_unavailability(user_id);
@@ -0,0 +1,2 @@
+-- ownership_rules is an ordered JSON array of {"pattern": ..., "owners": [...]}, the last matching rule wins
+ALTER TABLE teams ADD COLUMN ownership_rules TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(ownership_rules) AND json_type(ownership_rules) = 'array');
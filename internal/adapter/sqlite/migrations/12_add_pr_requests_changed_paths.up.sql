@@ -0,0 +1,2 @@
+-- changed_paths is a JSON array of file paths, like assigned_reviewers
+ALTER TABLE pr_requests ADD COLUMN changed_paths TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(changed_paths) AND json_type(changed_paths) = 'array');
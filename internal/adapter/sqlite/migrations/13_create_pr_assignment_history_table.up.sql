@@ -0,0 +1,19 @@
+-- One row per pool a reviewer decision looked at. User ids have no foreign
+-- keys, like assigned_reviewers, so removed users keep their history.
+-- candidates and chosen are JSON arrays of ids, excluded a JSON array of
+-- {"user_id": ..., "reason": ...}
+CREATE TABLE IF NOT EXISTS pr_assignment_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pr_requests(pull_request_id),
+    cause TEXT NOT NULL CHECK (cause IN ('INITIAL', 'REASSIGNMENT', 'TOP_UP', 'DEACTIVATION', 'UNAVAILABILITY')),
+    replaced_user_id TEXT,
+    pool TEXT NOT NULL CHECK (pool IN ('OWNER', 'TEAM_MEMBER', 'FALLBACK_TEAM')),
+    team_name TEXT NOT NULL,
+    strategy TEXT NOT NULL,
+    candidates TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(candidates) AND json_type(candidates) = 'array'),
+    excluded TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(excluded) AND json_type(excluded) = 'array'),
+    chosen TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(chosen) AND json_type(chosen) = 'array'),
+    decided_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_assignment_history_pull_request_id ON pr_assignment_history(pull_request_id);
@@ -0,0 +1,33 @@
+-- SQLite cannot change a CHECK constraint, the table is rebuilt with the new
+-- one. Dropping the old table drops its triggers, so they are created again.
+CREATE TABLE audit_log_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL CHECK (target_type IN ('TEAM', 'USER', 'PULL_REQUEST', 'UNAVAILABILITY', 'ROSTER', 'WEBHOOK')),
+    target_id TEXT NOT NULL,
+    before TEXT CHECK (before IS NULL OR json_valid(before)),
+    after TEXT CHECK (after IS NULL OR json_valid(after)),
+    request_id TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+INSERT INTO audit_log_new (id, actor, action, target_type, target_id, before, after, request_id, created_at)
+SELECT id, actor, action, target_type, target_id, before, after, request_id, created_at FROM audit_log;
+
+DROP TABLE audit_log;
+ALTER TABLE audit_log_new RENAME TO audit_log;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,331 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// timeLayout has a fixed width and times are stored in UTC,
+// so ORDER BY on the TEXT columns is chronological
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+const prColumns = "pull_request_id, pull_request_name, author_id, status, assigned_reviewers, changed_paths, created_at, merged_at, version"
+
+type SQLitePullRequestTable struct {
+	Conn    DBTX
+	PRTable string
+}
+
+func NewPullRequestRepo(
+	conn DBTX,
+	prTable string,
+) service.PullRequestRepository {
+	return &SQLitePullRequestTable{Conn: conn, PRTable: prTable}
+}
+
+func (p *SQLitePullRequestTable) Create(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at, changed_paths) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING %s",
+		p.PRTable, prColumns,
+	)
+
+	reviewers, err := encodeIDs(pr.AssignedReviewers)
+	if err != nil {
+		return nil, err
+	}
+	changedPaths, err := encodeIDs(pr.ChangedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	row := p.Conn.QueryRowContext(
+		ctx,
+		insertQuery,
+		pr.ID, pr.Name, pr.AuthorID, string(pr.Status), reviewers, formatTime(pr.CreatedAt), formatTime(pr.MergedAt), changedPaths,
+	)
+
+	createdPR, err := scanPullRequest(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, &domain.PullRequestExistsError{PullRequestID: pr.ID}
+		}
+		return nil, fmt.Errorf("unhandled error inserting PR into SQLite PR table: %w", err)
+	}
+
+	return createdPR, nil
+}
+
+func (p *SQLitePullRequestTable) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = ?",
+		prColumns, p.PRTable,
+	)
+
+	row := p.Conn.QueryRowContext(ctx, selectQuery, prID)
+
+	pr, err := scanPullRequest(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("pull request not found: %w", err)
+		}
+		return nil, fmt.Errorf("error getting pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (p *SQLitePullRequestTable) Exists(ctx context.Context, prID string) (bool, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT EXISTS(SELECT 1 FROM %s WHERE pull_request_id = ?)",
+		p.PRTable,
+	)
+
+	row := p.Conn.QueryRowContext(ctx, selectQuery, prID)
+
+	var exists bool
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking if PR exists: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (p *SQLitePullRequestTable) ExistsByAuthor(ctx context.Context, authorID string) (bool, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT EXISTS(SELECT 1 FROM %s WHERE author_id = ?)",
+		p.PRTable,
+	)
+
+	row := p.Conn.QueryRowContext(ctx, selectQuery, authorID)
+
+	var exists bool
+	err := row.Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking if author has PRs: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (p *SQLitePullRequestTable) Update(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET pull_request_name = ?, author_id = ?, status = ?, assigned_reviewers = ?, created_at = ?, merged_at = ?, version = version + 1 WHERE pull_request_id = ? AND version = ? RETURNING %s",
+		p.PRTable, prColumns,
+	)
+
+	reviewers, err := encodeIDs(pr.AssignedReviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	row := p.Conn.QueryRowContext(
+		ctx,
+		updateQuery,
+		pr.Name, pr.AuthorID, string(pr.Status), reviewers, formatTime(pr.CreatedAt), formatTime(pr.MergedAt), pr.ID, pr.Version,
+	)
+
+	updatedPR, err := scanPullRequest(row)
+	if err != nil {
+		// Nothing matched the version we read, someone has updated the PR since
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.PullRequestConflictError{PullRequestID: pr.ID, Version: pr.Version}
+		}
+		return nil, fmt.Errorf("error updating pull request: %w", err)
+	}
+
+	return updatedPR, nil
+}
+
+func (p *SQLitePullRequestTable) GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE EXISTS (SELECT 1 FROM json_each(assigned_reviewers) WHERE value = ?) ORDER BY created_at DESC",
+		prColumns, p.PRTable,
+	)
+
+	rows, err := p.Conn.QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying PRs by reviewer: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPullRequests(rows)
+}
+
+func (p *SQLitePullRequestTable) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	countQuery := fmt.Sprintf(
+		"SELECT reviewer.value, COUNT(*) FROM %s, json_each(assigned_reviewers) AS reviewer WHERE status = 'OPEN' AND reviewer.value IN (SELECT value FROM json_each(?)) GROUP BY reviewer.value",
+		p.PRTable,
+	)
+
+	ids, err := encodeIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.Conn.QueryContext(ctx, countQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error counting open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var reviewer string
+		var count int
+		if err := rows.Scan(&reviewer, &count); err != nil {
+			return nil, fmt.Errorf("error scanning open review count: %w", err)
+		}
+		counts[reviewer] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open review counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (p *SQLitePullRequestTable) GetOpenByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE status = 'OPEN' AND EXISTS (SELECT 1 FROM json_each(assigned_reviewers) WHERE value IN (SELECT value FROM json_each(?))) ORDER BY created_at",
+		prColumns, p.PRTable,
+	)
+
+	ids, err := encodeIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.Conn.QueryContext(ctx, selectQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open PRs by reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPullRequests(rows)
+}
+
+func (p *SQLitePullRequestTable) GetOpenByAuthors(ctx context.Context, authorIDs []string) ([]domain.PullRequest, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE status = 'OPEN' AND author_id IN (SELECT value FROM json_each(?)) ORDER BY created_at",
+		prColumns, p.PRTable,
+	)
+
+	ids, err := encodeIDs(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.Conn.QueryContext(ctx, selectQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open PRs by authors: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPullRequests(rows)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPullRequest(row rowScanner) (*domain.PullRequest, error) {
+	var pr domain.PullRequest
+	var status string
+	var reviewers, changedPaths string
+	var createdAt, mergedAt sql.NullString
+	err := row.Scan(
+		&pr.ID,
+		&pr.Name,
+		&pr.AuthorID,
+		&status,
+		&reviewers,
+		&changedPaths,
+		&createdAt,
+		&mergedAt,
+		&pr.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.Status = domain.PullRequestStatus(status)
+	if err := json.Unmarshal([]byte(reviewers), &pr.AssignedReviewers); err != nil {
+		return nil, fmt.Errorf("error decoding assigned reviewers of PR %s: %w", pr.ID, err)
+	}
+	// Like the NOT NULL DEFAULT '{}' column in Postgres, reviewers are never nil
+	if pr.AssignedReviewers == nil {
+		pr.AssignedReviewers = []string{}
+	}
+	if err := json.Unmarshal([]byte(changedPaths), &pr.ChangedPaths); err != nil {
+		return nil, fmt.Errorf("error decoding changed paths of PR %s: %w", pr.ID, err)
+	}
+	if pr.ChangedPaths == nil {
+		pr.ChangedPaths = []string{}
+	}
+	if pr.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, fmt.Errorf("error decoding creation time of PR %s: %w", pr.ID, err)
+	}
+	if pr.MergedAt, err = parseTime(mergedAt); err != nil {
+		return nil, fmt.Errorf("error decoding merge time of PR %s: %w", pr.ID, err)
+	}
+
+	return &pr, nil
+}
+
+func scanPullRequests(rows *sql.Rows) ([]domain.PullRequest, error) {
+	var prs []domain.PullRequest
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning pull request: %w", err)
+		}
+		prs = append(prs, *pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+// encodeIDs turns ids into the JSON array stored in assigned_reviewers or
+// changed_paths and expected by json_each in place of Postgres array parameters.
+func encodeIDs(ids []string) (string, error) {
+	if ids == nil {
+		ids = []string{}
+	}
+	encoded, err := json.Marshal(ids)
+	if err != nil {
+		return "", fmt.Errorf("error encoding ids: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func formatTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	// RFC3339Nano also accepts the millisecond precision of the column default
+	t, err := time.Parse(time.RFC3339Nano, value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,33 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/sqlite"
+	"github.com/raccoon00/avito-pr/internal/service"
+	"github.com/raccoon00/avito-pr/internal/service/repotest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "prs.db"))
+	if err != nil {
+		t.Logf("Could not open database: %v", err)
+		t.FailNow()
+	}
+	defer db.Close()
+
+	if err := sqlite.Migrate(t.Context(), db); err != nil {
+		t.Logf("Could not migrate database: %v", err)
+		t.FailNow()
+	}
+	// Applying the migrations again must be a no-op
+	if err := sqlite.Migrate(t.Context(), db); err != nil {
+		t.Logf("Could not migrate database twice: %v", err)
+		t.FailNow()
+	}
+
+	repotest.Run(t, func(t *testing.T) service.Repositories {
+		return sqlite.NewRepositories(db, sqlite.DefaultTables())
+	})
+}
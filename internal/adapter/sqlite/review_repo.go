@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const reviewColumns = "pull_request_id, reviewer_id, verdict, created_at, updated_at"
+
+type SQLiteReviewTable struct {
+	Conn        DBTX
+	ReviewTable string
+}
+
+func NewReviewRepo(
+	conn DBTX,
+	reviewTable string,
+) service.ReviewRepository {
+	return &SQLiteReviewTable{Conn: conn, ReviewTable: reviewTable}
+}
+
+func (r *SQLiteReviewTable) Upsert(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	upsertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?) ON CONFLICT (pull_request_id, reviewer_id) DO UPDATE SET verdict = excluded.verdict, updated_at = excluded.updated_at RETURNING %s",
+		r.ReviewTable, reviewColumns, reviewColumns,
+	)
+
+	row := r.Conn.QueryRowContext(
+		ctx,
+		upsertQuery,
+		review.PullRequestID, review.ReviewerID, string(review.Verdict), formatTime(&review.CreatedAt), formatTime(&review.UpdatedAt),
+	)
+
+	upserted, err := scanReview(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, fmt.Errorf("pull request not found: %s", review.PullRequestID)
+		}
+		return nil, fmt.Errorf("error upserting review: %w", err)
+	}
+
+	return upserted, nil
+}
+
+func (r *SQLiteReviewTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.Review, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = ? ORDER BY created_at, reviewer_id",
+		reviewColumns, r.ReviewTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews by PR: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *SQLiteReviewTable) GetByReviewer(ctx context.Context, userID string) ([]domain.Review, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE reviewer_id = ? ORDER BY created_at, pull_request_id",
+		reviewColumns, r.ReviewTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviews by reviewer: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReviews(rows)
+}
+
+func (r *SQLiteReviewTable) DeleteByReviewers(ctx context.Context, prID string, reviewerIDs []string) error {
+	deleteQuery := fmt.Sprintf(
+		"DELETE FROM %s WHERE pull_request_id = ? AND reviewer_id IN (SELECT value FROM json_each(?))",
+		r.ReviewTable,
+	)
+
+	ids, err := encodeIDs(reviewerIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Conn.ExecContext(ctx, deleteQuery, prID, ids)
+	if err != nil {
+		return fmt.Errorf("error deleting reviews: %w", err)
+	}
+
+	return nil
+}
+
+func scanReview(row rowScanner) (*domain.Review, error) {
+	var review domain.Review
+	var verdict string
+	var createdAt, updatedAt string
+	err := row.Scan(
+		&review.PullRequestID,
+		&review.ReviewerID,
+		&verdict,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Verdict = domain.ReviewVerdict(verdict)
+	if review.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("error decoding creation time of review %s/%s: %w", review.PullRequestID, review.ReviewerID, err)
+	}
+	if review.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, fmt.Errorf("error decoding update time of review %s/%s: %w", review.PullRequestID, review.ReviewerID, err)
+	}
+
+	return &review, nil
+}
+
+func scanReviews(rows *sql.Rows) ([]domain.Review, error) {
+	reviews := []domain.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviews: %w", err)
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const reviewerChangeColumns = "pull_request_id, user_id, action, changed_at"
+
+type SQLiteReviewerHistoryTable struct {
+	Conn         DBTX
+	HistoryTable string
+}
+
+func NewReviewerHistoryRepo(
+	conn DBTX,
+	historyTable string,
+) service.ReviewerHistoryRepository {
+	return &SQLiteReviewerHistoryTable{Conn: conn, HistoryTable: historyTable}
+}
+
+func (r *SQLiteReviewerHistoryTable) Add(ctx context.Context, change *domain.ReviewerChange) (*domain.ReviewerChange, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (?, ?, ?, ?) RETURNING %s",
+		r.HistoryTable, reviewerChangeColumns, reviewerChangeColumns,
+	)
+
+	row := r.Conn.QueryRowContext(ctx, insertQuery, change.PullRequestID, change.UserID, string(change.Action), formatTime(&change.ChangedAt))
+
+	added, err := scanReviewerChange(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, fmt.Errorf("pull request not found: %s", change.PullRequestID)
+		}
+		return nil, fmt.Errorf("error adding reviewer change: %w", err)
+	}
+
+	return added, nil
+}
+
+func (r *SQLiteReviewerHistoryTable) GetByPullRequest(ctx context.Context, prID string) ([]domain.ReviewerChange, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE pull_request_id = ? ORDER BY id",
+		reviewerChangeColumns, r.HistoryTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reviewer history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []domain.ReviewerChange{}
+	for rows.Next() {
+		change, err := scanReviewerChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning reviewer change: %w", err)
+		}
+		changes = append(changes, *change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer history: %w", err)
+	}
+
+	return changes, nil
+}
+
+func scanReviewerChange(row rowScanner) (*domain.ReviewerChange, error) {
+	var change domain.ReviewerChange
+	var action, changedAt string
+	err := row.Scan(&change.PullRequestID, &change.UserID, &action, &changedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Action = domain.ReviewerChangeAction(action)
+	if change.ChangedAt, err = time.Parse(time.RFC3339Nano, changedAt); err != nil {
+		return nil, fmt.Errorf("error decoding time of reviewer change on PR %s: %w", change.PullRequestID, err)
+	}
+
+	return &change, nil
+}
@@ -0,0 +1,375 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const teamSettingsColumns = "reviewer_strategy, reviewer_count, min_approvals, block_on_changes_requested, merge_lead_ids, fallback_teams, ownership_rules"
+
+type SQLiteTeamTable struct {
+	Conn       DBTX
+	TeamTable  string
+	UsersTable string
+}
+
+func NewTeamRepo(
+	conn DBTX,
+	teamTable string,
+	usersTable string,
+) service.TeamRepository {
+	return &SQLiteTeamTable{Conn: conn, TeamTable: teamTable, UsersTable: usersTable}
+}
+
+func (t *SQLiteTeamTable) Create(ctx context.Context, team *domain.Team) (*domain.Team, error) {
+	insertTeamQuery := fmt.Sprintf(
+		"INSERT INTO %s (team_name, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING team_name, %s",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns,
+	)
+	settingsArgs, err := teamSettingsArgs(&team.Settings)
+	if err != nil {
+		return nil, err
+	}
+	row := t.Conn.QueryRowContext(
+		ctx,
+		insertTeamQuery,
+		append([]any{team.Name}, settingsArgs...)...,
+	)
+
+	var team_name string
+	var settings teamSettingsRow
+	err = row.Scan(append([]any{&team_name}, settings.dest()...)...)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, &domain.TeamExistsError{TeamName: team.Name}
+		}
+		return nil, fmt.Errorf("Unhandled error inserting team into SQLite Team table: %w", err)
+	}
+	teamSettings, err := settings.settings()
+	if err != nil {
+		return nil, err
+	}
+	newTeam := domain.Team{
+		Name:     team_name,
+		Members:  make([]domain.User, 0, len(team.Members)),
+		Settings: teamSettings,
+	}
+
+	insertUser := fmt.Sprintf(
+		"INSERT INTO %v (user_id, username, is_active, team_name) VALUES (?, ?, ?, ?) RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		t.UsersTable,
+	)
+	for _, member := range team.Members {
+		row := t.Conn.QueryRowContext(
+			ctx,
+			insertUser,
+			member.Id, member.Name, member.IsActive, member.Team,
+		)
+
+		var user domain.User
+		err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+
+		if err != nil {
+			return &newTeam, fmt.Errorf("Unhandled error inerting user into SQLite Users table: %w", err)
+		}
+
+		newTeam.Members = append(newTeam.Members, user)
+	}
+
+	return &newTeam, nil
+}
+
+func (t *SQLiteTeamTable) Get(ctx context.Context, teamName string) (*domain.Team, error) {
+	checkTeamQuery := fmt.Sprintf("SELECT team_name, %s FROM %s WHERE team_name = ?", teamSettingsColumns, t.TeamTable)
+	row := t.Conn.QueryRowContext(ctx, checkTeamQuery, teamName)
+
+	var teamNameFromDB string
+	var settings teamSettingsRow
+	err := row.Scan(append([]any{&teamNameFromDB}, settings.dest()...)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error getting team: %w", err)
+	}
+	teamSettings, err := settings.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	getUsersQuery := fmt.Sprintf("SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s WHERE team_name = ? ORDER BY user_id", t.UsersTable)
+	rows, err := t.Conn.QueryContext(ctx, getUsersQuery, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("error querying users for team: %w", err)
+	}
+	defer rows.Close()
+
+	team := &domain.Team{
+		Name:     teamName,
+		Members:  []domain.User{},
+		Settings: teamSettings,
+	}
+
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		team.Members = append(team.Members, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return team, nil
+}
+
+func (t *SQLiteTeamTable) List(ctx context.Context) ([]domain.Team, error) {
+	teamsQuery := fmt.Sprintf("SELECT team_name, %s FROM %s ORDER BY team_name", teamSettingsColumns, t.TeamTable)
+	rows, err := t.Conn.QueryContext(ctx, teamsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying teams: %w", err)
+	}
+	defer rows.Close()
+
+	teamIdx := make(map[string]int)
+	teams := []domain.Team{}
+	for rows.Next() {
+		var teamName string
+		var settings teamSettingsRow
+		if err := rows.Scan(append([]any{&teamName}, settings.dest()...)...); err != nil {
+			return nil, fmt.Errorf("error scanning team: %w", err)
+		}
+		teamSettings, err := settings.settings()
+		if err != nil {
+			return nil, err
+		}
+		teamIdx[teamName] = len(teams)
+		teams = append(teams, domain.Team{
+			Name:     teamName,
+			Members:  []domain.User{},
+			Settings: teamSettings,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating teams: %w", err)
+	}
+
+	usersQuery := fmt.Sprintf("SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s ORDER BY user_id", t.UsersTable)
+	userRows, err := t.Conn.QueryContext(ctx, usersQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying users: %w", err)
+	}
+	defer userRows.Close()
+
+	users, err := scanUsers(userRows, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		i := teamIdx[user.Team]
+		teams[i].Members = append(teams[i].Members, user)
+	}
+
+	return teams, nil
+}
+
+func (t *SQLiteTeamTable) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE team_name = ?", teamSettingsColumns, t.TeamTable)
+	row := t.Conn.QueryRowContext(ctx, selectQuery, teamName)
+
+	var settings teamSettingsRow
+	err := row.Scan(settings.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error getting team settings: %w", err)
+	}
+
+	teamSettings, err := settings.settings()
+	if err != nil {
+		return nil, err
+	}
+	return &teamSettings, nil
+}
+
+// GetSettingsForUpdate needs no lock: transactions begin immediately and
+// hold the write lock of the whole database.
+func (t *SQLiteTeamTable) GetSettingsForUpdate(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	return t.GetSettings(ctx, teamName)
+}
+
+func (t *SQLiteTeamTable) UpdateSettings(ctx context.Context, teamName string, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET (%s) = (?, ?, ?, ?, ?, ?, ?) WHERE team_name = ? RETURNING %s",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns,
+	)
+	settingsArgs, err := teamSettingsArgs(settings)
+	if err != nil {
+		return nil, err
+	}
+	row := t.Conn.QueryRowContext(ctx, updateQuery, append(settingsArgs, teamName)...)
+
+	var updated teamSettingsRow
+	err = row.Scan(updated.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error updating team settings: %w", err)
+	}
+
+	updatedSettings, err := updated.settings()
+	if err != nil {
+		return nil, err
+	}
+	return &updatedSettings, nil
+}
+
+func (t *SQLiteTeamTable) Rename(ctx context.Context, teamName string, newName string) (*domain.Team, error) {
+	// users.team_name references the old row, so the team is copied under
+	// the new name, members are moved over and only then the old row is dropped
+	copyTeamQuery := fmt.Sprintf(
+		"INSERT INTO %s (team_name, %s) SELECT ?2, %s FROM %s WHERE team_name = ?1",
+		t.TeamTable, teamSettingsColumns, teamSettingsColumns, t.TeamTable,
+	)
+	result, err := t.Conn.ExecContext(ctx, copyTeamQuery, teamName, newName)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, &domain.TeamExistsError{TeamName: newName}
+		}
+		return nil, fmt.Errorf("error copying team: %w", err)
+	}
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error copying team: %w", err)
+	}
+	if copied == 0 {
+		return nil, &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	moveUsersQuery := fmt.Sprintf("UPDATE %s SET team_name = ? WHERE team_name = ?", t.UsersTable)
+	if _, err := t.Conn.ExecContext(ctx, moveUsersQuery, newName, teamName); err != nil {
+		return nil, fmt.Errorf("error moving users to renamed team: %w", err)
+	}
+
+	deleteTeamQuery := fmt.Sprintf("DELETE FROM %s WHERE team_name = ?", t.TeamTable)
+	if _, err := t.Conn.ExecContext(ctx, deleteTeamQuery, teamName); err != nil {
+		return nil, fmt.Errorf("error deleting renamed team: %w", err)
+	}
+
+	return t.Get(ctx, newName)
+}
+
+func (t *SQLiteTeamTable) Delete(ctx context.Context, teamName string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE team_name = ?", t.TeamTable)
+
+	result, err := t.Conn.ExecContext(ctx, deleteQuery, teamName)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return &domain.TeamNotEmptyError{TeamName: teamName}
+		}
+		return fmt.Errorf("error deleting team: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting team: %w", err)
+	}
+	if deleted == 0 {
+		return &domain.TeamNotFoundError{TeamName: teamName}
+	}
+
+	return nil
+}
+
+// teamSettingsRow receives the teamSettingsColumns of a scanned row.
+type teamSettingsRow struct {
+	strategy                string
+	reviewerCount           int
+	minApprovals            int
+	blockOnChangesRequested bool
+	mergeLeadIDs            string
+	fallbackTeams           string
+	ownershipRules          string
+}
+
+// ownershipRuleRow is an element of the ownership_rules JSON array.
+type ownershipRuleRow struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+func (r *teamSettingsRow) dest() []any {
+	return []any{&r.strategy, &r.reviewerCount, &r.minApprovals, &r.blockOnChangesRequested, &r.mergeLeadIDs, &r.fallbackTeams, &r.ownershipRules}
+}
+
+func (r *teamSettingsRow) settings() (domain.TeamSettings, error) {
+	settings := domain.TeamSettings{
+		ReviewerStrategy: domain.ReviewerStrategy(r.strategy),
+		ReviewerCount:    r.reviewerCount,
+		MergePolicy: domain.MergePolicy{
+			MinApprovals:            r.minApprovals,
+			BlockOnChangesRequested: r.blockOnChangesRequested,
+			LeadIDs:                 []string{},
+		},
+		FallbackTeams:  []string{},
+		OwnershipRules: []domain.OwnershipRule{},
+	}
+	if err := json.Unmarshal([]byte(r.mergeLeadIDs), &settings.MergePolicy.LeadIDs); err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("error decoding merge leads: %w", err)
+	}
+	if err := json.Unmarshal([]byte(r.fallbackTeams), &settings.FallbackTeams); err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("error decoding fallback teams: %w", err)
+	}
+	var ownershipRules []ownershipRuleRow
+	if err := json.Unmarshal([]byte(r.ownershipRules), &ownershipRules); err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("error decoding ownership rules: %w", err)
+	}
+	for _, rule := range ownershipRules {
+		owners := rule.Owners
+		if owners == nil {
+			owners = []string{}
+		}
+		settings.OwnershipRules = append(settings.OwnershipRules, domain.OwnershipRule{Pattern: rule.Pattern, Owners: owners})
+	}
+	return settings, nil
+}
+
+// teamSettingsArgs returns the values of teamSettingsColumns in their order.
+func teamSettingsArgs(settings *domain.TeamSettings) ([]any, error) {
+	leadIDs, err := encodeIDs(settings.MergePolicy.LeadIDs)
+	if err != nil {
+		return nil, err
+	}
+	fallbackTeams, err := encodeIDs(settings.FallbackTeams)
+	if err != nil {
+		return nil, err
+	}
+	ownershipRules := make([]ownershipRuleRow, 0, len(settings.OwnershipRules))
+	for _, rule := range settings.OwnershipRules {
+		ownershipRules = append(ownershipRules, ownershipRuleRow{Pattern: rule.Pattern, Owners: rule.Owners})
+	}
+	encodedRules, err := json.Marshal(ownershipRules)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding ownership rules: %w", err)
+	}
+	return []any{
+		string(settings.ReviewerStrategy),
+		settings.ReviewerCount,
+		settings.MergePolicy.MinApprovals,
+		settings.MergePolicy.BlockOnChangesRequested,
+		leadIDs,
+		fallbackTeams,
+		string(encodedRules),
+	}, nil
+}
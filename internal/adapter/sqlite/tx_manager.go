@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/raccoon00/avito-pr/internal/service"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx,
+// so the same repository code works inside and outside a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type Tables struct {
+	Teams           string
+	Users           string
+	PullRequests    string
+	Reviews         string
+	ReviewerHistory string
+	Unavailability  string
+	Assignments     string
+	Audit           string
+	Webhooks        string
+	Deliveries      string
+	// DeliveryAttempts is the log of delivery attempts
+	DeliveryAttempts string
+}
+
+func DefaultTables() Tables {
+	return Tables{
+		Teams:           "teams",
+		Users:           "users",
+		PullRequests:    "pr_requests",
+		Reviews:         "pr_reviews",
+		ReviewerHistory: "pr_reviewer_history",
+		Unavailability:  "user_unavailability",
+		Assignments:     "pr_assignment_history",
+		Audit:           "audit_log",
+		Webhooks:        "webhook_subscriptions",
+		Deliveries:      "webhook_deliveries",
+
+		DeliveryAttempts: "webhook_delivery_attempts",
+	}
+}
+
+// Open opens the database file at path, creating it if needed.
+// Transactions take the write lock when they start, so concurrent ones
+// wait for each other for up to busy_timeout instead of failing midway.
+func Open(path string) (*sql.DB, error) {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error opening SQLite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// NewRepositories binds all repositories to conn, which is either the database or a transaction.
+func NewRepositories(conn DBTX, tables Tables) service.Repositories {
+	return service.Repositories{
+		Teams:           NewTeamRepo(conn, tables.Teams, tables.Users),
+		Users:           NewUserRepo(conn, tables.Users, tables.Unavailability),
+		PullRequests:    NewPullRequestRepo(conn, tables.PullRequests),
+		Reviews:         NewReviewRepo(conn, tables.Reviews),
+		ReviewerHistory: NewReviewerHistoryRepo(conn, tables.ReviewerHistory),
+		Unavailability:  NewUnavailabilityRepo(conn, tables.Unavailability),
+		Assignments:     NewAssignmentHistoryRepo(conn, tables.Assignments),
+		Audit:           NewAuditLogRepo(conn, tables.Audit),
+		Webhooks:        NewWebhookRepo(conn, tables.Webhooks),
+		Deliveries:      NewWebhookDeliveryRepo(conn, tables.Deliveries, tables.DeliveryAttempts, tables.Webhooks),
+	}
+}
+
+type SQLiteTxManager struct {
+	DB     *sql.DB
+	Tables Tables
+}
+
+func NewTxManager(db *sql.DB, tables Tables) service.TxManager {
+	return &SQLiteTxManager{DB: db, Tables: tables}
+}
+
+func (m *SQLiteTxManager) WithinTx(ctx context.Context, fn func(repos service.Repositories) error) error {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(NewRepositories(tx, m.Tables)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const unavailabilityColumns = "id, user_id, starts_at, ends_at, reason, handed_over"
+
+type SQLiteUnavailabilityTable struct {
+	Conn                DBTX
+	UnavailabilityTable string
+}
+
+func NewUnavailabilityRepo(
+	conn DBTX,
+	unavailabilityTable string,
+) service.UnavailabilityRepository {
+	return &SQLiteUnavailabilityTable{Conn: conn, UnavailabilityTable: unavailabilityTable}
+}
+
+func (r *SQLiteUnavailabilityTable) Create(ctx context.Context, unavailability *domain.Unavailability) (*domain.Unavailability, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, starts_at, ends_at, reason, handed_over) VALUES (?, ?, ?, ?, ?) RETURNING %s",
+		r.UnavailabilityTable, unavailabilityColumns,
+	)
+
+	row := r.Conn.QueryRowContext(
+		ctx,
+		insertQuery,
+		unavailability.UserID,
+		formatTime(&unavailability.StartsAt),
+		formatTime(&unavailability.EndsAt),
+		unavailability.Reason,
+		unavailability.HandedOver,
+	)
+
+	created, err := scanUnavailability(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, &domain.UserNotFoundError{UserID: unavailability.UserID}
+		}
+		return nil, fmt.Errorf("error creating unavailability: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *SQLiteUnavailabilityTable) GetByID(ctx context.Context, id int64) (*domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", unavailabilityColumns, r.UnavailabilityTable)
+
+	unavailability, err := scanUnavailability(r.Conn.QueryRowContext(ctx, selectQuery, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.UnavailabilityNotFoundError{ID: id}
+		}
+		return nil, fmt.Errorf("error querying unavailability: %w", err)
+	}
+
+	return unavailability, nil
+}
+
+func (r *SQLiteUnavailabilityTable) GetByUser(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE user_id = ? ORDER BY starts_at, id",
+		unavailabilityColumns, r.UnavailabilityTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unavailability: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUnavailabilities(rows)
+}
+
+func (r *SQLiteUnavailabilityTable) Delete(ctx context.Context, id int64) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id = ?", r.UnavailabilityTable)
+
+	result, err := r.Conn.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting unavailability: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting unavailability: %w", err)
+	}
+	if deleted == 0 {
+		return &domain.UnavailabilityNotFoundError{ID: id}
+	}
+
+	return nil
+}
+
+func (r *SQLiteUnavailabilityTable) GetPendingHandOvers(ctx context.Context, at time.Time) ([]domain.Unavailability, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE handed_over = false AND starts_at <= ? AND ends_at > ? ORDER BY starts_at, id",
+		unavailabilityColumns, r.UnavailabilityTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, formatTime(&at), formatTime(&at))
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending hand-overs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUnavailabilities(rows)
+}
+
+func (r *SQLiteUnavailabilityTable) MarkHandedOver(ctx context.Context, id int64) error {
+	updateQuery := fmt.Sprintf("UPDATE %s SET handed_over = true WHERE id = ? AND NOT handed_over", r.UnavailabilityTable)
+
+	result, err := r.Conn.ExecContext(ctx, updateQuery, id)
+	if err != nil {
+		return fmt.Errorf("error marking unavailability as handed over: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error marking unavailability as handed over: %w", err)
+	}
+	if updated == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return &domain.UnavailabilityHandedOverError{ID: id}
+	}
+
+	return nil
+}
+
+func scanUnavailabilities(rows *sql.Rows) ([]domain.Unavailability, error) {
+	unavailabilities := []domain.Unavailability{}
+	for rows.Next() {
+		unavailability, err := scanUnavailability(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning unavailability: %w", err)
+		}
+		unavailabilities = append(unavailabilities, *unavailability)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unavailability: %w", err)
+	}
+
+	return unavailabilities, nil
+}
+
+func scanUnavailability(row rowScanner) (*domain.Unavailability, error) {
+	var unavailability domain.Unavailability
+	var startsAt, endsAt string
+	err := row.Scan(
+		&unavailability.ID,
+		&unavailability.UserID,
+		&startsAt,
+		&endsAt,
+		&unavailability.Reason,
+		&unavailability.HandedOver,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if unavailability.StartsAt, err = time.Parse(time.RFC3339Nano, startsAt); err != nil {
+		return nil, fmt.Errorf("error decoding start of unavailability %d: %w", unavailability.ID, err)
+	}
+	if unavailability.EndsAt, err = time.Parse(time.RFC3339Nano, endsAt); err != nil {
+		return nil, fmt.Errorf("error decoding end of unavailability %d: %w", unavailability.ID, err)
+	}
+
+	return &unavailability, nil
+}
@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+type SQLiteUserTable struct {
+	Conn                DBTX
+	UsersTable          string
+	UnavailabilityTable string
+}
+
+func NewUserRepo(
+	conn DBTX,
+	usersTable string,
+	unavailabilityTable string,
+) service.UserRepository {
+	return &SQLiteUserTable{Conn: conn, UsersTable: usersTable, UnavailabilityTable: unavailabilityTable}
+}
+
+func (u *SQLiteUserTable) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET is_active = ? WHERE user_id = ? RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, updateQuery, isActive, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *SQLiteUserTable) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s WHERE user_id = ?",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, selectQuery, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *SQLiteUserTable) GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserID string) ([]domain.User, error) {
+	selectQuery := fmt.Sprintf(
+		`SELECT user_id, username, is_active, team_name, max_open_reviews FROM %s u
+		WHERE team_name = ? AND is_active = true AND user_id != ?
+			AND NOT EXISTS (SELECT 1 FROM %s a WHERE a.user_id = u.user_id AND a.starts_at <= ? AND a.ends_at > ?)
+		ORDER BY user_id`,
+		u.UsersTable, u.UnavailabilityTable,
+	)
+
+	now := time.Now()
+	rows, err := u.Conn.QueryContext(ctx, selectQuery, teamName, excludeUserID, formatTime(&now), formatTime(&now))
+	if err != nil {
+		return nil, fmt.Errorf("error querying active team members: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows, nil)
+}
+
+func (u *SQLiteUserTable) SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (*domain.User, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET max_open_reviews = ? WHERE user_id = ? RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, updateQuery, maxOpenReviews, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		return nil, fmt.Errorf("error updating user review limit: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *SQLiteUserTable) DeactivateUsers(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	deactivateQuery := fmt.Sprintf(
+		"UPDATE %s SET is_active = false WHERE user_id IN (SELECT value FROM json_each(?)) RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	ids, err := encodeIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := u.Conn.QueryContext(ctx, deactivateQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error deactivating users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUsers(rows, []domain.User{})
+}
+
+func (u *SQLiteUserTable) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, username, is_active, team_name, max_open_reviews) VALUES (?, ?, ?, ?, ?) RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, insertQuery, user.Id, user.Name, user.IsActive, user.Team, user.MaxOpenReviews)
+
+	var createdUser domain.User
+	err := row.Scan(&createdUser.Id, &createdUser.Name, &createdUser.IsActive, &createdUser.Team, &createdUser.MaxOpenReviews)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, &domain.UserExistsError{UserID: user.Id}
+		}
+		if isForeignKeyViolation(err) {
+			return nil, &domain.TeamNotFoundError{TeamName: user.Team}
+		}
+		return nil, fmt.Errorf("error inserting user: %w", err)
+	}
+
+	return &createdUser, nil
+}
+
+func (u *SQLiteUserTable) Upsert(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	// SQLite has no way to tell an insert from an update in RETURNING,
+	// the check is safe as write transactions take the lock up front
+	existsQuery := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE user_id = ?)", u.UsersTable)
+
+	var exists bool
+	if err := u.Conn.QueryRowContext(ctx, existsQuery, user.Id).Scan(&exists); err != nil {
+		return nil, false, fmt.Errorf("error checking if user exists: %w", err)
+	}
+
+	upsertQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, username, is_active, team_name) VALUES (?, ?, ?, ?) ON CONFLICT (user_id) DO UPDATE SET username = excluded.username, is_active = excluded.is_active, team_name = excluded.team_name RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, upsertQuery, user.Id, user.Name, user.IsActive, user.Team)
+
+	var upsertedUser domain.User
+	err := row.Scan(&upsertedUser.Id, &upsertedUser.Name, &upsertedUser.IsActive, &upsertedUser.Team, &upsertedUser.MaxOpenReviews)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, false, &domain.TeamNotFoundError{TeamName: user.Team}
+		}
+		return nil, false, fmt.Errorf("error upserting user: %w", err)
+	}
+
+	return &upsertedUser, !exists, nil
+}
+
+func (u *SQLiteUserTable) SetTeam(ctx context.Context, userID string, teamName string) (*domain.User, error) {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET team_name = ? WHERE user_id = ? RETURNING user_id, username, is_active, team_name, max_open_reviews",
+		u.UsersTable,
+	)
+
+	row := u.Conn.QueryRowContext(ctx, updateQuery, teamName, userID)
+
+	var user domain.User
+	err := row.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.UserNotFoundError{UserID: userID}
+		}
+		if isForeignKeyViolation(err) {
+			return nil, &domain.TeamNotFoundError{TeamName: teamName}
+		}
+		return nil, fmt.Errorf("error moving user to team: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (u *SQLiteUserTable) Delete(ctx context.Context, userID string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", u.UsersTable)
+
+	result, err := u.Conn.ExecContext(ctx, deleteQuery, userID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return &domain.UserHasPullRequestsError{UserID: userID}
+		}
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	if deleted == 0 {
+		return &domain.UserNotFoundError{UserID: userID}
+	}
+
+	return nil
+}
+
+// scanUsers appends every row to users, which lets callers
+// choose between a nil and an empty result for no rows.
+func scanUsers(rows *sql.Rows, users []domain.User) ([]domain.User, error) {
+	for rows.Next() {
+		var user domain.User
+		err := rows.Scan(&user.Id, &user.Name, &user.IsActive, &user.Team, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,429 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const (
+	webhookSubscriptionColumns = "id, url, secret, events, team_name, active, created_at"
+	webhookDeliveryColumns     = "id, subscription_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at"
+	webhookAttemptColumns      = "delivery_id, attempt, status_code, error, attempted_at"
+)
+
+type SQLiteWebhookTable struct {
+	Conn              DBTX
+	SubscriptionTable string
+}
+
+func NewWebhookRepo(
+	conn DBTX,
+	subscriptionTable string,
+) service.WebhookRepository {
+	return &SQLiteWebhookTable{Conn: conn, SubscriptionTable: subscriptionTable}
+}
+
+func (r *SQLiteWebhookTable) Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	events, err := encodeEvents(subscription.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (url, secret, events, team_name, active, created_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING %s",
+		r.SubscriptionTable, webhookSubscriptionColumns,
+	)
+
+	row := r.Conn.QueryRowContext(ctx, insertQuery,
+		subscription.URL,
+		subscription.Secret,
+		events,
+		subscription.TeamName,
+		subscription.Active,
+		formatTime(&subscription.CreatedAt),
+	)
+
+	created, err := scanWebhookSubscription(row)
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook subscription: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *SQLiteWebhookTable) Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", webhookSubscriptionColumns, r.SubscriptionTable)
+
+	subscription, err := scanWebhookSubscription(r.Conn.QueryRowContext(ctx, selectQuery, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.WebhookNotFoundError{ID: id}
+		}
+		return nil, fmt.Errorf("error querying webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *SQLiteWebhookTable) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s ORDER BY id", webhookSubscriptionColumns, r.SubscriptionTable)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *SQLiteWebhookTable) Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	events, err := encodeEvents(subscription.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET url = ?, secret = ?, events = ?, team_name = ?, active = ? WHERE id = ? RETURNING %s",
+		r.SubscriptionTable, webhookSubscriptionColumns,
+	)
+
+	row := r.Conn.QueryRowContext(ctx, updateQuery,
+		subscription.URL,
+		subscription.Secret,
+		events,
+		subscription.TeamName,
+		subscription.Active,
+		subscription.ID,
+	)
+
+	updated, err := scanWebhookSubscription(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &domain.WebhookNotFoundError{ID: subscription.ID}
+		}
+		return nil, fmt.Errorf("error updating webhook subscription: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *SQLiteWebhookTable) Delete(ctx context.Context, id int64) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE id = ?", r.SubscriptionTable)
+
+	result, err := r.Conn.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook subscription: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting webhook subscription: %w", err)
+	}
+	if deleted == 0 {
+		return &domain.WebhookNotFoundError{ID: id}
+	}
+
+	return nil
+}
+
+func encodeEvents(events []domain.EventType) (string, error) {
+	names := make([]string, 0, len(events))
+	for _, eventType := range events {
+		names = append(names, string(eventType))
+	}
+	return encodeIDs(names)
+}
+
+func scanWebhookSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	var events, createdAt string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.Secret,
+		&events,
+		&subscription.TeamName,
+		&subscription.Active,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Events = []domain.EventType{}
+	if err := json.Unmarshal([]byte(events), &subscription.Events); err != nil {
+		return nil, fmt.Errorf("error decoding events of webhook subscription %d: %w", subscription.ID, err)
+	}
+	if subscription.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("error decoding creation time of webhook subscription %d: %w", subscription.ID, err)
+	}
+
+	return &subscription, nil
+}
+
+type SQLiteWebhookDeliveryTable struct {
+	Conn              DBTX
+	DeliveryTable     string
+	AttemptTable      string
+	SubscriptionTable string
+}
+
+func NewWebhookDeliveryRepo(
+	conn DBTX,
+	deliveryTable string,
+	attemptTable string,
+	subscriptionTable string,
+) service.WebhookDeliveryRepository {
+	return &SQLiteWebhookDeliveryTable{
+		Conn:              conn,
+		DeliveryTable:     deliveryTable,
+		AttemptTable:      attemptTable,
+		SubscriptionTable: subscriptionTable,
+	}
+}
+
+func (r *SQLiteWebhookDeliveryTable) Create(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	insertQuery := fmt.Sprintf(
+		`INSERT INTO %s (subscription_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at, delivered_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING %s`,
+		r.DeliveryTable, webhookDeliveryColumns,
+	)
+
+	row := r.Conn.QueryRowContext(ctx, insertQuery,
+		delivery.SubscriptionID,
+		string(delivery.EventType),
+		string(delivery.Payload),
+		string(delivery.Status),
+		delivery.Attempts,
+		formatTime(&delivery.NextAttemptAt),
+		delivery.LastError,
+		formatTime(&delivery.CreatedAt),
+		formatTime(delivery.DeliveredAt),
+	)
+
+	created, err := scanWebhookDelivery(row)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, &domain.WebhookNotFoundError{ID: delivery.SubscriptionID}
+		}
+		return nil, fmt.Errorf("error creating webhook delivery: %w", err)
+	}
+
+	return created, nil
+}
+
+// ClaimDue needs no row locks, SQLite runs one write transaction at a time.
+func (r *SQLiteWebhookDeliveryTable) ClaimDue(ctx context.Context, at, until time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	selectQuery := fmt.Sprintf(
+		`SELECT %s FROM %s
+		WHERE status = ? AND next_attempt_at <= ? AND subscription_id IN (SELECT id FROM %s WHERE active)
+		ORDER BY next_attempt_at, id LIMIT ?`,
+		webhookDeliveryColumns, r.DeliveryTable, r.SubscriptionTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, string(domain.WebhookDeliveryPending), formatTime(&at), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	ids := []int64{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		delivery.NextAttemptAt = until
+		deliveries = append(deliveries, *delivery)
+		ids = append(ids, delivery.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	encodedIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding ids: %w", err)
+	}
+	claimQuery := fmt.Sprintf("UPDATE %s SET next_attempt_at = ? WHERE id IN (SELECT value FROM json_each(?))", r.DeliveryTable)
+	if _, err := r.Conn.ExecContext(ctx, claimQuery, formatTime(&until), string(encodedIDs)); err != nil {
+		return nil, fmt.Errorf("error claiming webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *SQLiteWebhookDeliveryTable) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	updateQuery := fmt.Sprintf(
+		"UPDATE %s SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, delivered_at = ? WHERE id = ?",
+		r.DeliveryTable,
+	)
+
+	result, err := r.Conn.ExecContext(ctx, updateQuery,
+		string(delivery.Status),
+		delivery.Attempts,
+		formatTime(&delivery.NextAttemptAt),
+		delivery.LastError,
+		formatTime(delivery.DeliveredAt),
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("webhook delivery not found: %d", delivery.ID)
+	}
+
+	insertQuery := fmt.Sprintf(
+		"INSERT INTO %s (delivery_id, attempt, status_code, error, attempted_at) VALUES (?, ?, ?, ?, ?)",
+		r.AttemptTable,
+	)
+
+	_, err = r.Conn.ExecContext(ctx, insertQuery,
+		delivery.ID,
+		attempt.Attempt,
+		attempt.StatusCode,
+		attempt.Error,
+		formatTime(&attempt.AttemptedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("error recording webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteWebhookDeliveryTable) GetBySubscription(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDeliveryLog, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE subscription_id = ? ORDER BY id DESC LIMIT ?",
+		webhookDeliveryColumns, r.DeliveryTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []domain.WebhookDeliveryLog{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook delivery: %w", err)
+		}
+		logs = append(logs, domain.WebhookDeliveryLog{Delivery: *delivery, Attempts: []domain.WebhookAttempt{}})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	rows.Close()
+
+	for i := range logs {
+		if logs[i].Attempts, err = r.getAttempts(ctx, logs[i].Delivery.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return logs, nil
+}
+
+func (r *SQLiteWebhookDeliveryTable) getAttempts(ctx context.Context, deliveryID int64) ([]domain.WebhookAttempt, error) {
+	selectQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE delivery_id = ? ORDER BY attempt",
+		webhookAttemptColumns, r.AttemptTable,
+	)
+
+	rows, err := r.Conn.QueryContext(ctx, selectQuery, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []domain.WebhookAttempt{}
+	for rows.Next() {
+		var attempt domain.WebhookAttempt
+		var attemptedAt string
+		err := rows.Scan(&attempt.DeliveryID, &attempt.Attempt, &attempt.StatusCode, &attempt.Error, &attemptedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook attempt: %w", err)
+		}
+		if attempt.AttemptedAt, err = time.Parse(time.RFC3339Nano, attemptedAt); err != nil {
+			return nil, fmt.Errorf("error decoding time of webhook attempt %d of delivery %d: %w", attempt.Attempt, deliveryID, err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var eventType, payload, status, nextAttemptAt, createdAt string
+	var deliveredAt sql.NullString
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&eventType,
+		&payload,
+		&status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&delivery.LastError,
+		&createdAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.EventType = domain.EventType(eventType)
+	delivery.Payload = json.RawMessage(payload)
+	delivery.Status = domain.WebhookDeliveryStatus(status)
+	if delivery.NextAttemptAt, err = time.Parse(time.RFC3339Nano, nextAttemptAt); err != nil {
+		return nil, fmt.Errorf("error decoding next attempt time of webhook delivery %d: %w", delivery.ID, err)
+	}
+	if delivery.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("error decoding creation time of webhook delivery %d: %w", delivery.ID, err)
+	}
+	if delivery.DeliveredAt, err = parseTime(deliveredAt); err != nil {
+		return nil, fmt.Errorf("error decoding delivery time of webhook delivery %d: %w", delivery.ID, err)
+	}
+
+	return &delivery, nil
+}
@@ -0,0 +1,60 @@
+// Package webhook delivers event payloads to webhook subscribers over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// Headers of every webhook request, receivers check SignatureHeader
+// against the HMAC-SHA256 of the body keyed with their secret.
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// maxResponseSize bounds how much of a response body is read before the
+// connection is reused, the body itself is ignored.
+const maxResponseSize = 64 << 10
+
+type HTTPSender struct {
+	Client *http.Client
+}
+
+// NewHTTPSender gives up on a receiver after timeout. Redirects are not
+// followed, so the payload never goes anywhere but the subscribed URL.
+func NewHTTPSender(timeout time.Duration) service.WebhookSender {
+	return &HTTPSender{Client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, request service.WebhookRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(request.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(request.DeliveryID, 10))
+	req.Header.Set(SignatureHeader, "sha256="+request.Signature)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	return resp.StatusCode, nil
+}
@@ -2,11 +2,15 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"log"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/raccoon00/avito-pr/internal/adapter/http"
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
 	"github.com/raccoon00/avito-pr/internal/adapter/postgres"
+	"github.com/raccoon00/avito-pr/internal/adapter/sqlite"
+	"github.com/raccoon00/avito-pr/internal/adapter/webhook"
 	"github.com/raccoon00/avito-pr/internal/config"
 	"github.com/raccoon00/avito-pr/internal/service"
 )
@@ -15,21 +19,93 @@ func Run() {
 	cfg := config.Load()
 	ctx_root := context.Background()
 
-	conn, err := connectToPostgres(ctx_root, cfg)
-	if err != nil {
-		log.Fatalf("Could not connect to database %v", err)
+	var srv *service.Service
+	switch cfg.DBProvider {
+	case config.DBProviderPostgres:
+		conn, err := connectToPostgres(ctx_root, cfg)
+		if err != nil {
+			log.Fatalf("Could not connect to database %v", err)
+		}
+		defer conn.Close()
+
+		if cfg.DBAutoMigrate {
+			if err := autoMigrate(ctx_root, conn); err != nil {
+				log.Fatalf("Could not migrate database %v", err)
+			}
+		}
+
+		srv = newPostgresService(conn)
+	case config.DBProviderMemory:
+		log.Println("Using in-memory storage, data will be lost on restart")
+		srv = newMemoryService()
+	case config.DBProviderSQLite:
+		db, err := connectToSQLite(ctx_root, cfg)
+		if err != nil {
+			log.Fatalf("Could not open SQLite database %v", err)
+		}
+		defer db.Close()
+
+		srv = newSQLiteService(db)
+	default:
+		log.Fatalf("Unknown database provider %q", cfg.DBProvider)
 	}
-	defer conn.Close()
 
-	team_repo := postgres.NewTeamRepo(conn, "teams", "users")
-	user_repo := postgres.NewUserRepo(conn, "users")
-	pr_repo := postgres.NewPullRequestRepo(conn, "pr_requests")
-	srv := service.CreateService(team_repo, user_repo, pr_repo)
+	srv.WebhookSender = webhook.NewHTTPSender(cfg.WebhookTimeout)
+	srv.WebhookRetry.BaseDelay = cfg.WebhookRetryBaseDelay
+	srv.WebhookRetry.MaxAttempts = cfg.WebhookMaxAttempts
+
+	if cfg.UnavailabilityCheckInterval > 0 {
+		go runUnavailabilityHandOver(ctx_root, srv, cfg.UnavailabilityCheckInterval)
+	}
+	if cfg.WebhookDeliveryInterval > 0 {
+		go runWebhookDelivery(ctx_root, srv, cfg.WebhookDeliveryInterval)
+	}
 
 	http.Run(srv)
 }
 
+func newPostgresService(conn *pgxpool.Pool) *service.Service {
+	tables := postgres.DefaultTables()
+	repos := postgres.NewRepositories(conn, tables)
+	stats_repo := postgres.NewStatsRepo(conn, tables.Users, tables.PullRequests)
+	tx_manager := postgres.NewTxManager(conn, tables)
+	return service.CreateService(repos, stats_repo, tx_manager)
+}
+
+func newMemoryService() *service.Service {
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	stats_repo := memory.NewStatsRepo(store)
+	tx_manager := memory.NewTxManager(store)
+	return service.CreateService(repos, stats_repo, tx_manager)
+}
+
+func newSQLiteService(db *sql.DB) *service.Service {
+	tables := sqlite.DefaultTables()
+	repos := sqlite.NewRepositories(db, tables)
+	stats_repo := sqlite.NewStatsRepo(db, tables.Users, tables.PullRequests)
+	tx_manager := sqlite.NewTxManager(db, tables)
+	return service.CreateService(repos, stats_repo, tx_manager)
+}
+
 func connectToPostgres(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 	conn, err := pgxpool.New(ctx, cfg.GetDBConnectionString())
 	return conn, err
 }
+
+// connectToSQLite opens the database file and brings its schema up to date,
+// there is no separate migrate step for SQLite.
+func connectToSQLite(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	log.Printf("Opening SQLite database %s\n", cfg.SQLitePath)
+	db, err := sqlite.Open(cfg.SQLitePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlite.Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
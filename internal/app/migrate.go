@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/raccoon00/avito-pr/internal/adapter/postgres"
+	"github.com/raccoon00/avito-pr/internal/config"
+	"github.com/raccoon00/avito-pr/migrations"
+)
+
+const migrateUsage = "usage: restapi migrate up [N] | down [N] | status | version"
+
+// Migrate runs the migrate subcommand against the configured Postgres database.
+// up applies all pending migrations or the next N, down reverts the last one or the last N.
+func Migrate(args []string) {
+	cfg := config.Load()
+	ctx_root := context.Background()
+
+	if cfg.DBProvider != config.DBProviderPostgres {
+		log.Fatalf("Migrations are only managed for %s, the %s provider sets up its schema on start", config.DBProviderPostgres, cfg.DBProvider)
+	}
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	conn, err := connectToPostgres(ctx_root, cfg)
+	if err != nil {
+		log.Fatalf("Could not connect to database %v", err)
+	}
+	defer conn.Close()
+
+	migrator, err := newMigrator(conn)
+	if err != nil {
+		log.Fatalf("Could not load migrations %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		steps, err := parseSteps(args[1:], 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		applied, err := migrator.Up(ctx_root, steps)
+		for _, migration := range applied {
+			log.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+	case "down":
+		steps, err := parseSteps(args[1:], 1)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reverted, err := migrator.Down(ctx_root, steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed %v", err)
+		}
+		if len(reverted) == 0 {
+			log.Println("No applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx_root)
+		if err != nil {
+			log.Fatalf("Could not read migration status %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%-8s %d_%s\n", state, status.Version, status.Name)
+		}
+	case "version":
+		version, dirty, err := migrator.Version(ctx_root)
+		if errors.Is(err, postgres.ErrNoMigrationApplied) {
+			fmt.Println("none")
+			return
+		}
+		if err != nil {
+			log.Fatalf("Could not read schema version %v", err)
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+			return
+		}
+		fmt.Println(version)
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// autoMigrate brings the schema up to date before the service starts.
+func autoMigrate(ctx context.Context, conn *pgxpool.Pool) error {
+	migrator, err := newMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx, 0)
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s\n", migration.Version, migration.Name)
+	}
+	return err
+}
+
+func newMigrator(conn *pgxpool.Pool) (*postgres.PostgresMigrator, error) {
+	loaded, err := postgres.LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.NewMigrator(conn, loaded, postgres.DefaultMigrationsTable), nil
+}
+
+func parseSteps(args []string, defaultSteps int) (int, error) {
+	if len(args) == 0 {
+		return defaultSteps, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 || len(args) > 1 {
+		return 0, errors.New(migrateUsage)
+	}
+	return steps, nil
+}
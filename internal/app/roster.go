@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/raccoon00/avito-pr/internal/adapter/http"
+	"github.com/raccoon00/avito-pr/internal/config"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+const rosterUsage = "usage: restapi roster sync [-dry-run] FILE"
+
+// Roster runs the roster subcommand against the configured database.
+// sync reads a JSON or YAML roster and applies it like POST /team/sync,
+// the resulting plan is printed as JSON.
+func Roster(args []string) {
+	// stdout is kept for the plan so it can be piped
+	log.SetOutput(os.Stderr)
+	cfg := config.Load()
+	ctx_root := context.Background()
+
+	if len(args) == 0 || args[0] != "sync" {
+		log.Fatal(rosterUsage)
+	}
+
+	flags := flag.NewFlagSet("roster sync", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the plan without changing anything")
+	flags.Parse(args[1:])
+	if flags.NArg() != 1 {
+		log.Fatal(rosterUsage)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Could not read roster %v", err)
+	}
+
+	// JSON is valid YAML, one decoder reads both formats
+	var doc http.RosterDocument
+	if err := binding.YAML.BindBody(data, &doc); err != nil {
+		log.Fatalf("Could not parse roster %v", err)
+	}
+
+	var srv *service.Service
+	switch cfg.DBProvider {
+	case config.DBProviderPostgres:
+		conn, err := connectToPostgres(ctx_root, cfg)
+		if err != nil {
+			log.Fatalf("Could not connect to database %v", err)
+		}
+		defer conn.Close()
+
+		srv = newPostgresService(conn)
+	case config.DBProviderSQLite:
+		db, err := connectToSQLite(ctx_root, cfg)
+		if err != nil {
+			log.Fatalf("Could not open SQLite database %v", err)
+		}
+		defer db.Close()
+
+		srv = newSQLiteService(db)
+	default:
+		log.Fatalf("Roster sync needs a persistent database, the %s provider is not supported", cfg.DBProvider)
+	}
+
+	result, err := srv.SyncRoster(ctx_root, doc.Roster(), *dryRun)
+	if err != nil {
+		log.Fatalf("Roster sync failed %v", err)
+	}
+
+	output, err := json.MarshalIndent(http.NewRosterSyncResponse(result), "", "  ")
+	if err != nil {
+		log.Fatalf("Could not encode sync result %v", err)
+	}
+	os.Stdout.Write(append(output, '\n'))
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// runUnavailabilityHandOver hands over OPEN reviews of users whose
+// unavailability has started, every interval until ctx is done.
+func runUnavailabilityHandOver(ctx context.Context, srv *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		replacements, err := srv.HandOverUnavailableReviews(ctx)
+		if err != nil {
+			log.Printf("Could not hand over reviews of unavailable users %v", err)
+		}
+		if len(replacements) > 0 {
+			log.Printf("Handed over %d reviews of unavailable users\n", len(replacements))
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// runWebhookDelivery sends the webhook deliveries that are due,
+// every interval until ctx is done.
+func runWebhookDelivery(ctx context.Context, srv *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		attempted, err := srv.DeliverWebhooks(ctx)
+		if err != nil {
+			log.Printf("Could not deliver webhooks %v", err)
+		}
+		if attempted > 0 {
+			log.Printf("Attempted %d webhook deliveries\n", attempted)
+		}
+	}
+}
@@ -4,6 +4,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
+)
+
+// Supported values of DB_PROVIDER
+const (
+	DBProviderPostgres = "postgres"
+	DBProviderMemory   = "memory"
+	DBProviderSQLite   = "sqlite"
 )
 
 type Config struct {
@@ -12,7 +21,27 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// DBProvider selects the storage backend, data of the memory one
+	// is lost when the service stops
 	DBProvider string
+	// SQLitePath is the database file of the sqlite provider
+	SQLitePath string
+	// DBAutoMigrate applies pending Postgres migrations on start,
+	// so the service does not need the migrate container
+	DBAutoMigrate bool
+	// UnavailabilityCheckInterval is how often reviews of users whose
+	// unavailability has started are handed over, zero turns the job off
+	UnavailabilityCheckInterval time.Duration
+	// WebhookDeliveryInterval is how often due webhook deliveries are sent,
+	// zero turns the job off and deliveries stay queued
+	WebhookDeliveryInterval time.Duration
+	// WebhookTimeout bounds a single delivery attempt
+	WebhookTimeout time.Duration
+	// WebhookRetryBaseDelay is the wait before the first retry, it doubles
+	// with every further one
+	WebhookRetryBaseDelay time.Duration
+	// WebhookMaxAttempts is the number of attempts before a delivery fails for good
+	WebhookMaxAttempts int
 }
 
 func Load() *Config {
@@ -20,6 +49,17 @@ func Load() *Config {
 		DBUser:     getEnv("DB_USER", "postgres"),
 		DBPassword: getEnv("DB_PASSWORD", "example"),
 		DBName:     getEnv("DB_NAME", "prs"),
+		DBProvider: getEnv("DB_PROVIDER", DBProviderPostgres),
+		SQLitePath: getEnv("SQLITE_PATH", "prs.db"),
+
+		DBAutoMigrate: getEnv("DB_AUTO_MIGRATE", "false") == "true",
+
+		UnavailabilityCheckInterval: getDurationEnv("UNAVAILABILITY_CHECK_INTERVAL", time.Minute),
+
+		WebhookDeliveryInterval: getDurationEnv("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+		WebhookTimeout:          getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookRetryBaseDelay:   getDurationEnv("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+		WebhookMaxAttempts:      getPositiveIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
 	}
 }
 
@@ -37,3 +77,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Fatalf("Invalid %s %q, expected a non-negative duration like 30s or 5m", key, value)
+	}
+	return duration
+}
+
+func getPositiveIntEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		log.Fatalf("Invalid %s %q, expected a positive number", key, value)
+	}
+	return number
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// AssignmentCause tells what made the service pick reviewers.
+type AssignmentCause string
+
+const (
+	// AssignmentCauseInitial is the first assignment of an OPEN pull request:
+	// on creation, when a draft is ready or a closed draft is reopened
+	AssignmentCauseInitial AssignmentCause = "INITIAL"
+	// AssignmentCauseReassignment replaces a reviewer by request
+	AssignmentCauseReassignment AssignmentCause = "REASSIGNMENT"
+	// AssignmentCauseTopUp adds reviewers up to the team's reviewer count
+	AssignmentCauseTopUp AssignmentCause = "TOP_UP"
+	// AssignmentCauseDeactivation hands over the reviews of a user who was
+	// deactivated or left the team
+	AssignmentCauseDeactivation AssignmentCause = "DEACTIVATION"
+	// AssignmentCauseUnavailability hands over the reviews of an unavailable user
+	AssignmentCauseUnavailability AssignmentCause = "UNAVAILABILITY"
+)
+
+type ExclusionReason string
+
+const (
+	ExclusionReasonAuthor          ExclusionReason = "AUTHOR"
+	ExclusionReasonAlreadyAssigned ExclusionReason = "ALREADY_ASSIGNED"
+	// ExclusionReasonReplaced is a reviewer whose reviews are being handed over
+	ExclusionReasonReplaced    ExclusionReason = "REPLACED"
+	ExclusionReasonInactive    ExclusionReason = "INACTIVE"
+	ExclusionReasonUnavailable ExclusionReason = "UNAVAILABLE"
+	// ExclusionReasonOverCapacity is a user who reached their MaxOpenReviews
+	ExclusionReasonOverCapacity ExclusionReason = "OVER_CAPACITY"
+)
+
+type ExcludedCandidate struct {
+	UserID string
+	Reason ExclusionReason
+}
+
+// AssignmentRecord explains how reviewers were picked from one pool: the
+// owners of the changed paths, the team or one of its fallback teams. A
+// decision that looks at several pools leaves a record for each of them.
+//
+// Candidates are the users the strategy chose from, Chosen the ones it
+// picked. ReplacedUserID is the reviewer whose slot is filled, it is empty
+// for INITIAL and TOP_UP.
+type AssignmentRecord struct {
+	PullRequestID  string
+	Cause          AssignmentCause
+	ReplacedUserID string
+	Pool           ReviewerReason
+	Team           string
+	Strategy       ReviewerStrategy
+	Candidates     []string
+	Excluded       []ExcludedCandidate
+	Chosen         []string
+	DecidedAt      time.Time
+}
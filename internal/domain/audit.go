@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction names a change made through the API, there is one action
+// for every mutating endpoint.
+type AuditAction string
+
+const (
+	AuditActionTeamAdd                 AuditAction = "TEAM_ADD"
+	AuditActionTeamSetReviewerStrategy AuditAction = "TEAM_SET_REVIEWER_STRATEGY"
+	AuditActionTeamSetReviewerCount    AuditAction = "TEAM_SET_REVIEWER_COUNT"
+	AuditActionTeamSetMergePolicy      AuditAction = "TEAM_SET_MERGE_POLICY"
+	AuditActionTeamSetFallbackTeams    AuditAction = "TEAM_SET_FALLBACK_TEAMS"
+	AuditActionTeamSetOwnershipRules   AuditAction = "TEAM_SET_OWNERSHIP_RULES"
+	AuditActionTeamFillReviewers       AuditAction = "TEAM_FILL_REVIEWERS"
+	AuditActionTeamDeactivateMembers   AuditAction = "TEAM_DEACTIVATE_MEMBERS"
+	AuditActionTeamAddMember           AuditAction = "TEAM_ADD_MEMBER"
+	AuditActionTeamRemoveMember        AuditAction = "TEAM_REMOVE_MEMBER"
+	AuditActionTeamMoveMember          AuditAction = "TEAM_MOVE_MEMBER"
+	AuditActionTeamRename              AuditAction = "TEAM_RENAME"
+	AuditActionTeamDelete              AuditAction = "TEAM_DELETE"
+	AuditActionTeamSync                AuditAction = "TEAM_SYNC"
+
+	AuditActionUserSetIsActive          AuditAction = "USER_SET_IS_ACTIVE"
+	AuditActionUserSetReviewLimit       AuditAction = "USER_SET_REVIEW_LIMIT"
+	AuditActionUserAddUnavailability    AuditAction = "USER_ADD_UNAVAILABILITY"
+	AuditActionUserDeleteUnavailability AuditAction = "USER_DELETE_UNAVAILABILITY"
+
+	AuditActionPullRequestCreate         AuditAction = "PULL_REQUEST_CREATE"
+	AuditActionPullRequestReassign       AuditAction = "PULL_REQUEST_REASSIGN"
+	AuditActionPullRequestFillReviewers  AuditAction = "PULL_REQUEST_FILL_REVIEWERS"
+	AuditActionPullRequestAddReviewer    AuditAction = "PULL_REQUEST_ADD_REVIEWER"
+	AuditActionPullRequestRemoveReviewer AuditAction = "PULL_REQUEST_REMOVE_REVIEWER"
+	AuditActionPullRequestMerge          AuditAction = "PULL_REQUEST_MERGE"
+	AuditActionPullRequestClose          AuditAction = "PULL_REQUEST_CLOSE"
+	AuditActionPullRequestReopen         AuditAction = "PULL_REQUEST_REOPEN"
+	AuditActionPullRequestReady          AuditAction = "PULL_REQUEST_READY"
+	AuditActionPullRequestDraft          AuditAction = "PULL_REQUEST_DRAFT"
+	AuditActionPullRequestReview         AuditAction = "PULL_REQUEST_REVIEW"
+
+	AuditActionWebhookCreate AuditAction = "WEBHOOK_CREATE"
+	AuditActionWebhookUpdate AuditAction = "WEBHOOK_UPDATE"
+	AuditActionWebhookDelete AuditAction = "WEBHOOK_DELETE"
+)
+
+// AuditTargetType is the kind of entity an audited action changes.
+type AuditTargetType string
+
+const (
+	AuditTargetTeam        AuditTargetType = "TEAM"
+	AuditTargetUser        AuditTargetType = "USER"
+	AuditTargetPullRequest AuditTargetType = "PULL_REQUEST"
+	// AuditTargetUnavailability is the list of unavailability periods
+	// of a user, the target id is the user id
+	AuditTargetUnavailability AuditTargetType = "UNAVAILABILITY"
+	// AuditTargetRoster is the list of all teams, it has no target id
+	AuditTargetRoster AuditTargetType = "ROSTER"
+	// AuditTargetWebhook is a webhook subscription, its secret is not logged
+	AuditTargetWebhook AuditTargetType = "WEBHOOK"
+)
+
+// AuditEntry records one change made through the API. Before and After are
+// JSON snapshots of the target, nil when the target did not exist.
+type AuditEntry struct {
+	ID         int64
+	Actor      string
+	Action     AuditAction
+	TargetType AuditTargetType
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// AuditFilter selects audit entries, empty fields match any entry.
+// Entries are listed by ID starting after AfterID, at most Limit of them.
+type AuditFilter struct {
+	Actor      string
+	Action     AuditAction
+	TargetType AuditTargetType
+	TargetID   string
+	RequestID  string
+	// From and To bound CreatedAt, From is inclusive and To is exclusive
+	From    *time.Time
+	To      *time.Time
+	AfterID int64
+	Limit   int
+}
@@ -9,3 +9,134 @@ type TeamExistsError struct {
 func (e *TeamExistsError) Error() string {
 	return fmt.Sprintf("The team with a name %s already exists", e.TeamName)
 }
+
+type UnknownReviewerStrategyError struct {
+	Strategy ReviewerStrategy
+}
+
+func (e *UnknownReviewerStrategyError) Error() string {
+	return fmt.Sprintf("unknown reviewer strategy %s", e.Strategy)
+}
+
+type InvalidReviewLimitError struct {
+	Limit int
+}
+
+func (e *InvalidReviewLimitError) Error() string {
+	return fmt.Sprintf("review limit must not be negative, got %d", e.Limit)
+}
+
+type InvalidReviewerCountError struct {
+	Count  int
+	Reason string
+}
+
+func (e *InvalidReviewerCountError) Error() string {
+	return fmt.Sprintf("invalid reviewer count %d: %s", e.Count, e.Reason)
+}
+
+type InvalidMergePolicyError struct {
+	Reason string
+}
+
+func (e *InvalidMergePolicyError) Error() string {
+	return fmt.Sprintf("invalid merge policy: %s", e.Reason)
+}
+
+type InvalidFallbackTeamsError struct {
+	TeamName string
+	Reason   string
+}
+
+func (e *InvalidFallbackTeamsError) Error() string {
+	return fmt.Sprintf("invalid fallback teams of %s: %s", e.TeamName, e.Reason)
+}
+
+type UserExistsError struct {
+	UserID string
+}
+
+func (e *UserExistsError) Error() string {
+	return fmt.Sprintf("user %s already exists", e.UserID)
+}
+
+type TeamNotEmptyError struct {
+	TeamName string
+}
+
+func (e *TeamNotEmptyError) Error() string {
+	return fmt.Sprintf("team %s still has members, move or remove them first", e.TeamName)
+}
+
+type UserHasPullRequestsError struct {
+	UserID string
+}
+
+func (e *UserHasPullRequestsError) Error() string {
+	return fmt.Sprintf("user %s authored pull requests and cannot be removed, deactivate them instead", e.UserID)
+}
+
+type InvalidRosterError struct {
+	Reason string
+}
+
+func (e *InvalidRosterError) Error() string {
+	return fmt.Sprintf("invalid roster: %s", e.Reason)
+}
+
+type InvalidUnavailabilityError struct {
+	UserID string
+	Reason string
+}
+
+func (e *InvalidUnavailabilityError) Error() string {
+	return fmt.Sprintf("invalid unavailability of %s: %s", e.UserID, e.Reason)
+}
+
+type UnavailabilityNotFoundError struct {
+	ID int64
+}
+
+func (e *UnavailabilityNotFoundError) Error() string {
+	return fmt.Sprintf("unavailability %d not found", e.ID)
+}
+
+type UnavailabilityHandedOverError struct {
+	ID int64
+}
+
+func (e *UnavailabilityHandedOverError) Error() string {
+	return fmt.Sprintf("unavailability %d is already handed over", e.ID)
+}
+
+type InvalidOwnershipRulesError struct {
+	Reason string
+}
+
+func (e *InvalidOwnershipRulesError) Error() string {
+	return fmt.Sprintf("invalid ownership rules: %s", e.Reason)
+}
+
+type InvalidAuditFilterError struct {
+	Reason string
+}
+
+func (e *InvalidAuditFilterError) Error() string {
+	return fmt.Sprintf("invalid audit filter: %s", e.Reason)
+}
+
+type WebhookNotFoundError struct {
+	ID int64
+}
+
+func (e *WebhookNotFoundError) Error() string {
+	return fmt.Sprintf("webhook subscription %d not found", e.ID)
+}
+
+type InvalidWebhookError struct {
+	Reason string
+}
+
+func (e *InvalidWebhookError) Error() string {
+	return fmt.Sprintf("invalid webhook subscription: %s", e.Reason)
+}
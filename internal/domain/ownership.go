@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OwnershipRule maps a CODEOWNERS-style pattern to the users owning the
+// matching paths. Like in CODEOWNERS the last matching rule of a team wins,
+// so a later rule without owners leaves its paths unowned.
+//
+// Patterns follow gitignore rules: a pattern without a slash matches at any
+// depth, a leading or inner slash anchors it at the repository root, a
+// trailing slash matches directories only, "*" does not cross slashes and
+// "**" matches any number of directories. A pattern matching a directory
+// covers everything inside it.
+type OwnershipRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// Matches reports whether filePath falls under the rule's pattern.
+func (r OwnershipRule) Matches(filePath string) bool {
+	patternSegments, anchored, dirOnly := splitPattern(r.Pattern)
+	if !anchored {
+		patternSegments = append([]string{"**"}, patternSegments...)
+	}
+	return matchSegments(patternSegments, strings.Split(strings.Trim(filePath, "/"), "/"), dirOnly)
+}
+
+// ValidateOwnershipPattern checks that pattern is a non-empty glob.
+func ValidateOwnershipPattern(pattern string) error {
+	segments, _, _ := splitPattern(pattern)
+	if len(segments) == 1 && segments[0] == "" {
+		return fmt.Errorf("pattern %q is empty", pattern)
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("pattern %q has an empty path segment", pattern)
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("pattern %q is malformed", pattern)
+		}
+	}
+	return nil
+}
+
+func splitPattern(pattern string) (segments []string, anchored bool, dirOnly bool) {
+	dirOnly = strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	anchored = strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+	return strings.Split(trimmed, "/"), anchored, dirOnly
+}
+
+// matchSegments reports whether the pattern matches the whole path or one
+// of its parent directories.
+func matchSegments(pattern, segments []string, dirOnly bool) bool {
+	if len(pattern) == 0 {
+		// Segments left over mean a parent directory has matched
+		return len(segments) > 0 || !dirOnly
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:], dirOnly) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:], dirOnly)
+}
+
+// PathOwner lists the changed paths a user owns.
+type PathOwner struct {
+	UserID string
+	Paths  []string
+}
+
+// OwnersOf returns the owners of paths by the last matching rule, in the
+// order they first own a path.
+func OwnersOf(rules []OwnershipRule, paths []string) []PathOwner {
+	owners := []PathOwner{}
+	index := map[string]int{}
+	for _, filePath := range paths {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !rules[i].Matches(filePath) {
+				continue
+			}
+			for _, userID := range rules[i].Owners {
+				if _, seen := index[userID]; !seen {
+					index[userID] = len(owners)
+					owners = append(owners, PathOwner{UserID: userID})
+				}
+				owner := &owners[index[userID]]
+				owner.Paths = append(owner.Paths, filePath)
+			}
+			break
+		}
+	}
+	return owners
+}
+
+// ParseCodeowners reads rules in the CODEOWNERS format: one pattern per line
+// followed by its owners separated by spaces, "#" starts a comment. Owners are
+// user ids, a leading "@" is dropped.
+func ParseCodeowners(text string) ([]OwnershipRule, error) {
+	rules := []OwnershipRule{}
+	for i, line := range strings.Split(text, "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := ValidateOwnershipPattern(fields[0]); err != nil {
+			return nil, &InvalidOwnershipRulesError{Reason: fmt.Sprintf("line %d: %v", i+1, err)}
+		}
+		rule := OwnershipRule{Pattern: fields[0], Owners: []string{}}
+		for _, owner := range fields[1:] {
+			rule.Owners = append(rule.Owners, strings.TrimPrefix(owner, "@"))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type ReviewerReason string
+
+const (
+	// ReviewerReasonOwner is an owner of some of the changed paths
+	ReviewerReasonOwner ReviewerReason = "OWNER"
+	// ReviewerReasonTeam is a member of the team reviewers are picked from
+	ReviewerReasonTeam ReviewerReason = "TEAM_MEMBER"
+	// ReviewerReasonFallbackTeam is a member of one of its fallback teams
+	ReviewerReasonFallbackTeam ReviewerReason = "FALLBACK_TEAM"
+)
+
+// ReviewerChoice explains why a reviewer was picked. Team is the team of the
+// reviewer, Paths the changed paths an OWNER owns.
+type ReviewerChoice struct {
+	UserID string
+	Reason ReviewerReason
+	Team   string
+	Paths  []string
+}
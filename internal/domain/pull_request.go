@@ -7,6 +7,10 @@ type PullRequestStatus string
 const (
 	PullRequestStatusOpen   PullRequestStatus = "OPEN"
 	PullRequestStatusMerged PullRequestStatus = "MERGED"
+	// CLOSED is a declined pull request, it can be reopened
+	PullRequestStatusClosed PullRequestStatus = "CLOSED"
+	// DRAFT is not ready for review, reviewers are assigned once it is
+	PullRequestStatusDraft PullRequestStatus = "DRAFT"
 )
 
 type PullRequest struct {
@@ -17,4 +21,33 @@ type PullRequest struct {
 	AssignedReviewers []string
 	CreatedAt         *time.Time
 	MergedAt          *time.Time
+	// Version is bumped on every update and is used to detect concurrent changes
+	Version int
+	// Reviews are the verdicts submitted so far, they are stored apart
+	// from the pull request and loaded by the service when needed
+	Reviews []Review
+	// ExternalReviewers are the assigned reviewers from outside the author's
+	// team, picked from its fallback teams. Filled by the service like Reviews
+	ExternalReviewers []string
+	// ChangedPaths are the files touched by the pull request, their owners
+	// are preferred as reviewers
+	ChangedPaths []string
+	// ReviewerChoices explain the reviewers picked by the current call,
+	// they are not stored
+	ReviewerChoices []ReviewerChoice
+}
+
+// ReviewerReplacement describes one reviewer slot change on a pull request.
+// An empty NewUserID means the slot was dropped because nobody was available.
+type ReviewerReplacement struct {
+	PullRequestID string
+	OldUserID     string
+	NewUserID     string
+}
+
+// ReviewerAddition lists the reviewers added to a pull request that had
+// fewer than the ReviewerCount of the author's team.
+type ReviewerAddition struct {
+	PullRequestID string
+	AddedUserIDs  []string
 }
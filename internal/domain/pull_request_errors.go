@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type PullRequestExistsError struct {
 	PullRequestID string
@@ -34,12 +37,15 @@ func (e *NoReviewersAvailableError) Error() string {
 	return fmt.Sprintf("No reviewers available in team %s", e.TeamName)
 }
 
-type PRMergedError struct {
+// PRNotModifiableError is returned when the status of a pull request
+// does not allow the requested change, e.g. reassigning on a merged PR.
+type PRNotModifiableError struct {
 	PullRequestID string
+	Status        PullRequestStatus
 }
 
-func (e *PRMergedError) Error() string {
-	return fmt.Sprintf("cannot reassign on merged PR %s", e.PullRequestID)
+func (e *PRNotModifiableError) Error() string {
+	return fmt.Sprintf("cannot modify PR %s in status %s", e.PullRequestID, e.Status)
 }
 
 type ReviewerNotAssignedError struct {
@@ -51,6 +57,38 @@ func (e *ReviewerNotAssignedError) Error() string {
 	return fmt.Sprintf("reviewer %s is not assigned to PR %s", e.UserID, e.PullRequestID)
 }
 
+type ReviewerAlreadyAssignedError struct {
+	PullRequestID string
+	UserID        string
+}
+
+func (e *ReviewerAlreadyAssignedError) Error() string {
+	return fmt.Sprintf("reviewer %s is already assigned to PR %s", e.UserID, e.PullRequestID)
+}
+
+// InvalidReviewerError is returned when a user can't review a pull request
+// at all, e.g. because they are its author or inactive.
+type InvalidReviewerError struct {
+	UserID string
+	Reason string
+}
+
+func (e *InvalidReviewerError) Error() string {
+	return fmt.Sprintf("user %s can't be a reviewer: %s", e.UserID, e.Reason)
+}
+
+// ReviewerLimitError is returned when adding a reviewer would exceed the
+// reviewer count of the team or the review limit of the user.
+type ReviewerLimitError struct {
+	PullRequestID string
+	UserID        string
+	Reason        string
+}
+
+func (e *ReviewerLimitError) Error() string {
+	return fmt.Sprintf("cannot add reviewer %s to PR %s: %s", e.UserID, e.PullRequestID, e.Reason)
+}
+
 type UserNotFoundError struct {
 	UserID string
 }
@@ -58,3 +96,40 @@ type UserNotFoundError struct {
 func (e *UserNotFoundError) Error() string {
 	return fmt.Sprintf("user %s not found", e.UserID)
 }
+
+type UserNotInTeamError struct {
+	UserID   string
+	TeamName string
+}
+
+func (e *UserNotInTeamError) Error() string {
+	return fmt.Sprintf("user %s is not a member of team %s", e.UserID, e.TeamName)
+}
+
+type PullRequestConflictError struct {
+	PullRequestID string
+	Version       int
+}
+
+func (e *PullRequestConflictError) Error() string {
+	return fmt.Sprintf("pull request %s was modified concurrently, version %d is outdated", e.PullRequestID, e.Version)
+}
+
+type UnknownReviewVerdictError struct {
+	Verdict ReviewVerdict
+}
+
+func (e *UnknownReviewVerdictError) Error() string {
+	return fmt.Sprintf("unknown review verdict %s", e.Verdict)
+}
+
+// MergePolicyViolationError is returned when a pull request does not meet
+// the merge policy of the author's team, Unmet lists the failed conditions.
+type MergePolicyViolationError struct {
+	PullRequestID string
+	Unmet         []string
+}
+
+func (e *MergePolicyViolationError) Error() string {
+	return fmt.Sprintf("PR %s does not meet the merge policy: %s", e.PullRequestID, strings.Join(e.Unmet, "; "))
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type ReviewVerdict string
+
+const (
+	ReviewVerdictApproved         ReviewVerdict = "APPROVED"
+	ReviewVerdictChangesRequested ReviewVerdict = "CHANGES_REQUESTED"
+	ReviewVerdictCommented        ReviewVerdict = "COMMENTED"
+)
+
+// IsValid reports whether v is one of the known verdicts.
+func (v ReviewVerdict) IsValid() bool {
+	switch v {
+	case ReviewVerdictApproved, ReviewVerdictChangesRequested, ReviewVerdictCommented:
+		return true
+	}
+	return false
+}
+
+// IsDecision reports whether the reviewer is done with the pull request,
+// a comment alone still leaves the review pending.
+func (v ReviewVerdict) IsDecision() bool {
+	return v == ReviewVerdictApproved || v == ReviewVerdictChangesRequested
+}
+
+// Review is the latest verdict of a reviewer on a pull request.
+// CreatedAt is the first submission, UpdatedAt the latest one.
+type Review struct {
+	PullRequestID string
+	ReviewerID    string
+	Verdict       ReviewVerdict
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type ReviewerChangeAction string
+
+const (
+	ReviewerChangeAdded   ReviewerChangeAction = "ADDED"
+	ReviewerChangeRemoved ReviewerChangeAction = "REMOVED"
+)
+
+// ReviewerChange is one entry of the reviewer history of a pull request,
+// written when a reviewer is added or removed by hand.
+type ReviewerChange struct {
+	PullRequestID string
+	UserID        string
+	Action        ReviewerChangeAction
+	ChangedAt     time.Time
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Roster is a list of teams and their members kept outside of the
+// service, syncing it makes the listed teams match the document.
+type Roster struct {
+	Teams []Team
+}
+
+// RosterUserChange is a user listed in the roster whose name,
+// activity or team differs from the stored one.
+type RosterUserChange struct {
+	Before User
+	After  User
+}
+
+// RosterSyncPlan is the diff between the roster and the stored teams.
+// Members of the listed teams missing from the roster are deactivated
+// rather than deleted, they may have authored pull requests.
+type RosterSyncPlan struct {
+	CreatedTeams     []string
+	CreatedUsers     []User
+	UpdatedUsers     []RosterUserChange
+	DeactivatedUsers []User
+}
+
+// RosterSyncResult holds the plan and, once it is applied, the OPEN
+// reviews handed over from users that left their team or were deactivated.
+type RosterSyncResult struct {
+	Plan         RosterSyncPlan
+	Applied      bool
+	Replacements []ReviewerReplacement
+}
@@ -0,0 +1,26 @@
+package domain
+
+type UserAssignmentStats struct {
+	UserID   string
+	Username string
+	TeamName string
+	// Assigned counts pull requests in every status
+	Assigned int
+	Open     int
+	Merged   int
+	Draft    int
+	Closed   int
+}
+
+type PullRequestAssignmentStats struct {
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	Status          PullRequestStatus
+	ReviewersCount  int
+}
+
+type AssignmentStats struct {
+	Users        []UserAssignmentStats
+	PullRequests []PullRequestAssignmentStats
+}
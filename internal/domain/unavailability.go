@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Unavailability is a period when the user gets no new reviews.
+// EndsAt is exclusive. HandedOver is set once the OPEN reviews of the user
+// have been reassigned for this period.
+type Unavailability struct {
+	ID         int64
+	UserID     string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	Reason     string
+	HandedOver bool
+}
+
+// Covers reports whether at falls into the period.
+func (u Unavailability) Covers(at time.Time) bool {
+	return !at.Before(u.StartsAt) && at.Before(u.EndsAt)
+}
+
+// UnavailabilityResult describes a scheduled period. Replacements lists the
+// OPEN reviews handed over right away because the period has already started.
+type UnavailabilityResult struct {
+	Unavailability Unavailability
+	Replacements   []ReviewerReplacement
+}
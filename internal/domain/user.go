@@ -5,9 +5,79 @@ type User struct {
 	Name     string
 	Team     string
 	IsActive bool
+	// MaxOpenReviews caps the number of OPEN reviews the user can have
+	// under the LEAST_LOADED strategy, nil means no limit
+	MaxOpenReviews *int
+}
+
+type ReviewerStrategy string
+
+const (
+	ReviewerStrategyRandom      ReviewerStrategy = "RANDOM"
+	ReviewerStrategyRoundRobin  ReviewerStrategy = "ROUND_ROBIN"
+	ReviewerStrategyLeastLoaded ReviewerStrategy = "LEAST_LOADED"
+)
+
+// MergePolicy lists the conditions a pull request of the team has to meet
+// before it is merged. The zero value allows merging any OPEN pull request.
+type MergePolicy struct {
+	// MinApprovals is the number of assigned reviewers that have to approve
+	MinApprovals int
+	// BlockOnChangesRequested forbids merging while an assigned reviewer requests changes
+	BlockOnChangesRequested bool
+	// LeadIDs are the team members allowed to merge regardless of the policy
+	LeadIDs []string
+}
+
+const (
+	// DefaultReviewerCount is the number of reviewers of a team that did not choose one
+	DefaultReviewerCount = 2
+	// MaxReviewerCount bounds the number of reviewers a team can ask for
+	MaxReviewerCount = 10
+)
+
+type TeamSettings struct {
+	ReviewerStrategy ReviewerStrategy
+	// ReviewerCount is how many reviewers are assigned to pull requests of the team
+	ReviewerCount int
+	MergePolicy   MergePolicy
+	// FallbackTeams are asked in order for reviewers when the team itself
+	// can't fill ReviewerCount. A shared reviewer pool is a team that other
+	// teams list here.
+	FallbackTeams []string
+	// OwnershipRules map changed paths of the team's pull requests to
+	// the users preferred as their reviewers
+	OwnershipRules []OwnershipRule
 }
 
 type Team struct {
-	Name    string
-	Members []User
+	Name     string
+	Members  []User
+	Settings TeamSettings
+}
+
+type TeamDeactivationResult struct {
+	TeamName     string
+	Deactivated  []User
+	Replacements []ReviewerReplacement
+}
+
+// TeamMembershipResult describes a user joining or leaving a team.
+// Replacements lists OPEN reviews handed over to the teammates left behind.
+type TeamMembershipResult struct {
+	User         User
+	FromTeam     string
+	ToTeam       string
+	Replacements []ReviewerReplacement
+}
+
+// TeamAddResult is the outcome of adding a team. Members that did not
+// exist are created, existing users are taken over with the submitted
+// name, activity and team. Replacements are the OPEN reviews handed over
+// from the existing users to their old teammates.
+type TeamAddResult struct {
+	Team           Team
+	CreatedUserIDs []string
+	UpdatedUserIDs []string
+	Replacements   []ReviewerReplacement
 }
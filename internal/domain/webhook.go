@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// EventType names something that happened to a pull request or its reviewers.
+type EventType string
+
+const (
+	EventPullRequestCreated  EventType = "PULL_REQUEST_CREATED"
+	EventPullRequestMerged   EventType = "PULL_REQUEST_MERGED"
+	EventPullRequestClosed   EventType = "PULL_REQUEST_CLOSED"
+	EventPullRequestReopened EventType = "PULL_REQUEST_REOPENED"
+	// EventReviewerAssigned is produced for every reviewer added to a pull
+	// request, whether chosen by the service or added by hand
+	EventReviewerAssigned EventType = "REVIEWER_ASSIGNED"
+	// EventReviewerReassigned is produced when a reviewer is replaced by another one
+	EventReviewerReassigned EventType = "REVIEWER_REASSIGNED"
+	// EventReviewerRemoved is produced when a reviewer leaves a pull request
+	// and nobody takes the place
+	EventReviewerRemoved EventType = "REVIEWER_REMOVED"
+)
+
+var eventTypes = []EventType{
+	EventPullRequestCreated,
+	EventPullRequestMerged,
+	EventPullRequestClosed,
+	EventPullRequestReopened,
+	EventReviewerAssigned,
+	EventReviewerReassigned,
+	EventReviewerRemoved,
+}
+
+func (t EventType) IsValid() bool {
+	return slices.Contains(eventTypes, t)
+}
+
+// Event is produced by a change of a pull request. TeamName is the team of
+// the author. UserID is the reviewer of reviewer events, ReplacedUserID is
+// set for EventReviewerReassigned only.
+type Event struct {
+	Type           EventType
+	TeamName       string
+	PullRequest    PullRequest
+	UserID         string
+	ReplacedUserID string
+	OccurredAt     time.Time
+}
+
+// WebhookSubscription asks for events to be POSTed to URL. Empty Events
+// means all event types and an empty TeamName means all teams.
+// Payloads are signed with Secret.
+type WebhookSubscription struct {
+	ID        int64
+	URL       string
+	Secret    string
+	Events    []EventType
+	TeamName  string
+	Active    bool
+	CreatedAt time.Time
+}
+
+// Matches reports whether the subscription wants event.
+func (s WebhookSubscription) Matches(event Event) bool {
+	if !s.Active {
+		return false
+	}
+	if s.TeamName != "" && s.TeamName != event.TeamName {
+		return false
+	}
+	return len(s.Events) == 0 || slices.Contains(s.Events, event.Type)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are sent at NextAttemptAt
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// WebhookDeliveryFailed deliveries ran out of attempts
+	WebhookDeliveryFailed WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is one event to be sent to one subscription.
+// Payload is the JSON body of every attempt.
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventType      EventType
+	Payload        json.RawMessage
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookAttempt is one POST of a delivery. StatusCode is zero when no
+// response was received, Error is empty when the receiver answered with 2xx.
+type WebhookAttempt struct {
+	DeliveryID  int64
+	Attempt     int
+	StatusCode  int
+	Error       string
+	AttemptedAt time.Time
+}
+
+// WebhookDeliveryLog is a delivery with all of its attempts.
+type WebhookDeliveryLog struct {
+	Delivery WebhookDelivery
+	Attempts []WebhookAttempt
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// reviewerDecision is one automatic pick of reviewers for a pull request.
+// It collects an assignment record for every pool it looks at, the records
+// are saved with saveAssignments once the pull request is stored.
+type reviewerDecision struct {
+	pr    *domain.PullRequest
+	cause domain.AssignmentCause
+	// replacedUserID is the reviewer whose slot is filled
+	replacedUserID string
+	// leaving are the users whose reviews are handed over
+	leaving []string
+	// assigned are the reviewers the pull request keeps, reviewers picked by
+	// the decision are added as it goes
+	assigned []string
+	records  []domain.AssignmentRecord
+}
+
+func newReviewerDecision(pr *domain.PullRequest, cause domain.AssignmentCause) *reviewerDecision {
+	return &reviewerDecision{pr: pr, cause: cause, assigned: slices.Clone(pr.AssignedReviewers)}
+}
+
+// exclusionReason tells why userID may not be picked regardless of the pool,
+// an empty reason means the user may be.
+func (d *reviewerDecision) exclusionReason(userID string) domain.ExclusionReason {
+	switch {
+	case userID == d.pr.AuthorID:
+		return domain.ExclusionReasonAuthor
+	case slices.Contains(d.leaving, userID):
+		return domain.ExclusionReasonReplaced
+	case slices.Contains(d.assigned, userID):
+		return domain.ExclusionReasonAlreadyAssigned
+	}
+	return ""
+}
+
+// pickFromPool picks up to count reviewers among members with the reviewer
+// strategy of team and records the decision. Members missing from available,
+// the active members that are not unavailable, are excluded like the author,
+// current reviewers and users who reached their review limit.
+func (s *Service) pickFromPool(
+	ctx context.Context,
+	repos Repositories,
+	decision *reviewerDecision,
+	pool domain.ReviewerReason,
+	team string,
+	members []domain.User,
+	available []domain.User,
+	count int,
+) ([]domain.User, error) {
+	selector, strategy, err := s.teamSelector(ctx, repos, team)
+	if err != nil {
+		return nil, err
+	}
+
+	record := domain.AssignmentRecord{
+		PullRequestID:  decision.pr.ID,
+		Cause:          decision.cause,
+		ReplacedUserID: decision.replacedUserID,
+		Pool:           pool,
+		Team:           team,
+		Strategy:       strategy,
+		Candidates:     []string{},
+		Excluded:       []domain.ExcludedCandidate{},
+		Chosen:         []string{},
+		DecidedAt:      time.Now(),
+	}
+
+	isAvailable := make(map[string]bool, len(available))
+	for _, user := range available {
+		isAvailable[user.Id] = true
+	}
+	for _, member := range members {
+		reason := decision.exclusionReason(member.Id)
+		if reason == "" && !member.IsActive {
+			reason = domain.ExclusionReasonInactive
+		} else if reason == "" && !isAvailable[member.Id] {
+			reason = domain.ExclusionReasonUnavailable
+		}
+		if reason != "" {
+			record.Excluded = append(record.Excluded, domain.ExcludedCandidate{UserID: member.Id, Reason: reason})
+		}
+	}
+
+	var eligible []domain.User
+	for _, user := range available {
+		if decision.exclusionReason(user.Id) == "" {
+			eligible = append(eligible, user)
+		}
+	}
+
+	full, err := overCapacity(ctx, repos, eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []domain.User
+	for _, user := range eligible {
+		if full[user.Id] {
+			record.Excluded = append(record.Excluded, domain.ExcludedCandidate{UserID: user.Id, Reason: domain.ExclusionReasonOverCapacity})
+			continue
+		}
+		candidates = append(candidates, user)
+		record.Candidates = append(record.Candidates, user.Id)
+	}
+
+	picked := []domain.User{}
+	if len(candidates) > 0 && count > 0 {
+		picked, err = selector.Select(ctx, repos, team, candidates, count)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, reviewer := range picked {
+		record.Chosen = append(record.Chosen, reviewer.Id)
+	}
+
+	decision.records = append(decision.records, record)
+	decision.assigned = append(decision.assigned, record.Chosen...)
+	return picked, nil
+}
+
+// overCapacity returns the users that already have MaxOpenReviews OPEN reviews.
+func overCapacity(ctx context.Context, repos Repositories, users []domain.User) (map[string]bool, error) {
+	var limited []string
+	for _, user := range users {
+		if user.MaxOpenReviews != nil {
+			limited = append(limited, user.Id)
+		}
+	}
+	if len(limited) == 0 {
+		return nil, nil
+	}
+
+	load, err := repos.PullRequests.CountOpenReviews(ctx, limited)
+	if err != nil {
+		return nil, err
+	}
+
+	full := map[string]bool{}
+	for _, user := range users {
+		if user.MaxOpenReviews != nil && load[user.Id] >= *user.MaxOpenReviews {
+			full[user.Id] = true
+		}
+	}
+	return full, nil
+}
+
+// saveAssignments stores the records of the decisions, the pull request has
+// to be stored already.
+func saveAssignments(ctx context.Context, repos Repositories, decisions ...*reviewerDecision) error {
+	for _, decision := range decisions {
+		for i := range decision.records {
+			if _, err := repos.Assignments.Add(ctx, &decision.records[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetAssignmentHistory lists how the reviewers of the pull request were
+// picked automatically, oldest first.
+func (s *Service) GetAssignmentHistory(ctx context.Context, prID string) ([]domain.AssignmentRecord, error) {
+	if _, err := s.PRRepo.GetByID(ctx, prID); err != nil {
+		return nil, err
+	}
+
+	return s.AssignmentRepo.GetByPullRequest(ctx, prID)
+}
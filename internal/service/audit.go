@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+const (
+	// DefaultAuditPageSize is the number of entries GetAuditLog returns without a limit
+	DefaultAuditPageSize = 50
+	// MaxAuditPageSize bounds the limit of GetAuditLog
+	MaxAuditPageSize = 500
+)
+
+// SystemActor is the actor of changes made without AuditMeta,
+// like the roster command of the service binary.
+const SystemActor = "system"
+
+// AuditMeta tells who makes a change and within which request, it travels
+// with the context and is written to the audit log.
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+}
+
+type auditMetaKey struct{}
+
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+func auditMetaFrom(ctx context.Context) AuditMeta {
+	meta, _ := ctx.Value(auditMetaKey{}).(AuditMeta)
+	if meta.Actor == "" {
+		meta.Actor = SystemActor
+	}
+	return meta
+}
+
+// auditTarget is the entity an audited action changes.
+type auditTarget struct {
+	kind domain.AuditTargetType
+	id   string
+}
+
+func teamTarget(teamName string) auditTarget {
+	return auditTarget{kind: domain.AuditTargetTeam, id: teamName}
+}
+
+func userTarget(userID string) auditTarget {
+	return auditTarget{kind: domain.AuditTargetUser, id: userID}
+}
+
+func pullRequestTarget(prID string) auditTarget {
+	return auditTarget{kind: domain.AuditTargetPullRequest, id: prID}
+}
+
+func unavailabilityTarget(userID string) auditTarget {
+	return auditTarget{kind: domain.AuditTargetUnavailability, id: userID}
+}
+
+func webhookTarget(id int64) auditTarget {
+	return auditTarget{kind: domain.AuditTargetWebhook, id: strconv.FormatInt(id, 10)}
+}
+
+// audited wraps fn, the body of a transaction, so that it also appends an
+// entry for action to the audit log with snapshots of target taken before
+// and after fn. Nothing is logged when fn fails.
+func audited(ctx context.Context, action domain.AuditAction, target auditTarget, fn func(repos Repositories) error) func(repos Repositories) error {
+	return auditedMove(ctx, action, target, target, fn)
+}
+
+// auditedMove is audited for actions that change the id of the target. The
+// entry is filed under from, the after snapshot is taken of to.
+func auditedMove(ctx context.Context, action domain.AuditAction, from, to auditTarget, fn func(repos Repositories) error) func(repos Repositories) error {
+	return func(repos Repositories) error {
+		before, err := auditSnapshot(ctx, repos, from)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(repos); err != nil {
+			return err
+		}
+
+		after, err := auditSnapshot(ctx, repos, to)
+		if err != nil {
+			return err
+		}
+
+		return addAuditEntry(ctx, repos, action, from, before, after)
+	}
+}
+
+// auditCreated logs the creation of a target whose id is only known once it
+// is created, so it can't be wrapped with audited.
+func auditCreated(ctx context.Context, repos Repositories, action domain.AuditAction, target auditTarget) error {
+	after, err := auditSnapshot(ctx, repos, target)
+	if err != nil {
+		return err
+	}
+
+	return addAuditEntry(ctx, repos, action, target, nil, after)
+}
+
+func addAuditEntry(ctx context.Context, repos Repositories, action domain.AuditAction, target auditTarget, before, after json.RawMessage) error {
+	meta := auditMetaFrom(ctx)
+	_, err := repos.Audit.Add(ctx, &domain.AuditEntry{
+		Actor:      meta.Actor,
+		Action:     action,
+		TargetType: target.kind,
+		TargetID:   target.id,
+		Before:     before,
+		After:      after,
+		RequestID:  meta.RequestID,
+		CreatedAt:  time.Now(),
+	})
+	return err
+}
+
+// auditSnapshot returns the target as JSON, or nil when it does not exist.
+func auditSnapshot(ctx context.Context, repos Repositories, target auditTarget) (json.RawMessage, error) {
+	var snapshot any
+	var err error
+	switch target.kind {
+	case domain.AuditTargetTeam:
+		snapshot, err = teamSnapshot(ctx, repos, target.id)
+	case domain.AuditTargetUser:
+		snapshot, err = userSnapshot(ctx, repos, target.id)
+	case domain.AuditTargetPullRequest:
+		snapshot, err = pullRequestSnapshot(ctx, repos, target.id)
+	case domain.AuditTargetUnavailability:
+		snapshot, err = unavailabilitySnapshot(ctx, repos, target.id)
+	case domain.AuditTargetRoster:
+		snapshot, err = rosterSnapshot(ctx, repos)
+	case domain.AuditTargetWebhook:
+		snapshot, err = webhookSnapshot(ctx, repos, target.id)
+	default:
+		return nil, fmt.Errorf("unknown audit target type %s", target.kind)
+	}
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s %s for the audit log: %w", target.kind, target.id, err)
+	}
+	return data, nil
+}
+
+// Snapshots use the field names of the API, so entries read like its responses.
+
+type auditUser struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type auditMergePolicy struct {
+	MinApprovals            int      `json:"min_approvals"`
+	BlockOnChangesRequested bool     `json:"block_on_changes_requested"`
+	LeadIDs                 []string `json:"lead_ids"`
+}
+
+type auditOwnershipRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+type auditTeam struct {
+	TeamName         string               `json:"team_name"`
+	Members          []auditUser          `json:"members"`
+	ReviewerStrategy string               `json:"reviewer_strategy"`
+	ReviewerCount    int                  `json:"reviewer_count"`
+	MergePolicy      auditMergePolicy     `json:"merge_policy"`
+	FallbackTeams    []string             `json:"fallback_teams"`
+	OwnershipRules   []auditOwnershipRule `json:"ownership_rules"`
+}
+
+type auditReview struct {
+	UserID    string    `json:"user_id"`
+	Verdict   string    `json:"verdict"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type auditPullRequest struct {
+	PullRequestID     string        `json:"pull_request_id"`
+	PullRequestName   string        `json:"pull_request_name"`
+	AuthorID          string        `json:"author_id"`
+	Status            string        `json:"status"`
+	AssignedReviewers []string      `json:"assigned_reviewers"`
+	ChangedPaths      []string      `json:"changed_paths"`
+	CreatedAt         *time.Time    `json:"createdAt"`
+	MergedAt          *time.Time    `json:"mergedAt"`
+	Version           int           `json:"version"`
+	Reviews           []auditReview `json:"reviews"`
+}
+
+type auditUnavailability struct {
+	ID         int64     `json:"id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Reason     string    `json:"reason"`
+	HandedOver bool      `json:"handed_over"`
+}
+
+type auditWebhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	TeamName  string    `json:"team_name"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAuditUser(user domain.User) auditUser {
+	return auditUser{
+		UserID:         user.Id,
+		Username:       user.Name,
+		TeamName:       user.Team,
+		IsActive:       user.IsActive,
+		MaxOpenReviews: user.MaxOpenReviews,
+	}
+}
+
+func newAuditTeam(team domain.Team) auditTeam {
+	snapshot := auditTeam{
+		TeamName:         team.Name,
+		Members:          make([]auditUser, 0, len(team.Members)),
+		ReviewerStrategy: string(team.Settings.ReviewerStrategy),
+		ReviewerCount:    team.Settings.ReviewerCount,
+		MergePolicy: auditMergePolicy{
+			MinApprovals:            team.Settings.MergePolicy.MinApprovals,
+			BlockOnChangesRequested: team.Settings.MergePolicy.BlockOnChangesRequested,
+			LeadIDs:                 append([]string{}, team.Settings.MergePolicy.LeadIDs...),
+		},
+		FallbackTeams:  append([]string{}, team.Settings.FallbackTeams...),
+		OwnershipRules: make([]auditOwnershipRule, 0, len(team.Settings.OwnershipRules)),
+	}
+	for _, member := range team.Members {
+		snapshot.Members = append(snapshot.Members, newAuditUser(member))
+	}
+	for _, rule := range team.Settings.OwnershipRules {
+		snapshot.OwnershipRules = append(snapshot.OwnershipRules, auditOwnershipRule{Pattern: rule.Pattern, Owners: rule.Owners})
+	}
+	return snapshot
+}
+
+func teamSnapshot(ctx context.Context, repos Repositories, teamName string) (any, error) {
+	team, err := repos.Teams.Get(ctx, teamName)
+	var teamNotFoundErr *domain.TeamNotFoundError
+	if errors.As(err, &teamNotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAuditTeam(*team), nil
+}
+
+func userSnapshot(ctx context.Context, repos Repositories, userID string) (any, error) {
+	user, err := repos.Users.GetByID(ctx, userID)
+	var userNotFoundErr *domain.UserNotFoundError
+	if errors.As(err, &userNotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAuditUser(*user), nil
+}
+
+func pullRequestSnapshot(ctx context.Context, repos Repositories, prID string) (any, error) {
+	exists, err := repos.PullRequests.Exists(ctx, prID)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	pr, err := repos.PullRequests.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := repos.Reviews.GetByPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := auditPullRequest{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            string(pr.Status),
+		AssignedReviewers: append([]string{}, pr.AssignedReviewers...),
+		ChangedPaths:      append([]string{}, pr.ChangedPaths...),
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		Version:           pr.Version,
+		Reviews:           make([]auditReview, 0, len(reviews)),
+	}
+	for _, review := range reviews {
+		snapshot.Reviews = append(snapshot.Reviews, auditReview{
+			UserID:    review.ReviewerID,
+			Verdict:   string(review.Verdict),
+			UpdatedAt: review.UpdatedAt,
+		})
+	}
+	return snapshot, nil
+}
+
+func unavailabilitySnapshot(ctx context.Context, repos Repositories, userID string) (any, error) {
+	periods, err := repos.Unavailability.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]auditUnavailability, 0, len(periods))
+	for _, period := range periods {
+		snapshot = append(snapshot, auditUnavailability{
+			ID:         period.ID,
+			StartsAt:   period.StartsAt,
+			EndsAt:     period.EndsAt,
+			Reason:     period.Reason,
+			HandedOver: period.HandedOver,
+		})
+	}
+	return snapshot, nil
+}
+
+func rosterSnapshot(ctx context.Context, repos Repositories) (any, error) {
+	teams, err := repos.Teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]auditTeam, 0, len(teams))
+	for _, team := range teams {
+		snapshot = append(snapshot, newAuditTeam(team))
+	}
+	return snapshot, nil
+}
+
+func webhookSnapshot(ctx context.Context, repos Repositories, id string) (any, error) {
+	subscriptionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook subscription id %s: %w", id, err)
+	}
+
+	subscription, err := repos.Webhooks.Get(ctx, subscriptionID)
+	var notFoundErr *domain.WebhookNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := auditWebhook{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Events:    make([]string, 0, len(subscription.Events)),
+		TeamName:  subscription.TeamName,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+	}
+	for _, eventType := range subscription.Events {
+		snapshot.Events = append(snapshot.Events, string(eventType))
+	}
+	return snapshot, nil
+}
+
+// GetAuditLog returns a page of the entries matching filter ordered by id,
+// the next page starts after the ID of the last entry.
+func (s *Service) GetAuditLog(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := validateAuditFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultAuditPageSize
+	}
+
+	return s.AuditRepo.Find(ctx, filter)
+}
+
+// ExportAuditLog passes every entry matching filter to emit in id order,
+// reading the log page by page. The Limit of the filter is ignored.
+func (s *Service) ExportAuditLog(ctx context.Context, filter domain.AuditFilter, emit func(entry domain.AuditEntry) error) error {
+	filter.Limit = 0
+	if err := validateAuditFilter(filter); err != nil {
+		return err
+	}
+
+	filter.Limit = MaxAuditPageSize
+	for {
+		entries, err := s.AuditRepo.Find(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < filter.Limit {
+			return nil
+		}
+		filter.AfterID = entries[len(entries)-1].ID
+	}
+}
+
+func validateAuditFilter(filter domain.AuditFilter) error {
+	switch {
+	case filter.Limit < 0 || filter.Limit > MaxAuditPageSize:
+		return &domain.InvalidAuditFilterError{Reason: fmt.Sprintf("limit must be between 1 and %d", MaxAuditPageSize)}
+	case filter.AfterID < 0:
+		return &domain.InvalidAuditFilterError{Reason: "after_id must not be negative"}
+	case filter.From != nil && filter.To != nil && !filter.To.After(*filter.From):
+		return &domain.InvalidAuditFilterError{Reason: "to must be after from"}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// SetTeamOwnershipRules replaces the ownership rules of a team. Patterns have
+// to be valid and owners have to exist, they may belong to any team.
+func (s *Service) SetTeamOwnershipRules(ctx context.Context, teamName string, rules []domain.OwnershipRule) (*domain.TeamSettings, error) {
+	var updatedSettings *domain.TeamSettings
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamSetOwnershipRules, teamTarget(teamName), func(repos Repositories) error {
+		settings, err := repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range rules {
+			if err := domain.ValidateOwnershipPattern(rule.Pattern); err != nil {
+				return &domain.InvalidOwnershipRulesError{Reason: err.Error()}
+			}
+			for _, owner := range rule.Owners {
+				if _, err := repos.Users.GetByID(ctx, owner); err != nil {
+					return &domain.InvalidOwnershipRulesError{Reason: fmt.Sprintf("owner %s of %s not found", owner, rule.Pattern)}
+				}
+			}
+		}
+
+		settings.OwnershipRules = rules
+		updatedSettings, err = repos.Teams.UpdateSettings(ctx, teamName, settings)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSettings, nil
+}
+
+// pickPullRequestReviewers picks reviewers for the decision like
+// pickReviewers, but active owners of the changed paths come first.
+// Ownership rules are taken from the author's team, owners are picked with
+// its reviewer strategy.
+func (s *Service) pickPullRequestReviewers(ctx context.Context, repos Repositories, decision *reviewerDecision, teamName string, count int) ([]domain.ReviewerChoice, error) {
+	selected, err := s.pickOwners(ctx, repos, decision, count)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) >= count {
+		return selected, nil
+	}
+
+	rest, err := s.pickReviewers(ctx, repos, decision, teamName, count-len(selected))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(selected, rest...), nil
+}
+
+func (s *Service) pickOwners(ctx context.Context, repos Repositories, decision *reviewerDecision, count int) ([]domain.ReviewerChoice, error) {
+	pr := decision.pr
+	if len(pr.ChangedPaths) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+	}
+
+	settings, err := repos.Teams.GetSettings(ctx, author.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := domain.OwnersOf(settings.OwnershipRules, pr.ChangedPaths)
+	if len(owners) == 0 {
+		return nil, nil
+	}
+
+	ownedPaths := map[string][]string{}
+	var ownerUsers []domain.User
+	var ownerTeams []string
+	for _, owner := range owners {
+		user, err := repos.Users.GetByID(ctx, owner.UserID)
+		if err != nil {
+			// Rules may outlive the users they name
+			continue
+		}
+		ownedPaths[owner.UserID] = owner.Paths
+		ownerUsers = append(ownerUsers, *user)
+		if !slices.Contains(ownerTeams, user.Team) {
+			ownerTeams = append(ownerTeams, user.Team)
+		}
+	}
+
+	// Owners are looked up among active team members, so that inactive and
+	// unavailable owners are skipped like anybody else
+	var available []domain.User
+	for _, ownerTeam := range ownerTeams {
+		members, err := repos.Users.GetActiveTeamMembers(ctx, ownerTeam, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if _, isOwner := ownedPaths[member.Id]; isOwner {
+				available = append(available, member)
+			}
+		}
+	}
+
+	picked, err := s.pickFromPool(ctx, repos, decision, domain.ReviewerReasonOwner, author.Team, ownerUsers, available, count)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]domain.ReviewerChoice, 0, len(picked))
+	for _, reviewer := range picked {
+		selected = append(selected, domain.ReviewerChoice{
+			UserID: reviewer.Id,
+			Reason: domain.ReviewerReasonOwner,
+			Team:   reviewer.Team,
+			Paths:  ownedPaths[reviewer.Id],
+		})
+	}
+	return selected, nil
+}
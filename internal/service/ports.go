@@ -2,25 +2,194 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/raccoon00/avito-pr/internal/domain"
 )
 
+// Repositories groups the repositories bound to the same connection or transaction.
+type Repositories struct {
+	Teams           TeamRepository
+	Users           UserRepository
+	PullRequests    PullRequestRepository
+	Reviews         ReviewRepository
+	ReviewerHistory ReviewerHistoryRepository
+	Unavailability  UnavailabilityRepository
+	Assignments     AssignmentHistoryRepository
+	Audit           AuditLogRepository
+	Webhooks        WebhookRepository
+	Deliveries      WebhookDeliveryRepository
+}
+
+// TxManager runs fn atomically: everything done through the given repositories
+// is committed when fn returns nil and rolled back when it returns an error.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(repos Repositories) error) error
+}
+
 type TeamRepository interface {
 	Create(ctx context.Context, team *domain.Team) (*domain.Team, error)
 	Get(ctx context.Context, teamName string) (*domain.Team, error)
+	// List returns all teams with their members, ordered by name.
+	List(ctx context.Context) ([]domain.Team, error)
+	GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	// GetSettingsForUpdate is GetSettings that also locks the team until the
+	// transaction ends, so settings checked against each other stay unchanged.
+	GetSettingsForUpdate(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	UpdateSettings(ctx context.Context, teamName string, settings *domain.TeamSettings) (*domain.TeamSettings, error)
+	// Rename moves the team and all of its members to newName
+	// and returns TeamExistsError when newName is taken.
+	Rename(ctx context.Context, teamName string, newName string) (*domain.Team, error)
+	// Delete removes a team without members.
+	Delete(ctx context.Context, teamName string) error
 }
 
 type UserRepository interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	// GetActiveTeamMembers returns the active members of a team ordered by id,
+	// leaving out excludeUserID and users that are unavailable right now.
 	GetActiveTeamMembers(ctx context.Context, teamName string, excludeUserID string) ([]domain.User, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (*domain.User, error)
+	DeactivateUsers(ctx context.Context, userIDs []string) ([]domain.User, error)
+	// Create adds a user to an existing team and returns UserExistsError
+	// when the id is taken.
+	Create(ctx context.Context, user *domain.User) (*domain.User, error)
+	// Upsert creates the user or overwrites name, activity and team of an
+	// existing one, created tells which of the two happened.
+	Upsert(ctx context.Context, user *domain.User) (upserted *domain.User, created bool, err error)
+	SetTeam(ctx context.Context, userID string, teamName string) (*domain.User, error)
+	// Delete removes a user that has not authored any pull request.
+	Delete(ctx context.Context, userID string) error
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error)
 	GetByID(ctx context.Context, prID string) (*domain.PullRequest, error)
 	Exists(ctx context.Context, prID string) (bool, error)
+	ExistsByAuthor(ctx context.Context, authorID string) (bool, error)
+	// Update saves pr only if its Version is still current and returns
+	// PullRequestConflictError otherwise.
 	Update(ctx context.Context, pr *domain.PullRequest) (*domain.PullRequest, error)
 	GetByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error)
+	GetOpenByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)
+	// GetOpenByAuthors returns the OPEN pull requests of the given authors ordered by created_at.
+	GetOpenByAuthors(ctx context.Context, authorIDs []string) ([]domain.PullRequest, error)
+	// CountOpenReviews returns the number of OPEN pull requests each of the
+	// given users is assigned to. Users without open reviews are omitted.
+	CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+}
+
+// ReviewRepository keeps one review per reviewer and pull request.
+type ReviewRepository interface {
+	// Upsert stores the review or replaces the verdict and UpdatedAt
+	// of the reviewer's previous one, CreatedAt is kept.
+	Upsert(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	// GetByPullRequest returns the reviews of a pull request ordered by CreatedAt.
+	GetByPullRequest(ctx context.Context, prID string) ([]domain.Review, error)
+	// GetByReviewer returns the reviews submitted by a user ordered by CreatedAt.
+	GetByReviewer(ctx context.Context, userID string) ([]domain.Review, error)
+	// DeleteByReviewers removes the reviews of the reviewers on a pull request,
+	// reviewers without one are skipped.
+	DeleteByReviewers(ctx context.Context, prID string, reviewerIDs []string) error
+}
+
+// ReviewerHistoryRepository is an append-only log of reviewer changes.
+type ReviewerHistoryRepository interface {
+	Add(ctx context.Context, change *domain.ReviewerChange) (*domain.ReviewerChange, error)
+	// GetByPullRequest returns the changes of a pull request in the order they were added.
+	GetByPullRequest(ctx context.Context, prID string) ([]domain.ReviewerChange, error)
+}
+
+// AssignmentHistoryRepository is an append-only log of reviewer decisions.
+type AssignmentHistoryRepository interface {
+	Add(ctx context.Context, record *domain.AssignmentRecord) (*domain.AssignmentRecord, error)
+	// GetByPullRequest returns the records of a pull request in the order they were added.
+	GetByPullRequest(ctx context.Context, prID string) ([]domain.AssignmentRecord, error)
+}
+
+// AuditLogRepository is an append-only log of changes made through the API.
+type AuditLogRepository interface {
+	// Add assigns the entry the next ID.
+	Add(ctx context.Context, entry *domain.AuditEntry) (*domain.AuditEntry, error)
+	// Find returns the entries matching filter ordered by ID,
+	// a filter without Limit returns all of them.
+	Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	// Get returns WebhookNotFoundError when there is no such subscription.
+	Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	// List returns all subscriptions ordered by ID.
+	List(ctx context.Context) ([]domain.WebhookSubscription, error)
+	// Update overwrites everything but ID and CreatedAt and returns
+	// WebhookNotFoundError when there is no such subscription.
+	Update(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	// Delete removes the subscription with its deliveries and returns
+	// WebhookNotFoundError when there is no such subscription.
+	Delete(ctx context.Context, id int64) error
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error)
+	// ClaimDue returns at most limit PENDING deliveries of active subscriptions
+	// whose NextAttemptAt is not after at, ordered by NextAttemptAt, and moves
+	// their NextAttemptAt to until, so other workers skip them while they are
+	// sent. Deliveries locked by another transaction are skipped as well.
+	ClaimDue(ctx context.Context, at, until time.Time, limit int) ([]domain.WebhookDelivery, error)
+	// RecordAttempt appends attempt to the log of the delivery and saves
+	// its Status, Attempts, NextAttemptAt, LastError and DeliveredAt.
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error
+	// GetBySubscription returns at most limit deliveries of a subscription
+	// with their attempts, the latest first.
+	GetBySubscription(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDeliveryLog, error)
+}
+
+// EventPublisher hands events over for delivery. It is called inside the
+// transaction of the change that produced them and with its repositories,
+// so events of a change that is rolled back are dropped with it.
+type EventPublisher interface {
+	Publish(ctx context.Context, repos Repositories, events ...domain.Event) error
+}
+
+// WebhookSender POSTs payloads to subscribers.
+type WebhookSender interface {
+	// Send returns the status code of the response, a transport error
+	// is returned with a zero code.
+	Send(ctx context.Context, request WebhookRequest) (int, error)
+}
+
+// WebhookRequest is one attempt to deliver a payload, Signature is the
+// hex HMAC-SHA256 of Payload keyed with the secret of the subscription.
+type WebhookRequest struct {
+	URL        string
+	DeliveryID int64
+	EventType  domain.EventType
+	Payload    []byte
+	Signature  string
+}
+
+type UnavailabilityRepository interface {
+	// Create returns UserNotFoundError when the user does not exist.
+	Create(ctx context.Context, unavailability *domain.Unavailability) (*domain.Unavailability, error)
+	// GetByID returns UnavailabilityNotFoundError when there is no such period.
+	GetByID(ctx context.Context, id int64) (*domain.Unavailability, error)
+	// GetByUser returns the periods of a user ordered by StartsAt.
+	GetByUser(ctx context.Context, userID string) ([]domain.Unavailability, error)
+	// Delete returns UnavailabilityNotFoundError when there is no such period.
+	Delete(ctx context.Context, id int64) error
+	// GetPendingHandOvers returns the periods that cover at and whose
+	// reviews have not been handed over yet, ordered by StartsAt.
+	GetPendingHandOvers(ctx context.Context, at time.Time) ([]domain.Unavailability, error)
+	// MarkHandedOver returns UnavailabilityHandedOverError when the period
+	// is already marked, so only one caller hands its reviews over.
+	MarkHandedOver(ctx context.Context, id int64) error
+}
+
+// StatsRepository aggregates review assignments.
+// An empty teamName means statistics across all teams.
+type StatsRepository interface {
+	GetUserAssignmentStats(ctx context.Context, teamName string) ([]domain.UserAssignmentStats, error)
+	GetPullRequestAssignmentStats(ctx context.Context, teamName string) ([]domain.PullRequestAssignmentStats, error)
 }
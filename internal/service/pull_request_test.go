@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+func TestReopenHandsOverReviewsOfStaleReviewers(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	srv := service.CreateService(repos, memory.NewStatsRepo(store), memory.NewTxManager(store))
+
+	newTeam(t, ctx, repos, "backend", "author", "b", "c", "d", "e")
+	newPR(t, ctx, repos, "pr-1", "author", "b", "c")
+	if _, err := srv.ClosePullRequest(ctx, "pr-1"); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := repos.Users.SetIsActive(ctx, "b", false); err != nil {
+		t.Fatalf("Failed to deactivate b: %v", err)
+	}
+	now := time.Now()
+	_, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+		UserID:   "c",
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to make c unavailable: %v", err)
+	}
+
+	pr, err := srv.ReopenPullRequest(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	reviewers := slices.Sorted(slices.Values(pr.AssignedReviewers))
+	if pr.Status != domain.PullRequestStatusOpen || !slices.Equal(reviewers, []string{"d", "e"}) {
+		t.Fatalf("Stale reviewers were not handed over: %v %v", pr.Status, pr.AssignedReviewers)
+	}
+
+	records, err := srv.GetAssignmentHistory(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get assignment history: %v", err)
+	}
+	causes := map[string]domain.AssignmentCause{}
+	for _, record := range records {
+		causes[record.ReplacedUserID] = record.Cause
+	}
+	if causes["b"] != domain.AssignmentCauseDeactivation || causes["c"] != domain.AssignmentCauseUnavailability {
+		t.Fatalf("Hand-overs are not recorded with their causes: %v", causes)
+	}
+}
@@ -0,0 +1,116 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testAssignmentHistoryRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	t.Run("Records keep the insertion order", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		author, first, second, third := team.Members[0].Id, team.Members[1].Id, team.Members[2].Id, team.Members[3].Id
+		pr := createPR(t, ctx, repos, author, []string{first}, now)
+		other := createPR(t, ctx, repos, author, []string{first}, now)
+
+		records := []domain.AssignmentRecord{
+			{
+				PullRequestID: pr.ID,
+				Cause:         domain.AssignmentCauseInitial,
+				Pool:          domain.ReviewerReasonTeam,
+				Team:          team.Name,
+				Strategy:      domain.ReviewerStrategyRandom,
+				Candidates:    []string{first, second},
+				Excluded: []domain.ExcludedCandidate{
+					{UserID: author, Reason: domain.ExclusionReasonAuthor},
+					{UserID: third, Reason: domain.ExclusionReasonOverCapacity},
+				},
+				Chosen:    []string{first},
+				DecidedAt: now,
+			},
+			{
+				PullRequestID: other.ID,
+				Cause:         domain.AssignmentCauseInitial,
+				Pool:          domain.ReviewerReasonTeam,
+				Team:          team.Name,
+				Strategy:      domain.ReviewerStrategyRandom,
+				DecidedAt:     now,
+			},
+			// Records made at the same time stay in the order they were added
+			{
+				PullRequestID:  pr.ID,
+				Cause:          domain.AssignmentCauseReassignment,
+				ReplacedUserID: first,
+				Pool:           domain.ReviewerReasonFallbackTeam,
+				Team:           team.Name,
+				Strategy:       domain.ReviewerStrategyLeastLoaded,
+				Candidates:     []string{},
+				Excluded:       []domain.ExcludedCandidate{},
+				Chosen:         []string{},
+				DecidedAt:      now,
+			},
+		}
+		for _, record := range records {
+			added, err := repos.Assignments.Add(ctx, &record)
+			assertNoError(t, err, "Add record")
+			assertTrue(t, added.DecidedAt.Equal(now), "Decision time")
+		}
+
+		history, err := repos.Assignments.GetByPullRequest(ctx, pr.ID)
+		assertNoError(t, err, "Get history of PR")
+		assertEqual(t, 2, len(history), "History length")
+
+		initial := history[0]
+		assertEqual(t, domain.AssignmentCauseInitial, initial.Cause, "First record cause")
+		assertEqual(t, "", initial.ReplacedUserID, "Initial assignment replaces nobody")
+		assertEqual(t, domain.ReviewerReasonTeam, initial.Pool, "First record pool")
+		assertEqual(t, team.Name, initial.Team, "First record team")
+		assertEqual(t, domain.ReviewerStrategyRandom, initial.Strategy, "First record strategy")
+		assertIDs(t, []string{first, second}, initial.Candidates, "Candidates")
+		assertIDs(t, []string{first}, initial.Chosen, "Chosen")
+		assertEqual(t, 2, len(initial.Excluded), "Excluded count")
+		assertEqual(t, author, initial.Excluded[0].UserID, "Excluded user")
+		assertEqual(t, domain.ExclusionReasonAuthor, initial.Excluded[0].Reason, "Exclusion reason")
+		assertEqual(t, domain.ExclusionReasonOverCapacity, initial.Excluded[1].Reason, "Second exclusion reason")
+
+		reassignment := history[1]
+		assertEqual(t, domain.AssignmentCauseReassignment, reassignment.Cause, "Second record cause")
+		assertEqual(t, first, reassignment.ReplacedUserID, "Replaced user")
+		assertEqual(t, domain.ReviewerStrategyLeastLoaded, reassignment.Strategy, "Second record strategy")
+
+		empty, err := repos.Assignments.GetByPullRequest(ctx, other.ID)
+		assertNoError(t, err, "Get history of other PR")
+		assertEqual(t, 1, len(empty), "Other history length")
+		assertTrue(t, empty[0].Candidates != nil, "Empty candidates are not nil")
+		assertTrue(t, empty[0].Excluded != nil, "Empty exclusions are not nil")
+		assertTrue(t, empty[0].Chosen != nil, "Empty choice is not nil")
+
+		fresh := createPR(t, ctx, repos, author, []string{}, now)
+		none, err := repos.Assignments.GetByPullRequest(ctx, fresh.ID)
+		assertNoError(t, err, "Get history of PR without records")
+		assertTrue(t, none != nil, "Empty history is not nil")
+	})
+
+	t.Run("Record of non-existent PR", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		_, err := repos.Assignments.Add(ctx, &domain.AssignmentRecord{
+			PullRequestID: uniqueID("no-such-pr"),
+			Cause:         domain.AssignmentCauseInitial,
+			Pool:          domain.ReviewerReasonTeam,
+			Team:          team.Name,
+			Strategy:      domain.ReviewerStrategyRandom,
+			DecidedAt:     now,
+		})
+		assertTrue(t, err != nil, "Missing PR returns an error")
+		assertTrue(t, strings.Contains(err.Error(), "not found"), "Error mentions not found")
+	})
+}
@@ -0,0 +1,161 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+func testAuditLogRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	// addEntries stores the entries in order. Every test logs changes of
+	// targets of its own, so entries of other tests do not get in the way
+	addEntries := func(t *testing.T, repos service.Repositories, entries ...domain.AuditEntry) []domain.AuditEntry {
+		added := make([]domain.AuditEntry, 0, len(entries))
+		for _, entry := range entries {
+			stored, err := repos.Audit.Add(ctx, &entry)
+			assertNoError(t, err, "Add entry")
+			added = append(added, *stored)
+		}
+		return added
+	}
+
+	t.Run("Add and Find", func(t *testing.T) {
+		repos := newRepos(t)
+		teamName := uniqueID("audit-team")
+		otherTeam := uniqueID("audit-team")
+
+		added := addEntries(t, repos,
+			domain.AuditEntry{
+				Actor:      "alice",
+				Action:     domain.AuditActionTeamAdd,
+				TargetType: domain.AuditTargetTeam,
+				TargetID:   teamName,
+				After:      json.RawMessage(`{"team_name": "` + teamName + `", "members": []}`),
+				RequestID:  "req-1",
+				CreatedAt:  now,
+			},
+			domain.AuditEntry{
+				Actor:      "bob",
+				Action:     domain.AuditActionTeamAdd,
+				TargetType: domain.AuditTargetTeam,
+				TargetID:   otherTeam,
+				After:      json.RawMessage(`{}`),
+				RequestID:  "req-2",
+				CreatedAt:  now,
+			},
+			domain.AuditEntry{
+				Actor:      "alice",
+				Action:     domain.AuditActionTeamDelete,
+				TargetType: domain.AuditTargetTeam,
+				TargetID:   teamName,
+				Before:     json.RawMessage(`{"team_name": "` + teamName + `", "members": []}`),
+				RequestID:  "req-3",
+				CreatedAt:  now,
+			},
+		)
+		assertTrue(t, added[0].ID < added[1].ID && added[1].ID < added[2].ID, "Ids grow")
+		assertEqual(t, "alice", added[0].Actor, "Added actor")
+		assertTrue(t, added[0].CreatedAt.Equal(now), "Added time")
+
+		entries, err := repos.Audit.Find(ctx, domain.AuditFilter{TargetType: domain.AuditTargetTeam, TargetID: teamName})
+		assertNoError(t, err, "Find entries of the team")
+		assertEqual(t, 2, len(entries), "Entries of the team")
+
+		created := entries[0]
+		assertEqual(t, added[0].ID, created.ID, "First entry id")
+		assertEqual(t, "alice", created.Actor, "First entry actor")
+		assertEqual(t, domain.AuditActionTeamAdd, created.Action, "First entry action")
+		assertEqual(t, domain.AuditTargetTeam, created.TargetType, "First entry target type")
+		assertEqual(t, teamName, created.TargetID, "First entry target id")
+		assertEqual(t, "req-1", created.RequestID, "First entry request id")
+		assertTrue(t, created.CreatedAt.Equal(now), "First entry time")
+		assertTrue(t, created.Before == nil, "Missing snapshot is nil")
+		assertJSON(t, `{"team_name": "`+teamName+`", "members": []}`, created.After, "After snapshot")
+
+		deleted := entries[1]
+		assertEqual(t, domain.AuditActionTeamDelete, deleted.Action, "Second entry action")
+		assertJSON(t, `{"team_name": "`+teamName+`", "members": []}`, deleted.Before, "Before snapshot")
+		assertTrue(t, deleted.After == nil, "Deleted target has no after snapshot")
+
+		none, err := repos.Audit.Find(ctx, domain.AuditFilter{TargetID: uniqueID("no-such-target")})
+		assertNoError(t, err, "Find without matches")
+		assertTrue(t, none != nil, "No entries is not nil")
+		assertEqual(t, 0, len(none), "No entries")
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		repos := newRepos(t)
+		userID := uniqueID("audit-user")
+		requestID := uniqueID("req")
+		actor := uniqueID("actor")
+
+		entry := func(action domain.AuditAction, actor, requestID string, createdAt time.Time) domain.AuditEntry {
+			return domain.AuditEntry{
+				Actor:      actor,
+				Action:     action,
+				TargetType: domain.AuditTargetUser,
+				TargetID:   userID,
+				Before:     json.RawMessage(`{"is_active": true}`),
+				After:      json.RawMessage(`{"is_active": false}`),
+				RequestID:  requestID,
+				CreatedAt:  createdAt,
+			}
+		}
+		added := addEntries(t, repos,
+			entry(domain.AuditActionUserSetIsActive, actor, requestID, now.Add(-2*time.Hour)),
+			entry(domain.AuditActionUserSetReviewLimit, actor, uniqueID("req"), now.Add(-time.Hour)),
+			entry(domain.AuditActionUserSetIsActive, uniqueID("actor"), uniqueID("req"), now),
+		)
+
+		find := func(filter domain.AuditFilter) []domain.AuditEntry {
+			filter.TargetID = userID
+			entries, err := repos.Audit.Find(ctx, filter)
+			assertNoError(t, err, "Find entries")
+			return entries
+		}
+		ids := func(entries []domain.AuditEntry) []int64 {
+			ids := make([]int64, 0, len(entries))
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			return ids
+		}
+
+		assertEqual(t, 2, len(find(domain.AuditFilter{Actor: actor})), "Filter by actor")
+		assertEqual(t, 2, len(find(domain.AuditFilter{Action: domain.AuditActionUserSetIsActive})), "Filter by action")
+		assertEqual(t, 0, len(find(domain.AuditFilter{TargetType: domain.AuditTargetTeam})), "Filter by target type")
+
+		byRequest := find(domain.AuditFilter{RequestID: requestID})
+		assertEqual(t, 1, len(byRequest), "Filter by request")
+		assertEqual(t, added[0].ID, byRequest[0].ID, "Entry of the request")
+
+		from := now.Add(-90 * time.Minute)
+		to := now
+		assertTrue(t, reflect.DeepEqual([]int64{added[1].ID}, ids(find(domain.AuditFilter{From: &from, To: &to}))), "From is inclusive, to is exclusive")
+		assertTrue(t, reflect.DeepEqual([]int64{added[1].ID, added[2].ID}, ids(find(domain.AuditFilter{From: &from}))), "Filter from")
+
+		firstPage := find(domain.AuditFilter{Limit: 2})
+		assertTrue(t, reflect.DeepEqual([]int64{added[0].ID, added[1].ID}, ids(firstPage)), "First page")
+		secondPage := find(domain.AuditFilter{Limit: 2, AfterID: firstPage[1].ID})
+		assertTrue(t, reflect.DeepEqual([]int64{added[2].ID}, ids(secondPage)), "Second page")
+	})
+}
+
+// assertJSON compares JSON documents by value, storages may reformat them.
+func assertJSON(t *testing.T, expected string, actual json.RawMessage, message string) {
+	var expectedValue, actualValue any
+	assertNoError(t, json.Unmarshal([]byte(expected), &expectedValue), message)
+	assertNoError(t, json.Unmarshal(actual, &actualValue), message)
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		t.Logf("%s: expected %s, got %s", message, expected, actual)
+		t.FailNow()
+	}
+}
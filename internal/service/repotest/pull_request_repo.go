@@ -0,0 +1,321 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testPullRequestRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Create and get", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		reviewers := []string{team.Members[1].Id, team.Members[2].Id}
+
+		created := createPR(t, ctx, repos, team.Members[0].Id, reviewers, now)
+		assertEqual(t, 1, created.Version, "New PR version")
+		assertEqual(t, domain.PullRequestStatusOpen, created.Status, "New PR status")
+		assertIDs(t, reviewers, created.AssignedReviewers, "New PR reviewers")
+		assertTrue(t, created.MergedAt == nil, "New PR is not merged")
+
+		found, err := repos.PullRequests.GetByID(ctx, created.ID)
+		assertNoError(t, err, "Get PR")
+		assertEqual(t, created.Name, found.Name, "PR name")
+		assertEqual(t, created.AuthorID, found.AuthorID, "PR author")
+		assertEqual(t, created.Version, found.Version, "PR version")
+		assertIDs(t, reviewers, found.AssignedReviewers, "Reviewers keep their order")
+		assertTrue(t, found.CreatedAt != nil, "PR creation time")
+	})
+
+	t.Run("Create without reviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		created := createPR(t, ctx, repos, team.Members[0].Id, []string{}, now)
+		found, err := repos.PullRequests.GetByID(ctx, created.ID)
+		assertNoError(t, err, "Get PR")
+		assertTrue(t, found.AssignedReviewers != nil, "Empty reviewers are not nil")
+		assertEqual(t, 0, len(found.AssignedReviewers), "Reviewers count")
+	})
+
+	t.Run("Changed paths are stored", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		changedPaths := []string{"cmd/main.go", "docs/README.md"}
+
+		created, err := repos.PullRequests.Create(ctx, &domain.PullRequest{
+			ID:                uniqueID("pr"),
+			Name:              "Contract PR",
+			AuthorID:          team.Members[0].Id,
+			Status:            domain.PullRequestStatusOpen,
+			AssignedReviewers: []string{},
+			ChangedPaths:      changedPaths,
+			CreatedAt:         &now,
+		})
+		assertNoError(t, err, "Create PR")
+		assertIDs(t, changedPaths, created.ChangedPaths, "Created PR changed paths")
+
+		found, err := repos.PullRequests.GetByID(ctx, created.ID)
+		assertNoError(t, err, "Get PR")
+		assertIDs(t, changedPaths, found.ChangedPaths, "Stored changed paths")
+
+		plain := createPR(t, ctx, repos, team.Members[0].Id, []string{}, now)
+		found, err = repos.PullRequests.GetByID(ctx, plain.ID)
+		assertNoError(t, err, "Get PR")
+		assertTrue(t, found.ChangedPaths != nil, "Empty changed paths are not nil")
+		assertEqual(t, 0, len(found.ChangedPaths), "Changed paths count")
+	})
+
+	t.Run("Create duplicate PR", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		created := createPR(t, ctx, repos, team.Members[0].Id, []string{}, now)
+
+		_, err := repos.PullRequests.Create(ctx, created)
+		var prExistsErr *domain.PullRequestExistsError
+		assertTrue(t, errors.As(err, &prExistsErr), "Duplicate PR returns PullRequestExistsError")
+	})
+
+	t.Run("Create PR with more than two reviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		reviewers := []string{team.Members[1].Id, team.Members[2].Id, team.Members[3].Id}
+
+		created, err := repos.PullRequests.Create(ctx, &domain.PullRequest{
+			ID:                uniqueID("pr"),
+			Name:              "Contract PR",
+			AuthorID:          team.Members[0].Id,
+			Status:            domain.PullRequestStatusOpen,
+			AssignedReviewers: reviewers,
+			CreatedAt:         &now,
+		})
+		// The reviewer count is a team setting checked by the service
+		assertNoError(t, err, "Create PR with three reviewers")
+		assertIDs(t, reviewers, created.AssignedReviewers, "Stored reviewers")
+	})
+
+	t.Run("Get non-existent PR", func(t *testing.T) {
+		repos := newRepos(t)
+		prID := uniqueID("no-such-pr")
+
+		_, err := repos.PullRequests.GetByID(ctx, prID)
+		assertTrue(t, err != nil, "Missing PR returns an error")
+		// The HTTP layer maps such errors to 404 by this text
+		assertTrue(t, strings.Contains(err.Error(), "not found"), "Error mentions not found")
+
+		exists, err := repos.PullRequests.Exists(ctx, prID)
+		assertNoError(t, err, "Check PR existence")
+		assertEqual(t, false, exists, "Missing PR does not exist")
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		created := createPR(t, ctx, repos, team.Members[0].Id, []string{}, now)
+
+		exists, err := repos.PullRequests.Exists(ctx, created.ID)
+		assertNoError(t, err, "Check PR existence")
+		assertEqual(t, true, exists, "Created PR exists")
+	})
+
+	t.Run("ExistsByAuthor", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		createPR(t, ctx, repos, team.Members[0].Id, []string{team.Members[1].Id}, now)
+
+		exists, err := repos.PullRequests.ExistsByAuthor(ctx, team.Members[0].Id)
+		assertNoError(t, err, "Check author PRs")
+		assertEqual(t, true, exists, "Author has PRs")
+
+		exists, err = repos.PullRequests.ExistsByAuthor(ctx, team.Members[1].Id)
+		assertNoError(t, err, "Check reviewer PRs")
+		assertEqual(t, false, exists, "Reviewer is not an author")
+	})
+
+	t.Run("Update bumps version", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		pr := createPR(t, ctx, repos, team.Members[0].Id, []string{team.Members[1].Id}, now)
+
+		mergedAt := now.Add(time.Minute)
+		pr.Status = domain.PullRequestStatusMerged
+		pr.MergedAt = &mergedAt
+		pr.AssignedReviewers = []string{team.Members[2].Id}
+
+		updated, err := repos.PullRequests.Update(ctx, pr)
+		assertNoError(t, err, "Update PR")
+		assertEqual(t, 2, updated.Version, "Updated PR version")
+		assertEqual(t, domain.PullRequestStatusMerged, updated.Status, "Updated PR status")
+		assertTrue(t, updated.MergedAt != nil, "Updated PR merge time")
+		assertIDs(t, []string{team.Members[2].Id}, updated.AssignedReviewers, "Updated PR reviewers")
+
+		found, err := repos.PullRequests.GetByID(ctx, pr.ID)
+		assertNoError(t, err, "Get PR")
+		assertEqual(t, 2, found.Version, "Stored PR version")
+		assertEqual(t, domain.PullRequestStatusMerged, found.Status, "Stored PR status")
+	})
+
+	t.Run("Update with outdated version", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		pr := createPR(t, ctx, repos, team.Members[0].Id, []string{team.Members[1].Id}, now)
+
+		stale := *pr
+		_, err := repos.PullRequests.Update(ctx, pr)
+		assertNoError(t, err, "First update")
+
+		_, err = repos.PullRequests.Update(ctx, &stale)
+		var conflictErr *domain.PullRequestConflictError
+		assertTrue(t, errors.As(err, &conflictErr), "Outdated version returns PullRequestConflictError")
+
+		found, err := repos.PullRequests.GetByID(ctx, pr.ID)
+		assertNoError(t, err, "Get PR")
+		assertEqual(t, 2, found.Version, "Conflicting update is not saved")
+	})
+
+	t.Run("GetByReviewer orders newest first", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		author, reviewer := team.Members[0].Id, team.Members[1].Id
+
+		older := createPR(t, ctx, repos, author, []string{reviewer}, now.Add(-2*time.Hour))
+		newer := createPR(t, ctx, repos, author, []string{reviewer, team.Members[2].Id}, now.Add(-time.Hour))
+		createPR(t, ctx, repos, author, []string{team.Members[2].Id}, now)
+
+		// Merged PRs are still listed
+		mergedAt := now
+		older.Status = domain.PullRequestStatusMerged
+		older.MergedAt = &mergedAt
+		_, err := repos.PullRequests.Update(ctx, older)
+		assertNoError(t, err, "Merge PR")
+
+		prs, err := repos.PullRequests.GetByReviewer(ctx, reviewer)
+		assertNoError(t, err, "Get PRs by reviewer")
+
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.ID)
+		}
+		assertIDs(t, []string{newer.ID, older.ID}, ids, "Reviewer PRs by created_at DESC")
+
+		prs, err = repos.PullRequests.GetByReviewer(ctx, author)
+		assertNoError(t, err, "Get PRs of user without reviews")
+		assertEqual(t, 0, len(prs), "User without reviews")
+	})
+
+	t.Run("GetOpenByReviewers orders oldest first", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		author := team.Members[0].Id
+		first, second, other := team.Members[1].Id, team.Members[2].Id, team.Members[3].Id
+
+		newer := createPR(t, ctx, repos, author, []string{second}, now.Add(-time.Hour))
+		older := createPR(t, ctx, repos, author, []string{first, other}, now.Add(-2*time.Hour))
+		merged := createPR(t, ctx, repos, author, []string{first}, now.Add(-3*time.Hour))
+		createPR(t, ctx, repos, author, []string{other}, now)
+
+		mergedAt := now
+		merged.Status = domain.PullRequestStatusMerged
+		merged.MergedAt = &mergedAt
+		_, err := repos.PullRequests.Update(ctx, merged)
+		assertNoError(t, err, "Merge PR")
+
+		prs, err := repos.PullRequests.GetOpenByReviewers(ctx, []string{first, second})
+		assertNoError(t, err, "Get open PRs by reviewers")
+
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.ID)
+		}
+		assertIDs(t, []string{older.ID, newer.ID}, ids, "Open PRs by created_at")
+	})
+
+	t.Run("GetOpenByAuthors orders oldest first", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		author, otherAuthor, stranger, reviewer := team.Members[0].Id, team.Members[1].Id, team.Members[2].Id, team.Members[3].Id
+
+		newer := createPR(t, ctx, repos, author, []string{reviewer}, now.Add(-time.Hour))
+		older := createPR(t, ctx, repos, otherAuthor, []string{}, now.Add(-2*time.Hour))
+		merged := createPR(t, ctx, repos, author, []string{reviewer}, now.Add(-3*time.Hour))
+		createPR(t, ctx, repos, stranger, []string{reviewer}, now)
+
+		mergedAt := now
+		merged.Status = domain.PullRequestStatusMerged
+		merged.MergedAt = &mergedAt
+		_, err := repos.PullRequests.Update(ctx, merged)
+		assertNoError(t, err, "Merge PR")
+
+		prs, err := repos.PullRequests.GetOpenByAuthors(ctx, []string{author, otherAuthor})
+		assertNoError(t, err, "Get open PRs by authors")
+
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.ID)
+		}
+		assertIDs(t, []string{older.ID, newer.ID}, ids, "Open PRs by created_at")
+	})
+
+	t.Run("CountOpenReviews", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		author := team.Members[0].Id
+		busy, idle, other := team.Members[1].Id, team.Members[2].Id, team.Members[3].Id
+
+		createPR(t, ctx, repos, author, []string{busy, other}, now)
+		createPR(t, ctx, repos, author, []string{busy}, now)
+		merged := createPR(t, ctx, repos, author, []string{busy}, now)
+
+		mergedAt := now
+		merged.Status = domain.PullRequestStatusMerged
+		merged.MergedAt = &mergedAt
+		_, err := repos.PullRequests.Update(ctx, merged)
+		assertNoError(t, err, "Merge PR")
+
+		counts, err := repos.PullRequests.CountOpenReviews(ctx, []string{busy, idle})
+		assertNoError(t, err, "Count open reviews")
+		assertEqual(t, 2, counts[busy], "Merged PRs are not counted")
+		_, hasIdle := counts[idle]
+		assertEqual(t, false, hasIdle, "Users without open reviews are omitted")
+		_, hasOther := counts[other]
+		assertEqual(t, false, hasOther, "Users that were not asked for are omitted")
+	})
+
+	t.Run("Closed and draft PRs are not open reviews", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		author, reviewer := team.Members[0].Id, team.Members[1].Id
+
+		draft, err := repos.PullRequests.Create(ctx, &domain.PullRequest{
+			ID:                uniqueID("pr"),
+			Name:              "Draft PR",
+			AuthorID:          author,
+			Status:            domain.PullRequestStatusDraft,
+			AssignedReviewers: []string{},
+			CreatedAt:         &now,
+		})
+		assertNoError(t, err, "Create draft PR")
+		assertEqual(t, domain.PullRequestStatusDraft, draft.Status, "Draft status")
+
+		closed := createPR(t, ctx, repos, author, []string{reviewer}, now)
+		closed.Status = domain.PullRequestStatusClosed
+		closed, err = repos.PullRequests.Update(ctx, closed)
+		assertNoError(t, err, "Close PR")
+		assertEqual(t, domain.PullRequestStatusClosed, closed.Status, "Closed status")
+
+		counts, err := repos.PullRequests.CountOpenReviews(ctx, []string{reviewer})
+		assertNoError(t, err, "Count open reviews")
+		assertEqual(t, 0, counts[reviewer], "Closed PRs are not counted")
+
+		prs, err := repos.PullRequests.GetOpenByReviewers(ctx, []string{reviewer})
+		assertNoError(t, err, "Get open PRs by reviewers")
+		assertEqual(t, 0, len(prs), "Closed PRs are not open")
+	})
+}
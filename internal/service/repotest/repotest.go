@@ -0,0 +1,132 @@
+// Package repotest is a contract test suite for the repository ports of
+// the service package. Every storage backend runs it to prove it behaves
+// the same way as the Postgres adapter.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// NewRepositories returns repositories bound to the storage under test.
+// It is called once per test, the storage does not have to be empty,
+// all tests use ids that are unique for the run.
+type NewRepositories func(t *testing.T) service.Repositories
+
+func Run(t *testing.T, newRepos NewRepositories) {
+	t.Run("Teams", func(t *testing.T) {
+		testTeamRepository(t, newRepos)
+	})
+	t.Run("Users", func(t *testing.T) {
+		testUserRepository(t, newRepos)
+	})
+	t.Run("PullRequests", func(t *testing.T) {
+		testPullRequestRepository(t, newRepos)
+	})
+	t.Run("Reviews", func(t *testing.T) {
+		testReviewRepository(t, newRepos)
+	})
+	t.Run("ReviewerHistory", func(t *testing.T) {
+		testReviewerHistoryRepository(t, newRepos)
+	})
+	t.Run("Unavailability", func(t *testing.T) {
+		testUnavailabilityRepository(t, newRepos)
+	})
+	t.Run("AssignmentHistory", func(t *testing.T) {
+		testAssignmentHistoryRepository(t, newRepos)
+	})
+	t.Run("AuditLog", func(t *testing.T) {
+		testAuditLogRepository(t, newRepos)
+	})
+	t.Run("Webhooks", func(t *testing.T) {
+		testWebhookRepository(t, newRepos)
+	})
+}
+
+var (
+	runID     = time.Now().UnixNano()
+	idCounter atomic.Int64
+)
+
+// uniqueID makes ids that do not clash with data left by previous runs
+// against the same database.
+func uniqueID(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, runID, idCounter.Add(1))
+}
+
+// createTeam stores a team with the given members, all of them active.
+func createTeam(t *testing.T, ctx context.Context, repos service.Repositories, memberCount int) *domain.Team {
+	team := &domain.Team{
+		Name: uniqueID("team"),
+		Settings: domain.TeamSettings{
+			ReviewerStrategy: domain.ReviewerStrategyRandom,
+			ReviewerCount:    domain.DefaultReviewerCount,
+		},
+	}
+	for i := range memberCount {
+		team.Members = append(team.Members, domain.User{
+			Id:       uniqueID(fmt.Sprintf("u%d", i)),
+			Name:     fmt.Sprintf("User %d", i),
+			Team:     team.Name,
+			IsActive: true,
+		})
+	}
+
+	createdTeam, err := repos.Teams.Create(ctx, team)
+	if err != nil {
+		t.Logf("Failed to create team: %v", err)
+		t.FailNow()
+	}
+	return createdTeam
+}
+
+// createPR stores an OPEN pull request created at createdAt.
+func createPR(t *testing.T, ctx context.Context, repos service.Repositories, authorID string, reviewers []string, createdAt time.Time) *domain.PullRequest {
+	pr, err := repos.PullRequests.Create(ctx, &domain.PullRequest{
+		ID:                uniqueID("pr"),
+		Name:              "Contract PR",
+		AuthorID:          authorID,
+		Status:            domain.PullRequestStatusOpen,
+		AssignedReviewers: reviewers,
+		CreatedAt:         &createdAt,
+	})
+	if err != nil {
+		t.Logf("Failed to create pull request: %v", err)
+		t.FailNow()
+	}
+	return pr
+}
+
+func assertNoError(t *testing.T, err error, message string) {
+	if err != nil {
+		t.Logf("%s: unexpected error %v", message, err)
+		t.FailNow()
+	}
+}
+
+func assertEqual(t *testing.T, expected, actual any, message string) {
+	if expected != actual {
+		t.Logf("%s: expected %v, got %v", message, expected, actual)
+		t.FailNow()
+	}
+}
+
+func assertTrue(t *testing.T, condition bool, message string) {
+	if !condition {
+		t.Logf("%s: condition is false", message)
+		t.FailNow()
+	}
+}
+
+func assertIDs(t *testing.T, expected, actual []string, message string) {
+	if fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Logf("%s: expected %v, got %v", message, expected, actual)
+		t.FailNow()
+	}
+}
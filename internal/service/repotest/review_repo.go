@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testReviewRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	t.Run("Upsert keeps one review per reviewer", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		reviewer := team.Members[1].Id
+		pr := createPR(t, ctx, repos, team.Members[0].Id, []string{reviewer, team.Members[2].Id}, now)
+
+		created, err := repos.Reviews.Upsert(ctx, &domain.Review{
+			PullRequestID: pr.ID,
+			ReviewerID:    reviewer,
+			Verdict:       domain.ReviewVerdictChangesRequested,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		assertNoError(t, err, "Create review")
+		assertEqual(t, domain.ReviewVerdictChangesRequested, created.Verdict, "Created verdict")
+		assertTrue(t, created.CreatedAt.Equal(now), "Creation time")
+
+		later := now.Add(time.Minute)
+		updated, err := repos.Reviews.Upsert(ctx, &domain.Review{
+			PullRequestID: pr.ID,
+			ReviewerID:    reviewer,
+			Verdict:       domain.ReviewVerdictApproved,
+			CreatedAt:     later,
+			UpdatedAt:     later,
+		})
+		assertNoError(t, err, "Update review")
+		assertEqual(t, domain.ReviewVerdictApproved, updated.Verdict, "Updated verdict")
+		assertTrue(t, updated.CreatedAt.Equal(now), "Creation time is kept")
+		assertTrue(t, updated.UpdatedAt.Equal(later), "Update time")
+
+		reviews, err := repos.Reviews.GetByPullRequest(ctx, pr.ID)
+		assertNoError(t, err, "Get reviews of PR")
+		assertEqual(t, 1, len(reviews), "Reviews count")
+		assertEqual(t, domain.ReviewVerdictApproved, reviews[0].Verdict, "Stored verdict")
+	})
+
+	t.Run("Get by reviewer", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		reviewer := team.Members[1].Id
+		first := createPR(t, ctx, repos, team.Members[0].Id, []string{reviewer}, now)
+		second := createPR(t, ctx, repos, team.Members[0].Id, []string{reviewer}, now)
+
+		for i, pr := range []string{second.ID, first.ID} {
+			submittedAt := now.Add(time.Duration(i) * time.Second)
+			_, err := repos.Reviews.Upsert(ctx, &domain.Review{
+				PullRequestID: pr,
+				ReviewerID:    reviewer,
+				Verdict:       domain.ReviewVerdictCommented,
+				CreatedAt:     submittedAt,
+				UpdatedAt:     submittedAt,
+			})
+			assertNoError(t, err, "Create review")
+		}
+
+		reviews, err := repos.Reviews.GetByReviewer(ctx, reviewer)
+		assertNoError(t, err, "Get reviews of reviewer")
+		var prIDs []string
+		for _, review := range reviews {
+			prIDs = append(prIDs, review.PullRequestID)
+		}
+		assertIDs(t, []string{second.ID, first.ID}, prIDs, "Reviews are ordered by creation time")
+
+		none, err := repos.Reviews.GetByReviewer(ctx, uniqueID("no-reviews"))
+		assertNoError(t, err, "Get reviews of user without reviews")
+		assertTrue(t, none != nil, "Empty reviews are not nil")
+	})
+
+	t.Run("DeleteByReviewers", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		first, second := team.Members[1].Id, team.Members[2].Id
+		pr := createPR(t, ctx, repos, team.Members[0].Id, []string{first, second}, now)
+		other := createPR(t, ctx, repos, team.Members[0].Id, []string{first}, now)
+
+		for _, review := range []domain.Review{
+			{PullRequestID: pr.ID, ReviewerID: first},
+			{PullRequestID: pr.ID, ReviewerID: second},
+			{PullRequestID: other.ID, ReviewerID: first},
+		} {
+			review.Verdict = domain.ReviewVerdictApproved
+			review.CreatedAt, review.UpdatedAt = now, now
+			_, err := repos.Reviews.Upsert(ctx, &review)
+			assertNoError(t, err, "Create review")
+		}
+
+		err := repos.Reviews.DeleteByReviewers(ctx, pr.ID, []string{first, uniqueID("no-review")})
+		assertNoError(t, err, "Delete reviews")
+
+		reviews, err := repos.Reviews.GetByPullRequest(ctx, pr.ID)
+		assertNoError(t, err, "Get reviews of PR")
+		assertEqual(t, 1, len(reviews), "Reviews count")
+		assertEqual(t, second, reviews[0].ReviewerID, "Review of the other reviewer is kept")
+
+		reviews, err = repos.Reviews.GetByPullRequest(ctx, other.ID)
+		assertNoError(t, err, "Get reviews of other PR")
+		assertEqual(t, 1, len(reviews), "Reviews of other PRs are kept")
+	})
+
+	t.Run("Review of non-existent PR", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		_, err := repos.Reviews.Upsert(ctx, &domain.Review{
+			PullRequestID: uniqueID("no-such-pr"),
+			ReviewerID:    team.Members[0].Id,
+			Verdict:       domain.ReviewVerdictApproved,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		assertTrue(t, err != nil, "Missing PR returns an error")
+		assertTrue(t, strings.Contains(err.Error(), "not found"), "Error mentions not found")
+	})
+}
@@ -0,0 +1,62 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testReviewerHistoryRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	t.Run("Changes keep the insertion order", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+		first, second := team.Members[1].Id, team.Members[2].Id
+		pr := createPR(t, ctx, repos, team.Members[0].Id, []string{first}, now)
+		other := createPR(t, ctx, repos, team.Members[0].Id, []string{first}, now)
+
+		changes := []domain.ReviewerChange{
+			{PullRequestID: pr.ID, UserID: second, Action: domain.ReviewerChangeAdded, ChangedAt: now},
+			{PullRequestID: other.ID, UserID: second, Action: domain.ReviewerChangeAdded, ChangedAt: now},
+			// Changes made at the same time stay in the order they were added
+			{PullRequestID: pr.ID, UserID: first, Action: domain.ReviewerChangeRemoved, ChangedAt: now},
+		}
+		for _, change := range changes {
+			added, err := repos.ReviewerHistory.Add(ctx, &change)
+			assertNoError(t, err, "Add change")
+			assertTrue(t, added.ChangedAt.Equal(now), "Change time")
+		}
+
+		history, err := repos.ReviewerHistory.GetByPullRequest(ctx, pr.ID)
+		assertNoError(t, err, "Get history of PR")
+		assertEqual(t, 2, len(history), "History length")
+		assertEqual(t, second, history[0].UserID, "First change user")
+		assertEqual(t, domain.ReviewerChangeAdded, history[0].Action, "First change action")
+		assertEqual(t, first, history[1].UserID, "Second change user")
+		assertEqual(t, domain.ReviewerChangeRemoved, history[1].Action, "Second change action")
+
+		empty := createPR(t, ctx, repos, team.Members[0].Id, []string{}, now)
+		none, err := repos.ReviewerHistory.GetByPullRequest(ctx, empty.ID)
+		assertNoError(t, err, "Get history of PR without changes")
+		assertTrue(t, none != nil, "Empty history is not nil")
+	})
+
+	t.Run("Change of non-existent PR", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		_, err := repos.ReviewerHistory.Add(ctx, &domain.ReviewerChange{
+			PullRequestID: uniqueID("no-such-pr"),
+			UserID:        team.Members[0].Id,
+			Action:        domain.ReviewerChangeAdded,
+			ChangedAt:     now,
+		})
+		assertTrue(t, err != nil, "Missing PR returns an error")
+		assertTrue(t, strings.Contains(err.Error(), "not found"), "Error mentions not found")
+	})
+}
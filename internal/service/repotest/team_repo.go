@@ -0,0 +1,279 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testTeamRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("Create returns team with members", func(t *testing.T) {
+		repos := newRepos(t)
+		team := &domain.Team{
+			Name:     uniqueID("team"),
+			Settings: domain.TeamSettings{ReviewerStrategy: domain.ReviewerStrategyRoundRobin, ReviewerCount: 3},
+		}
+		team.Members = []domain.User{
+			{Id: uniqueID("u"), Name: "Alice", Team: team.Name, IsActive: true},
+			{Id: uniqueID("u"), Name: "Bob", Team: team.Name, IsActive: false},
+		}
+
+		created, err := repos.Teams.Create(ctx, team)
+		assertNoError(t, err, "Create team")
+		assertEqual(t, team.Name, created.Name, "Team name")
+		assertEqual(t, domain.ReviewerStrategyRoundRobin, created.Settings.ReviewerStrategy, "Reviewer strategy")
+		assertEqual(t, 3, created.Settings.ReviewerCount, "Reviewer count")
+		assertEqual(t, 2, len(created.Members), "Members count")
+		for i, member := range created.Members {
+			assertEqual(t, team.Members[i].Id, member.Id, "Member id")
+			assertEqual(t, team.Members[i].Name, member.Name, "Member name")
+			assertEqual(t, team.Name, member.Team, "Member team")
+			assertEqual(t, team.Members[i].IsActive, member.IsActive, "Member activity")
+			assertTrue(t, member.MaxOpenReviews == nil, "New member has no review limit")
+		}
+	})
+
+	t.Run("Create duplicate team", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 0)
+
+		_, err := repos.Teams.Create(ctx, &domain.Team{Name: team.Name, Settings: team.Settings})
+		var teamExistsErr *domain.TeamExistsError
+		assertTrue(t, errors.As(err, &teamExistsErr), "Duplicate team returns TeamExistsError")
+	})
+
+	t.Run("Get returns members and settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+
+		found, err := repos.Teams.Get(ctx, team.Name)
+		assertNoError(t, err, "Get team")
+		assertEqual(t, team.Name, found.Name, "Team name")
+		assertEqual(t, domain.ReviewerStrategyRandom, found.Settings.ReviewerStrategy, "Reviewer strategy")
+		assertEqual(t, 3, len(found.Members), "Members count")
+		for _, member := range found.Members {
+			assertEqual(t, team.Name, member.Team, "Member team")
+		}
+	})
+
+	t.Run("Get empty team", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 0)
+
+		found, err := repos.Teams.Get(ctx, team.Name)
+		assertNoError(t, err, "Get team")
+		assertTrue(t, found.Members != nil, "Members of an empty team are not nil")
+		assertEqual(t, 0, len(found.Members), "Members count")
+	})
+
+	t.Run("Get non-existent team", func(t *testing.T) {
+		repos := newRepos(t)
+
+		_, err := repos.Teams.Get(ctx, uniqueID("no-such-team"))
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Missing team returns TeamNotFoundError")
+	})
+
+	t.Run("List returns teams with members", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		empty := createTeam(t, ctx, repos, 0)
+
+		teams, err := repos.Teams.List(ctx)
+		assertNoError(t, err, "List teams")
+		assertTrue(t, slices.IsSortedFunc(teams, func(a, b domain.Team) int {
+			return strings.Compare(a.Name, b.Name)
+		}), "Teams are ordered by name")
+
+		byName := map[string]domain.Team{}
+		for _, listed := range teams {
+			byName[listed.Name] = listed
+		}
+		assertEqual(t, 2, len(byName[team.Name].Members), "Members count")
+		assertEqual(t, team.Members[0].Id, byName[team.Name].Members[0].Id, "First member")
+		assertTrue(t, byName[empty.Name].Members != nil, "Members of an empty team are not nil")
+		assertEqual(t, 0, len(byName[empty.Name].Members), "Empty team members count")
+	})
+
+	t.Run("Update and get settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		updated, err := repos.Teams.UpdateSettings(ctx, team.Name, &domain.TeamSettings{
+			ReviewerStrategy: domain.ReviewerStrategyLeastLoaded,
+			ReviewerCount:    5,
+		})
+		assertNoError(t, err, "Update settings")
+		assertEqual(t, domain.ReviewerStrategyLeastLoaded, updated.ReviewerStrategy, "Updated strategy")
+		assertEqual(t, 5, updated.ReviewerCount, "Updated reviewer count")
+
+		settings, err := repos.Teams.GetSettings(ctx, team.Name)
+		assertNoError(t, err, "Get settings")
+		assertEqual(t, domain.ReviewerStrategyLeastLoaded, settings.ReviewerStrategy, "Stored strategy")
+		assertEqual(t, 5, settings.ReviewerCount, "Stored reviewer count")
+
+		locked, err := repos.Teams.GetSettingsForUpdate(ctx, team.Name)
+		assertNoError(t, err, "Get settings for update")
+		assertEqual(t, domain.ReviewerStrategyLeastLoaded, locked.ReviewerStrategy, "Locked strategy")
+		assertEqual(t, 5, locked.ReviewerCount, "Locked reviewer count")
+	})
+
+	t.Run("Merge policy is stored with settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		policy := domain.MergePolicy{
+			MinApprovals:            2,
+			BlockOnChangesRequested: true,
+			LeadIDs:                 []string{team.Members[0].Id},
+		}
+
+		updated, err := repos.Teams.UpdateSettings(ctx, team.Name, &domain.TeamSettings{
+			ReviewerStrategy: domain.ReviewerStrategyRandom,
+			ReviewerCount:    domain.DefaultReviewerCount,
+			MergePolicy:      policy,
+		})
+		assertNoError(t, err, "Update settings")
+		assertEqual(t, 2, updated.MergePolicy.MinApprovals, "Updated min approvals")
+
+		newName := uniqueID("renamed")
+		_, err = repos.Teams.Rename(ctx, team.Name, newName)
+		assertNoError(t, err, "Rename team")
+
+		found, err := repos.Teams.Get(ctx, newName)
+		assertNoError(t, err, "Get team")
+		assertEqual(t, 2, found.Settings.MergePolicy.MinApprovals, "Stored min approvals")
+		assertTrue(t, found.Settings.MergePolicy.BlockOnChangesRequested, "Stored changes requested block")
+		assertIDs(t, policy.LeadIDs, found.Settings.MergePolicy.LeadIDs, "Stored leads")
+
+		created := createTeam(t, ctx, repos, 1)
+		settings, err := repos.Teams.GetSettings(ctx, created.Name)
+		assertNoError(t, err, "Get settings")
+		assertEqual(t, 0, settings.MergePolicy.MinApprovals, "Default min approvals")
+		assertTrue(t, settings.MergePolicy.LeadIDs != nil, "Empty leads are not nil")
+	})
+
+	t.Run("Fallback teams are stored with settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		pool := createTeam(t, ctx, repos, 1)
+		other := createTeam(t, ctx, repos, 0)
+		fallbackTeams := []string{pool.Name, other.Name}
+
+		settings := team.Settings
+		settings.FallbackTeams = fallbackTeams
+		updated, err := repos.Teams.UpdateSettings(ctx, team.Name, &settings)
+		assertNoError(t, err, "Update settings")
+		assertIDs(t, fallbackTeams, updated.FallbackTeams, "Updated fallback teams")
+
+		found, err := repos.Teams.Get(ctx, team.Name)
+		assertNoError(t, err, "Get team")
+		assertIDs(t, fallbackTeams, found.Settings.FallbackTeams, "Stored fallback teams")
+
+		stored, err := repos.Teams.GetSettings(ctx, pool.Name)
+		assertNoError(t, err, "Get settings")
+		assertTrue(t, stored.FallbackTeams != nil, "Empty fallback teams are not nil")
+		assertEqual(t, 0, len(stored.FallbackTeams), "Default fallback teams")
+	})
+
+	t.Run("Ownership rules are stored with settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		rules := []domain.OwnershipRule{
+			{Pattern: "*.go", Owners: []string{team.Members[0].Id, team.Members[1].Id}},
+			{Pattern: "/docs/", Owners: []string{}},
+		}
+
+		settings := team.Settings
+		settings.OwnershipRules = rules
+		_, err := repos.Teams.UpdateSettings(ctx, team.Name, &settings)
+		assertNoError(t, err, "Update settings")
+
+		stored, err := repos.Teams.GetSettings(ctx, team.Name)
+		assertNoError(t, err, "Get settings")
+		assertEqual(t, len(rules), len(stored.OwnershipRules), "Rules count")
+		for i, rule := range rules {
+			assertEqual(t, rule.Pattern, stored.OwnershipRules[i].Pattern, "Rules keep their order")
+			assertIDs(t, rule.Owners, stored.OwnershipRules[i].Owners, "Rule owners")
+		}
+		assertTrue(t, stored.OwnershipRules[1].Owners != nil, "Empty owners are not nil")
+
+		other := createTeam(t, ctx, repos, 0)
+		defaults, err := repos.Teams.GetSettings(ctx, other.Name)
+		assertNoError(t, err, "Get settings")
+		assertTrue(t, defaults.OwnershipRules != nil, "Empty rules are not nil")
+		assertEqual(t, 0, len(defaults.OwnershipRules), "Default rules")
+	})
+
+	t.Run("Settings of non-existent team", func(t *testing.T) {
+		repos := newRepos(t)
+		teamName := uniqueID("no-such-team")
+		var teamNotFoundErr *domain.TeamNotFoundError
+
+		_, err := repos.Teams.GetSettings(ctx, teamName)
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "GetSettings returns TeamNotFoundError")
+
+		_, err = repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "GetSettingsForUpdate returns TeamNotFoundError")
+
+		_, err = repos.Teams.UpdateSettings(ctx, teamName, &domain.TeamSettings{ReviewerStrategy: domain.ReviewerStrategyRandom})
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "UpdateSettings returns TeamNotFoundError")
+	})
+
+	t.Run("Rename keeps members and settings", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		_, err := repos.Teams.UpdateSettings(ctx, team.Name, &domain.TeamSettings{ReviewerStrategy: domain.ReviewerStrategyLeastLoaded})
+		assertNoError(t, err, "Update settings")
+		newName := uniqueID("renamed")
+
+		renamed, err := repos.Teams.Rename(ctx, team.Name, newName)
+		assertNoError(t, err, "Rename team")
+		assertEqual(t, newName, renamed.Name, "Renamed team name")
+		assertEqual(t, 2, len(renamed.Members), "Members count")
+		assertEqual(t, domain.ReviewerStrategyLeastLoaded, renamed.Settings.ReviewerStrategy, "Strategy")
+		for _, member := range renamed.Members {
+			assertEqual(t, newName, member.Team, "Member team")
+		}
+
+		_, err = repos.Teams.Get(ctx, team.Name)
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Old name is gone")
+	})
+
+	t.Run("Rename to taken name", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		other := createTeam(t, ctx, repos, 0)
+
+		_, err := repos.Teams.Rename(ctx, team.Name, other.Name)
+		var teamExistsErr *domain.TeamExistsError
+		assertTrue(t, errors.As(err, &teamExistsErr), "Taken name returns TeamExistsError")
+
+		_, err = repos.Teams.Rename(ctx, uniqueID("no-such-team"), uniqueID("renamed"))
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Missing team returns TeamNotFoundError")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		empty := createTeam(t, ctx, repos, 0)
+
+		err := repos.Teams.Delete(ctx, team.Name)
+		var notEmptyErr *domain.TeamNotEmptyError
+		assertTrue(t, errors.As(err, &notEmptyErr), "Team with members returns TeamNotEmptyError")
+
+		err = repos.Teams.Delete(ctx, empty.Name)
+		assertNoError(t, err, "Delete empty team")
+
+		err = repos.Teams.Delete(ctx, empty.Name)
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Deleted team returns TeamNotFoundError")
+	})
+}
@@ -0,0 +1,173 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testUnavailabilityRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	t.Run("Create and GetByUser", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		userID := team.Members[0].Id
+
+		later, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+			UserID:   userID,
+			StartsAt: now.Add(24 * time.Hour),
+			EndsAt:   now.Add(48 * time.Hour),
+			Reason:   "vacation",
+		})
+		assertNoError(t, err, "Create later period")
+		assertTrue(t, later.ID != 0, "Id is assigned")
+		assertEqual(t, userID, later.UserID, "Period user")
+		assertEqual(t, "vacation", later.Reason, "Period reason")
+		assertEqual(t, false, later.HandedOver, "Period is not handed over")
+		assertTrue(t, later.StartsAt.Equal(now.Add(24*time.Hour)), "Period start")
+		assertTrue(t, later.EndsAt.Equal(now.Add(48*time.Hour)), "Period end")
+
+		earlier, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+			UserID:     userID,
+			StartsAt:   now.Add(-time.Hour),
+			EndsAt:     now.Add(time.Hour),
+			HandedOver: true,
+		})
+		assertNoError(t, err, "Create earlier period")
+		assertTrue(t, earlier.ID != later.ID, "Ids differ")
+		assertEqual(t, true, earlier.HandedOver, "Handed over flag is stored")
+
+		periods, err := repos.Unavailability.GetByUser(ctx, userID)
+		assertNoError(t, err, "Get periods")
+		assertEqual(t, 2, len(periods), "Periods count")
+		assertEqual(t, earlier.ID, periods[0].ID, "Periods are ordered by start")
+		assertEqual(t, later.ID, periods[1].ID, "Later period goes last")
+
+		none, err := repos.Unavailability.GetByUser(ctx, team.Members[1].Id)
+		assertNoError(t, err, "Get periods of user without any")
+		assertTrue(t, none != nil, "No periods is not nil")
+		assertEqual(t, 0, len(none), "No periods")
+	})
+
+	t.Run("Create for non-existent user", func(t *testing.T) {
+		repos := newRepos(t)
+
+		_, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+			UserID:   uniqueID("no-such-user"),
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+		})
+		var userNotFoundErr *domain.UserNotFoundError
+		assertTrue(t, errors.As(err, &userNotFoundErr), "Missing user returns UserNotFoundError")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+
+		period, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+			UserID:   team.Members[0].Id,
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+		})
+		assertNoError(t, err, "Create period")
+
+		found, err := repos.Unavailability.GetByID(ctx, period.ID)
+		assertNoError(t, err, "Get period by id")
+		assertEqual(t, period.UserID, found.UserID, "Found period user")
+		assertTrue(t, found.StartsAt.Equal(period.StartsAt), "Found period start")
+
+		err = repos.Unavailability.Delete(ctx, period.ID)
+		assertNoError(t, err, "Delete period")
+
+		periods, err := repos.Unavailability.GetByUser(ctx, team.Members[0].Id)
+		assertNoError(t, err, "Get periods")
+		assertEqual(t, 0, len(periods), "Period is gone")
+
+		err = repos.Unavailability.Delete(ctx, period.ID)
+		var notFoundErr *domain.UnavailabilityNotFoundError
+		assertTrue(t, errors.As(err, &notFoundErr), "Deleted period returns UnavailabilityNotFoundError")
+
+		_, err = repos.Unavailability.GetByID(ctx, period.ID)
+		assertTrue(t, errors.As(err, &notFoundErr), "Getting a deleted period returns UnavailabilityNotFoundError")
+	})
+
+	t.Run("Periods are removed with the user", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		userID := team.Members[0].Id
+
+		_, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+			UserID:   userID,
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+		})
+		assertNoError(t, err, "Create period")
+
+		err = repos.Users.Delete(ctx, userID)
+		assertNoError(t, err, "Delete user with a period")
+
+		periods, err := repos.Unavailability.GetByUser(ctx, userID)
+		assertNoError(t, err, "Get periods of deleted user")
+		assertEqual(t, 0, len(periods), "Periods are removed")
+	})
+
+	t.Run("GetPendingHandOvers and MarkHandedOver", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		userID := team.Members[0].Id
+		at := now.Add(24 * time.Hour)
+
+		create := func(startsAt, endsAt time.Time) *domain.Unavailability {
+			period, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+				UserID:   userID,
+				StartsAt: startsAt,
+				EndsAt:   endsAt,
+			})
+			assertNoError(t, err, "Create period")
+			return period
+		}
+		second := create(at.Add(-time.Minute), at.Add(time.Hour))
+		first := create(at.Add(-time.Hour), at.Add(time.Minute))
+		// The start is inclusive and the end exclusive
+		starting := create(at, at.Add(time.Hour))
+		create(at.Add(-time.Hour), at)
+		create(at.Add(time.Minute), at.Add(time.Hour))
+
+		pending, err := repos.Unavailability.GetPendingHandOvers(ctx, at)
+		assertNoError(t, err, "Get pending hand-overs")
+		// The storage may hold periods of other users
+		var ids []int64
+		for _, period := range pending {
+			if period.UserID == userID {
+				ids = append(ids, period.ID)
+			}
+		}
+		assertEqual(t, 3, len(ids), "Pending periods count")
+		assertEqual(t, first.ID, ids[0], "Earliest start goes first")
+		assertEqual(t, second.ID, ids[1], "Second pending period")
+		assertEqual(t, starting.ID, ids[2], "Period starting at the moment is pending")
+
+		err = repos.Unavailability.MarkHandedOver(ctx, second.ID)
+		assertNoError(t, err, "Mark handed over")
+
+		pending, err = repos.Unavailability.GetPendingHandOvers(ctx, at)
+		assertNoError(t, err, "Get pending hand-overs after marking")
+		for _, period := range pending {
+			assertTrue(t, period.ID != second.ID, "Handed over period is not pending")
+		}
+
+		err = repos.Unavailability.MarkHandedOver(ctx, second.ID)
+		var handedOverErr *domain.UnavailabilityHandedOverError
+		assertTrue(t, errors.As(err, &handedOverErr), "Marking twice returns UnavailabilityHandedOverError")
+
+		err = repos.Unavailability.MarkHandedOver(ctx, second.ID+1000000)
+		var notFoundErr *domain.UnavailabilityNotFoundError
+		assertTrue(t, errors.As(err, &notFoundErr), "Missing period returns UnavailabilityNotFoundError")
+	})
+}
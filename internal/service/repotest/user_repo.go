@@ -0,0 +1,230 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+func testUserRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+
+	t.Run("GetByID", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		member := team.Members[0]
+
+		user, err := repos.Users.GetByID(ctx, member.Id)
+		assertNoError(t, err, "Get user")
+		assertEqual(t, member.Id, user.Id, "User id")
+		assertEqual(t, member.Name, user.Name, "User name")
+		assertEqual(t, team.Name, user.Team, "User team")
+		assertEqual(t, true, user.IsActive, "User activity")
+
+		_, err = repos.Users.GetByID(ctx, uniqueID("no-such-user"))
+		var userNotFoundErr *domain.UserNotFoundError
+		assertTrue(t, errors.As(err, &userNotFoundErr), "Missing user returns UserNotFoundError")
+	})
+
+	t.Run("SetIsActive", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		userID := team.Members[0].Id
+
+		user, err := repos.Users.SetIsActive(ctx, userID, false)
+		assertNoError(t, err, "Deactivate user")
+		assertEqual(t, false, user.IsActive, "Returned activity")
+
+		user, err = repos.Users.GetByID(ctx, userID)
+		assertNoError(t, err, "Get user")
+		assertEqual(t, false, user.IsActive, "Stored activity")
+
+		_, err = repos.Users.SetIsActive(ctx, uniqueID("no-such-user"), true)
+		assertTrue(t, err != nil, "Missing user returns an error")
+	})
+
+	t.Run("GetActiveTeamMembers skips excluded and inactive users", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		// Active members of other teams must not leak in
+		createTeam(t, ctx, repos, 1)
+
+		_, err := repos.Users.SetIsActive(ctx, team.Members[2].Id, false)
+		assertNoError(t, err, "Deactivate user")
+
+		members, err := repos.Users.GetActiveTeamMembers(ctx, team.Name, team.Members[0].Id)
+		assertNoError(t, err, "Get active members")
+
+		var ids []string
+		for _, member := range members {
+			assertEqual(t, team.Name, member.Team, "Member team")
+			assertEqual(t, true, member.IsActive, "Member activity")
+			ids = append(ids, member.Id)
+		}
+		// Ids are created in increasing order, so this is also the order by user_id
+		assertIDs(t, []string{team.Members[1].Id, team.Members[3].Id}, ids, "Active members ordered by id")
+
+		members, err = repos.Users.GetActiveTeamMembers(ctx, team.Name, "")
+		assertNoError(t, err, "Get active members without exclusion")
+		assertEqual(t, 3, len(members), "Active members count")
+	})
+
+	t.Run("GetActiveTeamMembers skips users unavailable now", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 4)
+		now := time.Now()
+
+		periods := []domain.Unavailability{
+			{UserID: team.Members[0].Id, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+			// Periods that are over or have not started yet do not count
+			{UserID: team.Members[1].Id, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+			{UserID: team.Members[2].Id, StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)},
+		}
+		for _, period := range periods {
+			_, err := repos.Unavailability.Create(ctx, &period)
+			assertNoError(t, err, "Create unavailability")
+		}
+
+		members, err := repos.Users.GetActiveTeamMembers(ctx, team.Name, "")
+		assertNoError(t, err, "Get active members")
+
+		var ids []string
+		for _, member := range members {
+			ids = append(ids, member.Id)
+		}
+		assertIDs(t, []string{team.Members[1].Id, team.Members[2].Id, team.Members[3].Id}, ids, "Available members ordered by id")
+	})
+
+	t.Run("SetMaxOpenReviews", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		userID := team.Members[0].Id
+
+		limit := 3
+		user, err := repos.Users.SetMaxOpenReviews(ctx, userID, &limit)
+		assertNoError(t, err, "Set review limit")
+		assertTrue(t, user.MaxOpenReviews != nil, "Review limit is returned")
+		assertEqual(t, 3, *user.MaxOpenReviews, "Returned review limit")
+
+		user, err = repos.Users.GetByID(ctx, userID)
+		assertNoError(t, err, "Get user")
+		assertTrue(t, user.MaxOpenReviews != nil, "Review limit is stored")
+		assertEqual(t, 3, *user.MaxOpenReviews, "Stored review limit")
+
+		user, err = repos.Users.SetMaxOpenReviews(ctx, userID, nil)
+		assertNoError(t, err, "Clear review limit")
+		assertTrue(t, user.MaxOpenReviews == nil, "Review limit is cleared")
+
+		_, err = repos.Users.SetMaxOpenReviews(ctx, uniqueID("no-such-user"), &limit)
+		var userNotFoundErr *domain.UserNotFoundError
+		assertTrue(t, errors.As(err, &userNotFoundErr), "Missing user returns UserNotFoundError")
+	})
+
+	t.Run("DeactivateUsers", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 3)
+
+		users, err := repos.Users.DeactivateUsers(ctx, []string{team.Members[0].Id, team.Members[1].Id, uniqueID("no-such-user")})
+		assertNoError(t, err, "Deactivate users")
+		assertEqual(t, 2, len(users), "Unknown users are ignored")
+		for _, user := range users {
+			assertEqual(t, false, user.IsActive, "Returned activity")
+		}
+
+		members, err := repos.Users.GetActiveTeamMembers(ctx, team.Name, "")
+		assertNoError(t, err, "Get active members")
+		assertEqual(t, 1, len(members), "Active members count")
+		assertEqual(t, team.Members[2].Id, members[0].Id, "Remaining active member")
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		user := &domain.User{Id: uniqueID("new"), Name: "New User", Team: team.Name, IsActive: true}
+
+		created, err := repos.Users.Create(ctx, user)
+		assertNoError(t, err, "Create user")
+		assertEqual(t, user.Id, created.Id, "Created user id")
+		assertEqual(t, team.Name, created.Team, "Created user team")
+
+		_, err = repos.Users.Create(ctx, user)
+		var userExistsErr *domain.UserExistsError
+		assertTrue(t, errors.As(err, &userExistsErr), "Duplicate user returns UserExistsError")
+
+		_, err = repos.Users.Create(ctx, &domain.User{Id: uniqueID("new"), Name: "New User", Team: uniqueID("no-such-team")})
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Missing team returns TeamNotFoundError")
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		other := createTeam(t, ctx, repos, 0)
+		existing := team.Members[0]
+		limit := 2
+		_, err := repos.Users.SetMaxOpenReviews(ctx, existing.Id, &limit)
+		assertNoError(t, err, "Set review limit")
+
+		user, created, err := repos.Users.Upsert(ctx, &domain.User{Id: existing.Id, Name: "Renamed", Team: other.Name, IsActive: false})
+		assertNoError(t, err, "Upsert existing user")
+		assertEqual(t, false, created, "Existing user is updated")
+		assertEqual(t, "Renamed", user.Name, "Updated name")
+		assertEqual(t, other.Name, user.Team, "Updated team")
+		assertEqual(t, false, user.IsActive, "Updated activity")
+		assertTrue(t, user.MaxOpenReviews != nil && *user.MaxOpenReviews == 2, "Review limit is kept")
+
+		newID := uniqueID("new")
+		user, created, err = repos.Users.Upsert(ctx, &domain.User{Id: newID, Name: "New User", Team: other.Name, IsActive: true})
+		assertNoError(t, err, "Upsert new user")
+		assertEqual(t, true, created, "New user is created")
+		assertEqual(t, newID, user.Id, "Created user id")
+
+		_, _, err = repos.Users.Upsert(ctx, &domain.User{Id: uniqueID("new"), Name: "New User", Team: uniqueID("no-such-team")})
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Missing team returns TeamNotFoundError")
+	})
+
+	t.Run("SetTeam", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 1)
+		other := createTeam(t, ctx, repos, 0)
+		userID := team.Members[0].Id
+
+		user, err := repos.Users.SetTeam(ctx, userID, other.Name)
+		assertNoError(t, err, "Move user")
+		assertEqual(t, other.Name, user.Team, "Returned team")
+
+		found, err := repos.Teams.Get(ctx, other.Name)
+		assertNoError(t, err, "Get team")
+		assertEqual(t, 1, len(found.Members), "Moved user is a member")
+
+		_, err = repos.Users.SetTeam(ctx, userID, uniqueID("no-such-team"))
+		var teamNotFoundErr *domain.TeamNotFoundError
+		assertTrue(t, errors.As(err, &teamNotFoundErr), "Missing team returns TeamNotFoundError")
+
+		_, err = repos.Users.SetTeam(ctx, uniqueID("no-such-user"), other.Name)
+		var userNotFoundErr *domain.UserNotFoundError
+		assertTrue(t, errors.As(err, &userNotFoundErr), "Missing user returns UserNotFoundError")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepos(t)
+		team := createTeam(t, ctx, repos, 2)
+		author := team.Members[0].Id
+		createPR(t, ctx, repos, author, []string{}, time.Now())
+
+		err := repos.Users.Delete(ctx, author)
+		var hasPRsErr *domain.UserHasPullRequestsError
+		assertTrue(t, errors.As(err, &hasPRsErr), "Author returns UserHasPullRequestsError")
+
+		err = repos.Users.Delete(ctx, team.Members[1].Id)
+		assertNoError(t, err, "Delete user")
+
+		err = repos.Users.Delete(ctx, team.Members[1].Id)
+		var userNotFoundErr *domain.UserNotFoundError
+		assertTrue(t, errors.As(err, &userNotFoundErr), "Deleted user returns UserNotFoundError")
+	})
+}
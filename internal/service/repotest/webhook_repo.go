@@ -0,0 +1,262 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+func testWebhookRepository(t *testing.T, newRepos NewRepositories) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	createSubscription := func(t *testing.T, repos service.Repositories, events ...domain.EventType) *domain.WebhookSubscription {
+		subscription, err := repos.Webhooks.Create(ctx, &domain.WebhookSubscription{
+			URL:       "https://example.com/" + uniqueID("hook"),
+			Secret:    "secret",
+			Events:    events,
+			TeamName:  uniqueID("webhook-team"),
+			Active:    true,
+			CreatedAt: now,
+		})
+		assertNoError(t, err, "Create subscription")
+		return subscription
+	}
+
+	createDelivery := func(t *testing.T, repos service.Repositories, subscriptionID int64, nextAttemptAt time.Time) *domain.WebhookDelivery {
+		delivery, err := repos.Deliveries.Create(ctx, &domain.WebhookDelivery{
+			SubscriptionID: subscriptionID,
+			EventType:      domain.EventReviewerAssigned,
+			Payload:        json.RawMessage(`{"event":"REVIEWER_ASSIGNED","user_id":"u1"}`),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  nextAttemptAt,
+			CreatedAt:      now,
+		})
+		assertNoError(t, err, "Create delivery")
+		return delivery
+	}
+
+	t.Run("Create, Get and Update", func(t *testing.T) {
+		repos := newRepos(t)
+
+		created := createSubscription(t, repos, domain.EventReviewerAssigned, domain.EventPullRequestMerged)
+		assertTrue(t, created.ID > 0, "Subscription gets an id")
+		assertIDs(t, []string{"REVIEWER_ASSIGNED", "PULL_REQUEST_MERGED"}, eventStrings(created.Events), "Created events")
+
+		found, err := repos.Webhooks.Get(ctx, created.ID)
+		assertNoError(t, err, "Get subscription")
+		assertEqual(t, created.URL, found.URL, "Found URL")
+		assertEqual(t, "secret", found.Secret, "Found secret")
+		assertEqual(t, created.TeamName, found.TeamName, "Found team")
+		assertTrue(t, found.Active, "Found subscription is active")
+		assertTrue(t, found.CreatedAt.Equal(now), "Found creation time")
+		assertIDs(t, eventStrings(created.Events), eventStrings(found.Events), "Found events")
+
+		found.URL = "https://example.com/moved"
+		found.Secret = "rotated"
+		found.Events = nil
+		found.TeamName = ""
+		found.Active = false
+		updated, err := repos.Webhooks.Update(ctx, found)
+		assertNoError(t, err, "Update subscription")
+		assertEqual(t, "https://example.com/moved", updated.URL, "Updated URL")
+		assertEqual(t, "rotated", updated.Secret, "Updated secret")
+		assertEqual(t, "", updated.TeamName, "Updated team")
+		assertEqual(t, false, updated.Active, "Updated activity")
+		assertTrue(t, updated.Events != nil && len(updated.Events) == 0, "No events means all of them and is never nil")
+		assertTrue(t, updated.CreatedAt.Equal(now), "Creation time is kept")
+
+		_, err = repos.Webhooks.Update(ctx, &domain.WebhookSubscription{ID: created.ID + 1000000, URL: "https://example.com"})
+		var notFoundErr *domain.WebhookNotFoundError
+		assertTrue(t, errors.As(err, &notFoundErr), "Updating a missing subscription returns WebhookNotFoundError")
+
+		_, err = repos.Webhooks.Get(ctx, created.ID+1000000)
+		assertTrue(t, errors.As(err, &notFoundErr), "Getting a missing subscription returns WebhookNotFoundError")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := newRepos(t)
+		first := createSubscription(t, repos)
+		second := createSubscription(t, repos)
+
+		subscriptions, err := repos.Webhooks.List(ctx)
+		assertNoError(t, err, "List subscriptions")
+		// The storage may hold subscriptions of other tests
+		var ids []int64
+		for _, subscription := range subscriptions {
+			if subscription.ID == first.ID || subscription.ID == second.ID {
+				ids = append(ids, subscription.ID)
+			}
+		}
+		assertEqual(t, fmt.Sprint([]int64{first.ID, second.ID}), fmt.Sprint(ids), "Subscriptions are ordered by id")
+	})
+
+	t.Run("Deliveries and attempts", func(t *testing.T) {
+		repos := newRepos(t)
+		subscription := createSubscription(t, repos)
+
+		delivery := createDelivery(t, repos, subscription.ID, now)
+		assertTrue(t, delivery.ID > 0, "Delivery gets an id")
+		assertEqual(t, domain.WebhookDeliveryPending, delivery.Status, "New delivery is pending")
+		assertTrue(t, delivery.DeliveredAt == nil, "New delivery is not delivered")
+		assertJSON(t, `{"event":"REVIEWER_ASSIGNED","user_id":"u1"}`, delivery.Payload, "Payload")
+
+		delivery.Attempts = 1
+		delivery.LastError = "unexpected response status 500"
+		delivery.NextAttemptAt = now.Add(time.Minute)
+		err := repos.Deliveries.RecordAttempt(ctx, delivery, &domain.WebhookAttempt{
+			Attempt:     1,
+			StatusCode:  500,
+			Error:       "unexpected response status 500",
+			AttemptedAt: now,
+		})
+		assertNoError(t, err, "Record failed attempt")
+
+		deliveredAt := now.Add(time.Minute)
+		delivery.Attempts = 2
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &deliveredAt
+		err = repos.Deliveries.RecordAttempt(ctx, delivery, &domain.WebhookAttempt{
+			Attempt:     2,
+			StatusCode:  204,
+			AttemptedAt: deliveredAt,
+		})
+		assertNoError(t, err, "Record successful attempt")
+
+		second := createDelivery(t, repos, subscription.ID, now)
+
+		logs, err := repos.Deliveries.GetBySubscription(ctx, subscription.ID, 10)
+		assertNoError(t, err, "Get deliveries")
+		assertEqual(t, 2, len(logs), "Deliveries count")
+		assertEqual(t, second.ID, logs[0].Delivery.ID, "Latest delivery goes first")
+		assertEqual(t, 0, len(logs[0].Attempts), "Second delivery has no attempts")
+
+		logged := logs[1]
+		assertEqual(t, domain.WebhookDeliveryDelivered, logged.Delivery.Status, "Delivered status")
+		assertEqual(t, 2, logged.Delivery.Attempts, "Attempts count")
+		assertEqual(t, "", logged.Delivery.LastError, "Last error is cleared")
+		assertTrue(t, logged.Delivery.DeliveredAt != nil && logged.Delivery.DeliveredAt.Equal(deliveredAt), "Delivery time")
+		assertEqual(t, 2, len(logged.Attempts), "Logged attempts")
+		assertEqual(t, 500, logged.Attempts[0].StatusCode, "First attempt status")
+		assertEqual(t, "unexpected response status 500", logged.Attempts[0].Error, "First attempt error")
+		assertEqual(t, 2, logged.Attempts[1].Attempt, "Second attempt number")
+		assertTrue(t, logged.Attempts[1].AttemptedAt.Equal(deliveredAt), "Second attempt time")
+
+		logs, err = repos.Deliveries.GetBySubscription(ctx, subscription.ID, 1)
+		assertNoError(t, err, "Get deliveries with a limit")
+		assertEqual(t, 1, len(logs), "Limited deliveries count")
+
+		_, err = repos.Deliveries.Create(ctx, &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID + 1000000,
+			EventType:      domain.EventReviewerAssigned,
+			Payload:        json.RawMessage(`{}`),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+		var notFoundErr *domain.WebhookNotFoundError
+		assertTrue(t, errors.As(err, &notFoundErr), "Delivery to a missing subscription returns WebhookNotFoundError")
+	})
+
+	// claimed keeps the deliveries of subscription, the storage may hold
+	// deliveries of other tests
+	claimed := func(deliveries []domain.WebhookDelivery, subscription *domain.WebhookSubscription) []int64 {
+		var ids []int64
+		for _, delivery := range deliveries {
+			if delivery.SubscriptionID == subscription.ID {
+				ids = append(ids, delivery.ID)
+			}
+		}
+		return ids
+	}
+
+	t.Run("ClaimDue", func(t *testing.T) {
+		repos := newRepos(t)
+		subscription := createSubscription(t, repos)
+		at := now.Add(time.Hour)
+		until := at.Add(5 * time.Minute)
+
+		second := createDelivery(t, repos, subscription.ID, at.Add(-time.Minute))
+		first := createDelivery(t, repos, subscription.ID, at.Add(-time.Hour))
+		// The attempt time is inclusive
+		current := createDelivery(t, repos, subscription.ID, at)
+		later := createDelivery(t, repos, subscription.ID, at.Add(time.Minute))
+		failed := createDelivery(t, repos, subscription.ID, at.Add(-time.Hour))
+		failed.Status = domain.WebhookDeliveryFailed
+		failed.Attempts = 1
+		err := repos.Deliveries.RecordAttempt(ctx, failed, &domain.WebhookAttempt{Attempt: 1, Error: "refused", AttemptedAt: now})
+		assertNoError(t, err, "Fail delivery")
+
+		due, err := repos.Deliveries.ClaimDue(ctx, at, until, 1000)
+		assertNoError(t, err, "Claim due deliveries")
+		assertEqual(t, fmt.Sprint([]int64{first.ID, second.ID, current.ID}), fmt.Sprint(claimed(due, subscription)), "Due deliveries ordered by attempt time")
+		for _, delivery := range due {
+			assertTrue(t, delivery.ID != later.ID, "Later delivery is not due")
+			if delivery.SubscriptionID == subscription.ID {
+				assertTrue(t, delivery.NextAttemptAt.Equal(until), "Claimed delivery waits for the claim to run out")
+			}
+		}
+
+		due, err = repos.Deliveries.ClaimDue(ctx, at, until, 1000)
+		assertNoError(t, err, "Claim due deliveries again")
+		assertEqual(t, 0, len(claimed(due, subscription)), "Claimed deliveries are not claimed twice")
+
+		// Claimed deliveries share the attempt time and are ordered by id
+		due, err = repos.Deliveries.ClaimDue(ctx, until, until.Add(5*time.Minute), 1000)
+		assertNoError(t, err, "Claim due deliveries after the claim ran out")
+		assertEqual(t, fmt.Sprint([]int64{later.ID, second.ID, first.ID, current.ID}), fmt.Sprint(claimed(due, subscription)), "Deliveries are due again once the claim runs out")
+
+		// Deleting the subscription takes its deliveries along, so they
+		// are not due in later runs against the same database
+		err = repos.Webhooks.Delete(ctx, subscription.ID)
+		assertNoError(t, err, "Delete subscription")
+
+		due, err = repos.Deliveries.ClaimDue(ctx, until.Add(time.Hour), until.Add(time.Hour), 1000)
+		assertNoError(t, err, "Claim due deliveries after delete")
+		assertEqual(t, 0, len(claimed(due, subscription)), "Deliveries are deleted with the subscription")
+
+		err = repos.Webhooks.Delete(ctx, subscription.ID)
+		var notFoundErr *domain.WebhookNotFoundError
+		assertTrue(t, errors.As(err, &notFoundErr), "Deleting twice returns WebhookNotFoundError")
+	})
+
+	t.Run("ClaimDue skips inactive subscriptions", func(t *testing.T) {
+		repos := newRepos(t)
+		subscription := createSubscription(t, repos)
+		delivery := createDelivery(t, repos, subscription.ID, now)
+
+		subscription.Active = false
+		_, err := repos.Webhooks.Update(ctx, subscription)
+		assertNoError(t, err, "Deactivate subscription")
+
+		due, err := repos.Deliveries.ClaimDue(ctx, now, now.Add(time.Minute), 1000)
+		assertNoError(t, err, "Claim due deliveries")
+		assertEqual(t, 0, len(claimed(due, subscription)), "Deliveries of an inactive subscription are not claimed")
+
+		subscription.Active = true
+		_, err = repos.Webhooks.Update(ctx, subscription)
+		assertNoError(t, err, "Activate subscription")
+
+		due, err = repos.Deliveries.ClaimDue(ctx, now, now.Add(time.Minute), 1000)
+		assertNoError(t, err, "Claim due deliveries")
+		assertEqual(t, fmt.Sprint([]int64{delivery.ID}), fmt.Sprint(claimed(due, subscription)), "Deliveries wait for the subscription to be active again")
+
+		err = repos.Webhooks.Delete(ctx, subscription.ID)
+		assertNoError(t, err, "Delete subscription")
+	})
+}
+
+func eventStrings(events []domain.EventType) []string {
+	names := make([]string, 0, len(events))
+	for _, eventType := range events {
+		names = append(names, string(eventType))
+	}
+	return names
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// SyncRoster makes the listed teams match the roster. Missing teams are
+// created with the default strategy, listed users are created or updated
+// and active members of the listed teams that are missing from the roster
+// are deactivated, teams that are not listed are left as they are.
+// OPEN reviews of users that leave their team or become inactive are
+// handed over to the old teammates. With dryRun only the plan is returned.
+func (s *Service) SyncRoster(ctx context.Context, roster *domain.Roster, dryRun bool) (*domain.RosterSyncResult, error) {
+	if err := validateRoster(roster); err != nil {
+		return nil, err
+	}
+
+	var result *domain.RosterSyncResult
+	apply := func(repos Repositories) error {
+		teams, err := repos.Teams.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		result = &domain.RosterSyncResult{
+			Plan:         planRosterSync(teams, roster),
+			Replacements: []domain.ReviewerReplacement{},
+		}
+		if dryRun {
+			return nil
+		}
+
+		result.Replacements, err = s.applyRosterSync(ctx, repos, &result.Plan)
+		if err != nil {
+			return err
+		}
+		result.Applied = true
+		return nil
+	}
+	// A dry run changes nothing, so there is nothing to log
+	if !dryRun {
+		apply = audited(ctx, domain.AuditActionTeamSync, auditTarget{kind: domain.AuditTargetRoster}, apply)
+	}
+
+	err := s.withinTxRetry(ctx, apply)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) applyRosterSync(ctx context.Context, repos Repositories, plan *domain.RosterSyncPlan) ([]domain.ReviewerReplacement, error) {
+	for _, teamName := range plan.CreatedTeams {
+		_, err := repos.Teams.Create(ctx, &domain.Team{
+			Name: teamName,
+			Settings: domain.TeamSettings{
+				ReviewerStrategy: domain.ReviewerStrategyRandom,
+				ReviewerCount:    domain.DefaultReviewerCount,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, user := range plan.CreatedUsers {
+		if _, _, err := repos.Users.Upsert(ctx, &user); err != nil {
+			return nil, err
+		}
+	}
+
+	// Leaving users are grouped by the team whose members take over their reviews
+	leaving := map[string][]string{}
+	for _, change := range plan.UpdatedUsers {
+		if _, _, err := repos.Users.Upsert(ctx, &change.After); err != nil {
+			return nil, err
+		}
+
+		leftTeam := change.Before.Team != change.After.Team
+		deactivated := change.Before.IsActive && !change.After.IsActive
+		if leftTeam || deactivated {
+			leaving[change.Before.Team] = append(leaving[change.Before.Team], change.Before.Id)
+		}
+	}
+
+	if len(plan.DeactivatedUsers) > 0 {
+		userIDs := make([]string, 0, len(plan.DeactivatedUsers))
+		for _, user := range plan.DeactivatedUsers {
+			userIDs = append(userIDs, user.Id)
+			leaving[user.Team] = append(leaving[user.Team], user.Id)
+		}
+		if _, err := repos.Users.DeactivateUsers(ctx, userIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	replacements := []domain.ReviewerReplacement{}
+	teamNames := make([]string, 0, len(leaving))
+	for teamName := range leaving {
+		teamNames = append(teamNames, teamName)
+	}
+	slices.Sort(teamNames)
+	for _, teamName := range teamNames {
+		teamReplacements, err := s.handOverReviews(ctx, repos, domain.AssignmentCauseDeactivation, teamName, leaving[teamName])
+		if err != nil {
+			return nil, err
+		}
+		replacements = append(replacements, teamReplacements...)
+	}
+
+	return replacements, nil
+}
+
+// planRosterSync diffs the roster against the stored teams. Created and
+// updated users follow the roster order, deactivated ones the stored order.
+func planRosterSync(teams []domain.Team, roster *domain.Roster) domain.RosterSyncPlan {
+	plan := domain.RosterSyncPlan{
+		CreatedTeams:     []string{},
+		CreatedUsers:     []domain.User{},
+		UpdatedUsers:     []domain.RosterUserChange{},
+		DeactivatedUsers: []domain.User{},
+	}
+
+	storedTeams := make(map[string]bool, len(teams))
+	storedUsers := map[string]domain.User{}
+	for _, team := range teams {
+		storedTeams[team.Name] = true
+		for _, member := range team.Members {
+			storedUsers[member.Id] = member
+		}
+	}
+
+	listed := map[string]bool{}
+	for _, team := range roster.Teams {
+		if !storedTeams[team.Name] {
+			plan.CreatedTeams = append(plan.CreatedTeams, team.Name)
+		}
+
+		for _, member := range team.Members {
+			listed[member.Id] = true
+			wanted := domain.User{Id: member.Id, Name: member.Name, Team: team.Name, IsActive: member.IsActive}
+
+			stored, exists := storedUsers[member.Id]
+			if !exists {
+				plan.CreatedUsers = append(plan.CreatedUsers, wanted)
+				continue
+			}
+
+			wanted.MaxOpenReviews = stored.MaxOpenReviews
+			if stored.Name != wanted.Name || stored.Team != wanted.Team || stored.IsActive != wanted.IsActive {
+				plan.UpdatedUsers = append(plan.UpdatedUsers, domain.RosterUserChange{Before: stored, After: wanted})
+			}
+		}
+	}
+
+	syncedTeams := make(map[string]bool, len(roster.Teams))
+	for _, team := range roster.Teams {
+		syncedTeams[team.Name] = true
+	}
+	for _, team := range teams {
+		if !syncedTeams[team.Name] {
+			continue
+		}
+		for _, member := range team.Members {
+			if !listed[member.Id] && member.IsActive {
+				plan.DeactivatedUsers = append(plan.DeactivatedUsers, member)
+			}
+		}
+	}
+
+	return plan
+}
+
+func validateRoster(roster *domain.Roster) error {
+	teams := map[string]bool{}
+	users := map[string]string{}
+	for _, team := range roster.Teams {
+		if team.Name == "" {
+			return &domain.InvalidRosterError{Reason: "team_name must not be empty"}
+		}
+		if teams[team.Name] {
+			return &domain.InvalidRosterError{Reason: fmt.Sprintf("team %s is listed twice", team.Name)}
+		}
+		teams[team.Name] = true
+
+		for _, member := range team.Members {
+			if member.Id == "" || member.Name == "" {
+				return &domain.InvalidRosterError{Reason: fmt.Sprintf("members of team %s need user_id and username", team.Name)}
+			}
+			if otherTeam, ok := users[member.Id]; ok {
+				return &domain.InvalidRosterError{Reason: fmt.Sprintf("user %s is listed in %s and %s", member.Id, otherTeam, team.Name)}
+			}
+			users[member.Id] = team.Name
+		}
+	}
+
+	return nil
+}
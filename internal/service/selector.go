@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// ReviewerSelector picks up to count reviewers out of candidates.
+// Candidates are already filtered: active, available, not the author, not
+// assigned yet and below their MaxOpenReviews. repos belong to the running
+// transaction, so reviewers picked earlier in it are visible.
+type ReviewerSelector interface {
+	Select(ctx context.Context, repos Repositories, teamName string, candidates []domain.User, count int) ([]domain.User, error)
+}
+
+func DefaultSelectors() map[domain.ReviewerStrategy]ReviewerSelector {
+	return map[domain.ReviewerStrategy]ReviewerSelector{
+		domain.ReviewerStrategyRandom:      &RandomSelector{},
+		domain.ReviewerStrategyRoundRobin:  NewRoundRobinSelector(),
+		domain.ReviewerStrategyLeastLoaded: &LeastLoadedSelector{},
+	}
+}
+
+type RandomSelector struct{}
+
+func (r *RandomSelector) Select(ctx context.Context, repos Repositories, teamName string, candidates []domain.User, count int) ([]domain.User, error) {
+	shuffled := slices.Clone(candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled[:min(len(shuffled), count)], nil
+}
+
+// RoundRobinSelector keeps a cursor per team and hands out candidates
+// one after another, wrapping around the candidate list.
+type RoundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{cursors: make(map[string]int)}
+}
+
+func (r *RoundRobinSelector) Select(ctx context.Context, repos Repositories, teamName string, candidates []domain.User, count int) ([]domain.User, error) {
+	if len(candidates) == 0 {
+		return []domain.User{}, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := r.cursors[teamName] % len(candidates)
+	selected := make([]domain.User, 0, min(len(candidates), count))
+	for i := range min(len(candidates), count) {
+		selected = append(selected, candidates[(start+i)%len(candidates)])
+	}
+	r.cursors[teamName] = start + len(selected)
+
+	return selected, nil
+}
+
+// LeastLoadedSelector balances the workload: candidates are ranked by the
+// number of OPEN reviews they already have. Ties are broken randomly so the
+// same people are not always picked first. The load is counted in the transaction, so
+// reviews handed out earlier in it count as well.
+type LeastLoadedSelector struct{}
+
+func (l *LeastLoadedSelector) Select(ctx context.Context, repos Repositories, teamName string, candidates []domain.User, count int) ([]domain.User, error) {
+	if len(candidates) == 0 {
+		return []domain.User{}, nil
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.Id)
+	}
+
+	load, err := repos.PullRequests.CountOpenReviews(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	available := slices.Clone(candidates)
+	rand.Shuffle(len(available), func(i, j int) {
+		available[i], available[j] = available[j], available[i]
+	})
+	slices.SortStableFunc(available, func(a, b domain.User) int {
+		return load[a.Id] - load[b.Id]
+	})
+
+	return available[:min(len(available), count)], nil
+}
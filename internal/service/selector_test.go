@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// newTeam stores a LEAST_LOADED team with active members ids.
+func newTeam(t *testing.T, ctx context.Context, repos service.Repositories, name string, ids ...string) {
+	team := &domain.Team{
+		Name: name,
+		Settings: domain.TeamSettings{
+			ReviewerStrategy: domain.ReviewerStrategyLeastLoaded,
+			ReviewerCount:    domain.DefaultReviewerCount,
+		},
+	}
+	for _, id := range ids {
+		team.Members = append(team.Members, domain.User{Id: id, Name: id, Team: name, IsActive: true})
+	}
+	if _, err := repos.Teams.Create(ctx, team); err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+}
+
+// newPR stores an OPEN pull request of the author with reviewers.
+func newPR(t *testing.T, ctx context.Context, repos service.Repositories, id, authorID string, reviewers ...string) {
+	createdAt := time.Now()
+	_, err := repos.PullRequests.Create(ctx, &domain.PullRequest{
+		ID:                id,
+		Name:              id,
+		AuthorID:          authorID,
+		Status:            domain.PullRequestStatusOpen,
+		AssignedReviewers: reviewers,
+		CreatedAt:         &createdAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+}
+
+func openReviews(t *testing.T, ctx context.Context, repos service.Repositories, ids ...string) map[string]int {
+	load, err := repos.PullRequests.CountOpenReviews(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to count open reviews: %v", err)
+	}
+	return load
+}
+
+func TestLeastLoadedSelectorSeesPicksOfTheTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+
+	newTeam(t, ctx, repos, "backend", "author", "b", "c")
+	newPR(t, ctx, repos, "pr-c", "author", "c")
+	candidates, err := repos.Users.GetActiveTeamMembers(ctx, "backend", "author")
+	if err != nil {
+		t.Fatalf("Failed to get candidates: %v", err)
+	}
+
+	selector := &service.LeastLoadedSelector{}
+	err = memory.NewTxManager(store).WithinTx(ctx, func(repos service.Repositories) error {
+		for i := range 3 {
+			picked, err := selector.Select(ctx, repos, "backend", candidates, 1)
+			if err != nil {
+				return err
+			}
+			newPR(t, ctx, repos, fmt.Sprintf("pr-%d", i), "author", picked[0].Id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	load := openReviews(t, ctx, repos, "b", "c")
+	if load["b"] != 2 || load["c"] != 2 {
+		t.Fatalf("Reviews are not balanced within the transaction: %v", load)
+	}
+}
+
+func TestDeactivationSpreadsReviewsByLoad(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	srv := service.CreateService(repos, memory.NewStatsRepo(store), memory.NewTxManager(store))
+
+	newTeam(t, ctx, repos, "backend", "author", "x", "b", "c")
+	for i := range 4 {
+		newPR(t, ctx, repos, fmt.Sprintf("pr-x-%d", i), "author", "x")
+	}
+	newPR(t, ctx, repos, "pr-c", "author", "c")
+
+	if _, err := srv.DeactivateTeamMembers(ctx, "backend", []string{"x"}); err != nil {
+		t.Fatalf("Deactivation failed: %v", err)
+	}
+
+	load := openReviews(t, ctx, repos, "x", "b", "c")
+	if load["x"] != 0 || load["b"]+load["c"] != 5 || max(load["b"]-load["c"], load["c"]-load["b"]) > 1 {
+		t.Fatalf("Reviews of x are not spread by load: %v", load)
+	}
+}
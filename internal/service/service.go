@@ -2,112 +2,1090 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/raccoon00/avito-pr/internal/domain"
 )
 
+// maxConflictRetries bounds how many times an operation is retried
+// when a pull request was changed concurrently.
+const maxConflictRetries = 3
+
 type Service struct {
-	TeamRepo TeamRepository
-	UserRepo UserRepository
-	PRRepo   PullRequestRepository
+	TeamRepo   TeamRepository
+	UserRepo   UserRepository
+	PRRepo     PullRequestRepository
+	ReviewRepo ReviewRepository
+	// ReviewerHistoryRepo reads the reviewer history, it is written inside transactions
+	ReviewerHistoryRepo ReviewerHistoryRepository
+	UnavailabilityRepo  UnavailabilityRepository
+	// AssignmentRepo reads the assignment history, it is written inside transactions
+	AssignmentRepo AssignmentHistoryRepository
+	// AuditRepo reads the audit log, it is written inside transactions
+	AuditRepo   AuditLogRepository
+	WebhookRepo WebhookRepository
+	// DeliveryRepo reads the delivery log, deliveries are queued by Events
+	DeliveryRepo WebhookDeliveryRepository
+	StatsRepo    StatsRepository
+	TxManager    TxManager
+	Selectors    map[domain.ReviewerStrategy]ReviewerSelector
+	// Events receives the events of pull request changes
+	Events EventPublisher
+	// WebhookSender is needed by DeliverWebhooks only
+	WebhookSender WebhookSender
+	WebhookRetry  WebhookRetryPolicy
+}
+
+func CreateService(
+	repos Repositories,
+	statsRepo StatsRepository,
+	txManager TxManager,
+) *Service {
+	return &Service{
+		TeamRepo:            repos.Teams,
+		UserRepo:            repos.Users,
+		PRRepo:              repos.PullRequests,
+		ReviewRepo:          repos.Reviews,
+		ReviewerHistoryRepo: repos.ReviewerHistory,
+		UnavailabilityRepo:  repos.Unavailability,
+		AssignmentRepo:      repos.Assignments,
+		AuditRepo:           repos.Audit,
+		WebhookRepo:         repos.Webhooks,
+		DeliveryRepo:        repos.Deliveries,
+		StatsRepo:           statsRepo,
+		TxManager:           txManager,
+		Selectors:           DefaultSelectors(),
+		Events:              WebhookPublisher{},
+		WebhookRetry:        DefaultWebhookRetryPolicy(),
+	}
+}
+
+// AddTeam creates the team and its members. Members that already exist
+// in another team are moved to the new one and their OPEN reviews are
+// handed over to the old teammates.
+func (s *Service) AddTeam(ctx context.Context, team *domain.Team) (*domain.TeamAddResult, error) {
+	if team.Settings.ReviewerStrategy == "" {
+		team.Settings.ReviewerStrategy = domain.ReviewerStrategyRandom
+	}
+	if _, ok := s.Selectors[team.Settings.ReviewerStrategy]; !ok {
+		return nil, &domain.UnknownReviewerStrategyError{Strategy: team.Settings.ReviewerStrategy}
+	}
+	if team.Settings.ReviewerCount == 0 {
+		team.Settings.ReviewerCount = domain.DefaultReviewerCount
+	}
+	if err := validateReviewerCount(team.Settings.ReviewerCount); err != nil {
+		return nil, err
+	}
+
+	var result *domain.TeamAddResult
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionTeamAdd, teamTarget(team.Name), func(repos Repositories) error {
+		insertedTeam, err := repos.Teams.Create(ctx, &domain.Team{Name: team.Name, Settings: team.Settings})
+		if err != nil {
+			return err
+		}
+
+		result = &domain.TeamAddResult{
+			Team:           *insertedTeam,
+			CreatedUserIDs: []string{},
+			UpdatedUserIDs: []string{},
+			Replacements:   []domain.ReviewerReplacement{},
+		}
+		for _, member := range team.Members {
+			member.Team = insertedTeam.Name
+			// The team is new, so existing members always leave their old
+			// one and its members take over the reviews before the move
+			stored, err := repos.Users.GetByID(ctx, member.Id)
+			if err == nil {
+				replacements, err := s.handOverReviews(ctx, repos, domain.AssignmentCauseDeactivation, stored.Team, []string{member.Id})
+				if err != nil {
+					return err
+				}
+				result.Replacements = append(result.Replacements, replacements...)
+			}
+
+			user, created, err := repos.Users.Upsert(ctx, &member)
+			if err != nil {
+				return err
+			}
+
+			result.Team.Members = append(result.Team.Members, *user)
+			if created {
+				result.CreatedUserIDs = append(result.CreatedUserIDs, user.Id)
+			} else {
+				result.UpdatedUserIDs = append(result.UpdatedUserIDs, user.Id)
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) SetTeamReviewerStrategy(ctx context.Context, teamName string, strategy domain.ReviewerStrategy) (*domain.TeamSettings, error) {
+	if _, ok := s.Selectors[strategy]; !ok {
+		return nil, &domain.UnknownReviewerStrategyError{Strategy: strategy}
+	}
+
+	var updatedSettings *domain.TeamSettings
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamSetReviewerStrategy, teamTarget(teamName), func(repos Repositories) error {
+		settings, err := repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		settings.ReviewerStrategy = strategy
+		updatedSettings, err = repos.Teams.UpdateSettings(ctx, teamName, settings)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSettings, nil
+}
+
+// SetTeamReviewerCount changes how many reviewers are assigned to new pull
+// requests of the team. Reviewers of existing pull requests are kept.
+func (s *Service) SetTeamReviewerCount(ctx context.Context, teamName string, count int) (*domain.TeamSettings, error) {
+	if err := validateReviewerCount(count); err != nil {
+		return nil, err
+	}
+
+	var updatedSettings *domain.TeamSettings
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamSetReviewerCount, teamTarget(teamName), func(repos Repositories) error {
+		settings, err := repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		if settings.MergePolicy.MinApprovals > count {
+			return &domain.InvalidReviewerCountError{
+				Count:  count,
+				Reason: fmt.Sprintf("the merge policy needs %d approvals", settings.MergePolicy.MinApprovals),
+			}
+		}
+
+		settings.ReviewerCount = count
+		updatedSettings, err = repos.Teams.UpdateSettings(ctx, teamName, settings)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSettings, nil
+}
+
+func validateReviewerCount(count int) error {
+	if count < 1 || count > domain.MaxReviewerCount {
+		return &domain.InvalidReviewerCountError{
+			Count:  count,
+			Reason: fmt.Sprintf("must be between 1 and %d", domain.MaxReviewerCount),
+		}
+	}
+	return nil
+}
+
+// SetTeamMergePolicy replaces the merge policy of the team.
+// Leads have to be members of the team.
+func (s *Service) SetTeamMergePolicy(ctx context.Context, teamName string, policy domain.MergePolicy) (*domain.TeamSettings, error) {
+	if policy.MinApprovals < 0 {
+		return nil, &domain.InvalidMergePolicyError{Reason: fmt.Sprintf("min approvals must not be negative, got %d", policy.MinApprovals)}
+	}
+
+	var updatedSettings *domain.TeamSettings
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamSetMergePolicy, teamTarget(teamName), func(repos Repositories) error {
+		// The reviewer count must not change until the policy is saved
+		settings, err := repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		team, err := repos.Teams.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		if policy.MinApprovals > settings.ReviewerCount {
+			return &domain.InvalidMergePolicyError{
+				Reason: fmt.Sprintf("min approvals %d exceed the %d reviewers of the team", policy.MinApprovals, settings.ReviewerCount),
+			}
+		}
+
+		for _, leadID := range policy.LeadIDs {
+			if !isTeamMember(team, leadID) {
+				return &domain.UserNotInTeamError{UserID: leadID, TeamName: teamName}
+			}
+		}
+
+		settings.MergePolicy = policy
+		updatedSettings, err = repos.Teams.UpdateSettings(ctx, teamName, settings)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSettings, nil
+}
+
+// SetTeamFallbackTeams replaces the teams asked for reviewers when teamName
+// runs out of candidates. Fallback teams have to exist and are not followed
+// further, their own fallbacks are ignored.
+func (s *Service) SetTeamFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) (*domain.TeamSettings, error) {
+	var updatedSettings *domain.TeamSettings
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamSetFallbackTeams, teamTarget(teamName), func(repos Repositories) error {
+		settings, err := repos.Teams.GetSettingsForUpdate(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		for i, fallbackTeam := range fallbackTeams {
+			if fallbackTeam == teamName {
+				return &domain.InvalidFallbackTeamsError{TeamName: teamName, Reason: "a team can't fall back to itself"}
+			}
+			if slices.Contains(fallbackTeams[:i], fallbackTeam) {
+				return &domain.InvalidFallbackTeamsError{TeamName: teamName, Reason: fmt.Sprintf("%s is listed twice", fallbackTeam)}
+			}
+
+			_, err := repos.Teams.GetSettings(ctx, fallbackTeam)
+			var teamNotFoundErr *domain.TeamNotFoundError
+			if errors.As(err, &teamNotFoundErr) {
+				return &domain.InvalidFallbackTeamsError{TeamName: teamName, Reason: err.Error()}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		settings.FallbackTeams = fallbackTeams
+		updatedSettings, err = repos.Teams.UpdateSettings(ctx, teamName, settings)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSettings, nil
+}
+
+func (s *Service) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+	team, err := s.TeamRepo.Get(ctx, teamName)
+	return team, err
+}
+
+func (s *Service) SetUserIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	var user *domain.User
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionUserSetIsActive, userTarget(userID), func(repos Repositories) error {
+		var err error
+		user, err = repos.Users.SetIsActive(ctx, userID, isActive)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *Service) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews *int) (*domain.User, error) {
+	if maxOpenReviews != nil && *maxOpenReviews < 0 {
+		return nil, &domain.InvalidReviewLimitError{Limit: *maxOpenReviews}
+	}
+
+	var user *domain.User
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionUserSetReviewLimit, userTarget(userID), func(repos Repositories) error {
+		var err error
+		user, err = repos.Users.SetMaxOpenReviews(ctx, userID, maxOpenReviews)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeactivateTeamMembers deactivates the given team members and moves their
+// OPEN reviews to other active teammates following the ReassignReviewer rules.
+// A review slot is dropped when nobody is left to take it.
+func (s *Service) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string) (*domain.TeamDeactivationResult, error) {
+	var result *domain.TeamDeactivationResult
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionTeamDeactivateMembers, teamTarget(teamName), func(repos Repositories) error {
+		var err error
+		result, err = s.deactivateTeamMembers(ctx, repos, teamName, userIDs)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) deactivateTeamMembers(ctx context.Context, repos Repositories, teamName string, userIDs []string) (*domain.TeamDeactivationResult, error) {
+	team, err := repos.Teams.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		if !isTeamMember(team, userID) {
+			return nil, &domain.UserNotInTeamError{UserID: userID, TeamName: teamName}
+		}
+	}
+
+	users, err := repos.Users.DeactivateUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	replacements, err := s.handOverReviews(ctx, repos, domain.AssignmentCauseDeactivation, teamName, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TeamDeactivationResult{
+		TeamName:     teamName,
+		Deactivated:  users,
+		Replacements: replacements,
+	}, nil
+}
+
+func (s *Service) AddTeamMember(ctx context.Context, user *domain.User) (*domain.User, error) {
+	var createdUser *domain.User
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamAddMember, teamTarget(user.Team), func(repos Repositories) error {
+		var err error
+		createdUser, err = repos.Users.Create(ctx, user)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return createdUser, nil
+}
+
+// RemoveTeamMember deletes a user that has not authored any pull request,
+// users with authored PRs are kept for the history and should be deactivated.
+// OPEN reviews of the user are handed over to the remaining teammates.
+func (s *Service) RemoveTeamMember(ctx context.Context, teamName string, userID string) (*domain.TeamMembershipResult, error) {
+	var result *domain.TeamMembershipResult
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionTeamRemoveMember, teamTarget(teamName), func(repos Repositories) error {
+		team, err := repos.Teams.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		memberIdx := slices.IndexFunc(team.Members, func(member domain.User) bool {
+			return member.Id == userID
+		})
+		if memberIdx < 0 {
+			return &domain.UserNotInTeamError{UserID: userID, TeamName: teamName}
+		}
+
+		hasPRs, err := repos.PullRequests.ExistsByAuthor(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if hasPRs {
+			return &domain.UserHasPullRequestsError{UserID: userID}
+		}
+
+		replacements, err := s.handOverReviews(ctx, repos, domain.AssignmentCauseDeactivation, teamName, []string{userID})
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Users.Delete(ctx, userID); err != nil {
+			return err
+		}
+
+		result = &domain.TeamMembershipResult{
+			User:         team.Members[memberIdx],
+			FromTeam:     teamName,
+			Replacements: replacements,
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MoveTeamMember moves a user to another team. Reviewers are picked from
+// the author's team, so OPEN reviews of the user are handed over to the
+// old teammates. Reviewers of PRs the user authored are kept.
+func (s *Service) MoveTeamMember(ctx context.Context, userID string, teamName string) (*domain.TeamMembershipResult, error) {
+	var result *domain.TeamMembershipResult
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionTeamMoveMember, userTarget(userID), func(repos Repositories) error {
+		user, err := repos.Users.GetByID(ctx, userID)
+		if err != nil {
+			return &domain.UserNotFoundError{UserID: userID}
+		}
+
+		if _, err := repos.Teams.GetSettings(ctx, teamName); err != nil {
+			return err
+		}
+
+		result = &domain.TeamMembershipResult{
+			User:         *user,
+			FromTeam:     user.Team,
+			ToTeam:       teamName,
+			Replacements: []domain.ReviewerReplacement{},
+		}
+		if user.Team == teamName {
+			return nil
+		}
+
+		result.Replacements, err = s.handOverReviews(ctx, repos, domain.AssignmentCauseDeactivation, user.Team, []string{userID})
+		if err != nil {
+			return err
+		}
+
+		movedUser, err := repos.Users.SetTeam(ctx, userID, teamName)
+		if err != nil {
+			return err
+		}
+		result.User = *movedUser
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RenameTeam renames a team together with the team_name of its members,
+// the fallback teams and the webhook subscriptions that name it. Pull requests reference users,
+// not teams, so they are not touched.
+func (s *Service) RenameTeam(ctx context.Context, teamName string, newName string) (*domain.Team, error) {
+	var renamedTeam *domain.Team
+	err := s.TxManager.WithinTx(ctx, auditedMove(ctx, domain.AuditActionTeamRename, teamTarget(teamName), teamTarget(newName), func(repos Repositories) error {
+		var err error
+		renamedTeam, err = repos.Teams.Rename(ctx, teamName, newName)
+		if err != nil {
+			return err
+		}
+
+		if err := replaceFallbackTeam(ctx, repos, teamName, newName); err != nil {
+			return err
+		}
+		return replaceWebhookTeam(ctx, repos, teamName, newName)
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return renamedTeam, nil
+}
+
+// DeleteTeam deletes a team without members, drops it from the fallback
+// teams of others and deactivates the webhook subscriptions scoped to it. Members have to be moved or removed first, which is where
+// their OPEN reviews are handed over.
+func (s *Service) DeleteTeam(ctx context.Context, teamName string) error {
+	return s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionTeamDelete, teamTarget(teamName), func(repos Repositories) error {
+		team, err := repos.Teams.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if len(team.Members) > 0 {
+			return &domain.TeamNotEmptyError{TeamName: teamName}
+		}
+
+		if err := repos.Teams.Delete(ctx, teamName); err != nil {
+			return err
+		}
+
+		if err := replaceFallbackTeam(ctx, repos, teamName, ""); err != nil {
+			return err
+		}
+		return replaceWebhookTeam(ctx, repos, teamName, "")
+	}))
+}
+
+// replaceFallbackTeam renames oldName in the fallback teams of every team,
+// an empty newName removes it.
+func replaceFallbackTeam(ctx context.Context, repos Repositories, oldName, newName string) error {
+	teams, err := repos.Teams.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		if !slices.Contains(team.Settings.FallbackTeams, oldName) {
+			continue
+		}
+
+		fallbackTeams := make([]string, 0, len(team.Settings.FallbackTeams))
+		for _, fallbackTeam := range team.Settings.FallbackTeams {
+			if fallbackTeam != oldName {
+				fallbackTeams = append(fallbackTeams, fallbackTeam)
+			} else if newName != "" {
+				fallbackTeams = append(fallbackTeams, newName)
+			}
+		}
+
+		settings := team.Settings
+		settings.FallbackTeams = fallbackTeams
+		if _, err := repos.Teams.UpdateSettings(ctx, team.Name, &settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handOverReviews moves OPEN reviews of the leaving users to other active members
+// of teamName or its fallback teams following the ReassignReviewer rules.
+// A review slot is dropped when nobody is left to take it.
+func (s *Service) handOverReviews(ctx context.Context, repos Repositories, cause domain.AssignmentCause, teamName string, userIDs []string) ([]domain.ReviewerReplacement, error) {
+	prs, err := repos.PullRequests.GetOpenByReviewers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	replacements := []domain.ReviewerReplacement{}
+	for i := range prs {
+		pr := &prs[i]
+		decisions, prReplacements, err := s.replaceReviewers(ctx, repos, pr, cause, teamName, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		left := make([]string, 0, len(prReplacements))
+		for _, replacement := range prReplacements {
+			left = append(left, replacement.OldUserID)
+		}
+		replacements = append(replacements, prReplacements...)
+
+		updatedPR, err := repos.PullRequests.Update(ctx, pr)
+		if err != nil {
+			return nil, err
+		}
+		if err := saveAssignments(ctx, repos, decisions...); err != nil {
+			return nil, err
+		}
+		if err := repos.Reviews.DeleteByReviewers(ctx, pr.ID, left); err != nil {
+			return nil, err
+		}
+		if err := s.publish(ctx, repos, updatedPR, replacementEvents(prReplacements)...); err != nil {
+			return nil, err
+		}
+	}
+
+	return replacements, nil
+}
+
+// replaceReviewers picks a replacement from teamName or its fallback teams
+// for every reviewer of pr in leaving and updates pr.AssignedReviewers, the
+// caller stores the pull request and the decisions.
+func (s *Service) replaceReviewers(
+	ctx context.Context,
+	repos Repositories,
+	pr *domain.PullRequest,
+	cause domain.AssignmentCause,
+	teamName string,
+	leaving []string,
+) ([]*reviewerDecision, []domain.ReviewerReplacement, error) {
+	// Keep the order of the slots, replaced reviewers take the old place
+	newReviewers := make([]string, 0, len(pr.AssignedReviewers))
+	var decisions []*reviewerDecision
+	var replacements []domain.ReviewerReplacement
+	for _, reviewer := range pr.AssignedReviewers {
+		if !slices.Contains(leaving, reviewer) {
+			newReviewers = append(newReviewers, reviewer)
+			continue
+		}
+
+		decision := newReviewerDecision(pr, cause)
+		decision.replacedUserID = reviewer
+		decision.leaving = leaving
+		decision.assigned = append(decision.assigned, newReviewers...)
+		decisions = append(decisions, decision)
+		selected, err := s.pickPullRequestReviewers(ctx, repos, decision, teamName, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		replacement := domain.ReviewerReplacement{PullRequestID: pr.ID, OldUserID: reviewer}
+		if len(selected) > 0 {
+			replacement.NewUserID = selected[0].UserID
+			newReviewers = append(newReviewers, selected[0].UserID)
+		}
+		replacements = append(replacements, replacement)
+	}
+
+	pr.AssignedReviewers = newReviewers
+	return decisions, replacements, nil
+}
+
+func isTeamMember(team *domain.Team, userID string) bool {
+	return slices.ContainsFunc(team.Members, func(member domain.User) bool {
+		return member.Id == userID
+	})
+}
+
+func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
+	var updatedPR *domain.PullRequest
+	var newReviewerID string
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionPullRequestReassign, pullRequestTarget(prID), func(repos Repositories) error {
+		var err error
+		updatedPR, newReviewerID, err = s.reassignReviewer(ctx, repos, prID, oldUserID)
+		return err
+	}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return updatedPR, newReviewerID, nil
+}
+
+func (s *Service) reassignReviewer(ctx context.Context, repos Repositories, prID, oldUserID string) (*domain.PullRequest, string, error) {
+	// Get the pull request
+	pr, err := repos.PullRequests.GetByID(ctx, prID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Only OPEN PRs have reviewers to reassign
+	if pr.Status != domain.PullRequestStatusOpen {
+		return nil, "", &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+	}
+
+	// Check if old user is assigned as reviewer
+	found := slices.Contains(pr.AssignedReviewers, oldUserID)
+	if !found {
+		return nil, "", &domain.ReviewerNotAssignedError{PullRequestID: prID, UserID: oldUserID}
+	}
+
+	// Get the old user to find their team
+	oldUser, err := repos.Users.GetByID(ctx, oldUserID)
+	if err != nil {
+		return nil, "", &domain.UserNotFoundError{UserID: oldUserID}
+	}
+
+	// Pick an owner of the changed paths, then from the old user's team or its
+	// fallbacks, skipping the author and current reviewers
+	decision := newReviewerDecision(pr, domain.AssignmentCauseReassignment)
+	decision.replacedUserID = oldUserID
+	decision.leaving = []string{oldUserID}
+	selected, err := s.pickPullRequestReviewers(ctx, repos, decision, oldUser.Team, 1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(selected) == 0 {
+		return nil, "", &domain.NoReviewersAvailableError{TeamName: oldUser.Team}
+	}
+	newReviewer := selected[0]
+
+	// Replace old reviewer with new reviewer
+	newReviewers := make([]string, len(pr.AssignedReviewers))
+	for i, reviewer := range pr.AssignedReviewers {
+		if reviewer == oldUserID {
+			newReviewers[i] = newReviewer.UserID
+		} else {
+			newReviewers[i] = reviewer
+		}
+	}
+
+	// Update the pull request
+	pr.AssignedReviewers = newReviewers
+	updatedPR, err := repos.PullRequests.Update(ctx, pr)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := saveAssignments(ctx, repos, decision); err != nil {
+		return nil, "", err
+	}
+	// A verdict belongs to the assignment, it must not count if the
+	// reviewer is assigned again later
+	if err := repos.Reviews.DeleteByReviewers(ctx, prID, []string{oldUserID}); err != nil {
+		return nil, "", err
+	}
+	err = s.publish(ctx, repos, updatedPR, domain.Event{
+		Type:           domain.EventReviewerReassigned,
+		UserID:         newReviewer.UserID,
+		ReplacedUserID: oldUserID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := loadReviews(ctx, repos, updatedPR); err != nil {
+		return nil, "", err
+	}
+	updatedPR.ReviewerChoices = selected
+
+	return updatedPR, newReviewer.UserID, nil
+}
+
+// AddReviewer assigns a chosen user to an OPEN pull request. The user has to
+// be active, not the author and not assigned yet, and neither the reviewer
+// count of the author's team nor the user's review limit may be exceeded.
+// The change is recorded in the reviewer history.
+func (s *Service) AddReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionPullRequestAddReviewer, pullRequestTarget(prID), func(repos Repositories) error {
+		pr, err := repos.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status != domain.PullRequestStatusOpen {
+			return &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+		}
+
+		reviewer, err := repos.Users.GetByID(ctx, userID)
+		if err != nil {
+			return &domain.UserNotFoundError{UserID: userID}
+		}
+		if reviewer.Id == pr.AuthorID {
+			return &domain.InvalidReviewerError{UserID: userID, Reason: "the author can't review their own pull request"}
+		}
+		if !reviewer.IsActive {
+			return &domain.InvalidReviewerError{UserID: userID, Reason: "the user is inactive"}
+		}
+		if slices.Contains(pr.AssignedReviewers, userID) {
+			return &domain.ReviewerAlreadyAssignedError{PullRequestID: prID, UserID: userID}
+		}
+
+		if err := checkReviewerLimits(ctx, repos, pr, reviewer); err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+		updatedPR, err = repos.PullRequests.Update(ctx, pr)
+		if err != nil {
+			return err
+		}
+
+		if err := recordReviewerChange(ctx, repos, prID, userID, domain.ReviewerChangeAdded); err != nil {
+			return err
+		}
+		if err := s.publish(ctx, repos, updatedPR, domain.Event{Type: domain.EventReviewerAssigned, UserID: userID}); err != nil {
+			return err
+		}
+
+		return loadReviews(ctx, repos, updatedPR)
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedPR, nil
+}
+
+// checkReviewerLimits makes sure reviewer can be added to pr without going
+// over the ReviewerCount of the author's team or the reviewer's MaxOpenReviews.
+func checkReviewerLimits(ctx context.Context, repos Repositories, pr *domain.PullRequest, reviewer *domain.User) error {
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+	}
+
+	settings, err := repos.Teams.GetSettings(ctx, author.Team)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) >= settings.ReviewerCount {
+		return &domain.ReviewerLimitError{
+			PullRequestID: pr.ID,
+			UserID:        reviewer.Id,
+			Reason:        fmt.Sprintf("team %s allows %d reviewers", author.Team, settings.ReviewerCount),
+		}
+	}
+
+	if reviewer.MaxOpenReviews != nil {
+		load, err := repos.PullRequests.CountOpenReviews(ctx, []string{reviewer.Id})
+		if err != nil {
+			return err
+		}
+		if load[reviewer.Id] >= *reviewer.MaxOpenReviews {
+			return &domain.ReviewerLimitError{
+				PullRequestID: pr.ID,
+				UserID:        reviewer.Id,
+				Reason:        fmt.Sprintf("the user already has %d open reviews", load[reviewer.Id]),
+			}
+		}
+	}
+
+	return nil
 }
 
-func CreateService(
-	teamRepo TeamRepository,
-	userRepo UserRepository,
-	prRepo PullRequestRepository,
-) *Service {
-	return &Service{
-		TeamRepo: teamRepo,
-		UserRepo: userRepo,
-		PRRepo:   prRepo,
+// RemoveReviewer unassigns a reviewer from an OPEN pull request without
+// picking a replacement. The change is recorded in the reviewer history.
+func (s *Service) RemoveReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionPullRequestRemoveReviewer, pullRequestTarget(prID), func(repos Repositories) error {
+		pr, err := repos.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status != domain.PullRequestStatusOpen {
+			return &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+		}
+
+		if !slices.Contains(pr.AssignedReviewers, userID) {
+			return &domain.ReviewerNotAssignedError{PullRequestID: prID, UserID: userID}
+		}
+
+		pr.AssignedReviewers = slices.DeleteFunc(pr.AssignedReviewers, func(reviewer string) bool {
+			return reviewer == userID
+		})
+		updatedPR, err = repos.PullRequests.Update(ctx, pr)
+		if err != nil {
+			return err
+		}
+
+		if err := recordReviewerChange(ctx, repos, prID, userID, domain.ReviewerChangeRemoved); err != nil {
+			return err
+		}
+		if err := repos.Reviews.DeleteByReviewers(ctx, prID, []string{userID}); err != nil {
+			return err
+		}
+		if err := s.publish(ctx, repos, updatedPR, domain.Event{Type: domain.EventReviewerRemoved, UserID: userID}); err != nil {
+			return err
+		}
+
+		return loadReviews(ctx, repos, updatedPR)
+	}))
+	if err != nil {
+		return nil, err
 	}
+
+	return updatedPR, nil
 }
 
-func (s *Service) AddTeam(ctx context.Context, team *domain.Team) (*domain.Team, error) {
-	insertedTeam, err := s.TeamRepo.Create(ctx, team)
-	return insertedTeam, err
+func recordReviewerChange(ctx context.Context, repos Repositories, prID, userID string, action domain.ReviewerChangeAction) error {
+	_, err := repos.ReviewerHistory.Add(ctx, &domain.ReviewerChange{
+		PullRequestID: prID,
+		UserID:        userID,
+		Action:        action,
+		ChangedAt:     time.Now(),
+	})
+	return err
 }
 
-func (s *Service) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
-	team, err := s.TeamRepo.Get(ctx, teamName)
-	return team, err
+// GetReviewerHistory lists the reviewers added to and removed from
+// the pull request by hand, oldest first.
+func (s *Service) GetReviewerHistory(ctx context.Context, prID string) ([]domain.ReviewerChange, error) {
+	if _, err := s.PRRepo.GetByID(ctx, prID); err != nil {
+		return nil, err
+	}
+
+	return s.ReviewerHistoryRepo.GetByPullRequest(ctx, prID)
 }
 
-func (s *Service) SetUserIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
-	user, err := s.UserRepo.SetIsActive(ctx, userID, isActive)
-	return user, err
+// FillPullRequestReviewers assigns more reviewers to an OPEN pull request that
+// has fewer than the ReviewerCount of the author's team, picking them like
+// CreatePullRequest does. A pull request with enough reviewers, or without
+// available candidates, is returned unchanged with no added reviewers.
+func (s *Service) FillPullRequestReviewers(ctx context.Context, prID string) (*domain.PullRequest, []string, error) {
+	var filledPR *domain.PullRequest
+	var addedIDs []string
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionPullRequestFillReviewers, pullRequestTarget(prID), func(repos Repositories) error {
+		pr, err := repos.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status != domain.PullRequestStatusOpen {
+			return &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+		}
+
+		filledPR, addedIDs, err = s.fillReviewers(ctx, repos, pr)
+		if err != nil {
+			return err
+		}
+
+		return loadReviews(ctx, repos, filledPR)
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return filledPR, addedIDs, nil
 }
 
-func (s *Service) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
-	// Get the pull request
-	pr, err := s.PRRepo.GetByID(ctx, prID)
+// FillTeamReviewers runs FillPullRequestReviewers over the OPEN pull requests
+// authored by members of the team and lists the ones that got new reviewers.
+func (s *Service) FillTeamReviewers(ctx context.Context, teamName string) ([]domain.ReviewerAddition, error) {
+	var additions []domain.ReviewerAddition
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionTeamFillReviewers, teamTarget(teamName), func(repos Repositories) error {
+		team, err := repos.Teams.Get(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		authorIDs := make([]string, 0, len(team.Members))
+		for _, member := range team.Members {
+			authorIDs = append(authorIDs, member.Id)
+		}
+
+		prs, err := repos.PullRequests.GetOpenByAuthors(ctx, authorIDs)
+		if err != nil {
+			return err
+		}
+
+		additions = []domain.ReviewerAddition{}
+		for i := range prs {
+			_, addedIDs, err := s.fillReviewers(ctx, repos, &prs[i])
+			if err != nil {
+				return err
+			}
+			if len(addedIDs) > 0 {
+				additions = append(additions, domain.ReviewerAddition{PullRequestID: prs[i].ID, AddedUserIDs: addedIDs})
+			}
+		}
+		return nil
+	}))
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	// Check if PR is merged
-	if pr.Status == domain.PullRequestStatusMerged {
-		return nil, "", &domain.PRMergedError{PullRequestID: prID}
+	return additions, nil
+}
+
+// fillReviewers appends reviewers to pr until it reaches the ReviewerCount
+// of the author's team and returns the ids of the added ones.
+func (s *Service) fillReviewers(ctx context.Context, repos Repositories, pr *domain.PullRequest) (*domain.PullRequest, []string, error) {
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, nil, &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
 	}
 
-	// Check if old user is assigned as reviewer
-	found := slices.Contains(pr.AssignedReviewers, oldUserID)
-	if !found {
-		return nil, "", &domain.ReviewerNotAssignedError{PullRequestID: prID, UserID: oldUserID}
+	settings, err := repos.Teams.GetSettings(ctx, author.Team)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Get the old user to find their team
-	oldUser, err := s.UserRepo.GetByID(ctx, oldUserID)
+	addedIDs := []string{}
+	missing := settings.ReviewerCount - len(pr.AssignedReviewers)
+	if missing <= 0 {
+		return pr, addedIDs, nil
+	}
+
+	decision := newReviewerDecision(pr, domain.AssignmentCauseTopUp)
+	selected, err := s.pickPullRequestReviewers(ctx, repos, decision, author.Team, missing)
 	if err != nil {
-		return nil, "", &domain.UserNotFoundError{UserID: oldUserID}
+		return nil, nil, err
+	}
+	// The decision is kept even when nobody could be added
+	if err := saveAssignments(ctx, repos, decision); err != nil {
+		return nil, nil, err
 	}
+	if len(selected) == 0 {
+		return pr, addedIDs, nil
+	}
+
+	addedIDs = reviewerIDs(selected)
+	pr.AssignedReviewers = append(pr.AssignedReviewers, addedIDs...)
 
-	// Get active team members excluding the old user and current reviewers
-	availableReviewers, err := s.UserRepo.GetActiveTeamMembers(ctx, oldUser.Team, pr.AuthorID)
+	updatedPR, err := repos.PullRequests.Update(ctx, pr)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
+	updatedPR.ReviewerChoices = selected
 
-	// Filter out current reviewers
-	var candidates []domain.User
-	for _, candidate := range availableReviewers {
-		isCurrentReviewer := slices.Contains(pr.AssignedReviewers, candidate.Id)
-		if !isCurrentReviewer {
-			candidates = append(candidates, candidate)
-		}
+	if err := s.publish(ctx, repos, updatedPR, reviewerEvents(nil, addedIDs)...); err != nil {
+		return nil, nil, err
 	}
 
-	if len(candidates) == 0 {
-		return nil, "", &domain.NoReviewersAvailableError{TeamName: oldUser.Team}
+	return updatedPR, addedIDs, nil
+}
+
+// SubmitReview records the verdict of an assigned reviewer on an OPEN
+// pull request. A new verdict replaces the reviewer's previous one.
+func (s *Service) SubmitReview(ctx context.Context, prID, reviewerID string, verdict domain.ReviewVerdict) (*domain.PullRequest, error) {
+	if !verdict.IsValid() {
+		return nil, &domain.UnknownReviewVerdictError{Verdict: verdict}
 	}
 
-	// Select first available candidate
-	newReviewer := candidates[0]
+	var reviewedPR *domain.PullRequest
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionPullRequestReview, pullRequestTarget(prID), func(repos Repositories) error {
+		pr, err := repos.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
 
-	// Replace old reviewer with new reviewer
-	newReviewers := make([]string, len(pr.AssignedReviewers))
-	for i, reviewer := range pr.AssignedReviewers {
-		if reviewer == oldUserID {
-			newReviewers[i] = newReviewer.Id
-		} else {
-			newReviewers[i] = reviewer
+		if pr.Status != domain.PullRequestStatusOpen {
+			return &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+		}
+		if !slices.Contains(pr.AssignedReviewers, reviewerID) {
+			return &domain.ReviewerNotAssignedError{PullRequestID: prID, UserID: reviewerID}
+		}
+
+		now := time.Now()
+		_, err = repos.Reviews.Upsert(ctx, &domain.Review{
+			PullRequestID: prID,
+			ReviewerID:    reviewerID,
+			Verdict:       verdict,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := loadReviews(ctx, repos, pr); err != nil {
+			return err
 		}
+		reviewedPR = pr
+		return nil
+	}))
+	if err != nil {
+		return nil, err
 	}
 
-	// Update the pull request
-	pr.AssignedReviewers = newReviewers
-	updatedPR, err := s.PRRepo.Update(ctx, pr)
+	return reviewedPR, nil
+}
+
+// loadReviews fills pr.Reviews with the verdicts submitted on it and
+// pr.ExternalReviewers with the reviewers from outside the author's team.
+func loadReviews(ctx context.Context, repos Repositories, pr *domain.PullRequest) error {
+	reviews, err := repos.Reviews.GetByPullRequest(ctx, pr.ID)
 	if err != nil {
-		return nil, "", err
+		return err
 	}
+	pr.Reviews = reviews
 
-	return updatedPR, newReviewer.Id, nil
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	pr.ExternalReviewers = nil
+	for _, reviewerID := range pr.AssignedReviewers {
+		reviewer, err := repos.Users.GetByID(ctx, reviewerID)
+		// Removed members stay reviewers of their MERGED and CLOSED pull requests
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &userNotFoundErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if reviewer.Team != author.Team {
+			pr.ExternalReviewers = append(pr.ExternalReviewers, reviewerID)
+		}
+	}
+	return nil
 }
 
-func (s *Service) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+// GetUserReviews lists the pull requests the user is assigned to review.
+// With awaitingOnly it keeps only OPEN ones the user has neither
+// approved nor requested changes on.
+func (s *Service) GetUserReviews(ctx context.Context, userID string, awaitingOnly bool) ([]domain.PullRequest, error) {
 	// Check if user exists
 	_, err := s.UserRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -120,27 +1098,325 @@ func (s *Service) GetUserReviews(ctx context.Context, userID string) ([]domain.P
 		return nil, err
 	}
 
+	// Closed PRs keep their reviewers for the history, but there is nothing to review
+	prs = slices.DeleteFunc(prs, func(pr domain.PullRequest) bool {
+		return pr.Status == domain.PullRequestStatusClosed
+	})
+
+	if awaitingOnly {
+		reviews, err := s.ReviewRepo.GetByReviewer(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		decided := make(map[string]bool, len(reviews))
+		for _, review := range reviews {
+			decided[review.PullRequestID] = review.Verdict.IsDecision()
+		}
+
+		prs = slices.DeleteFunc(prs, func(pr domain.PullRequest) bool {
+			return pr.Status != domain.PullRequestStatusOpen || decided[pr.ID]
+		})
+	}
+
 	return prs, nil
 }
 
-func (s *Service) MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	// Get the pull request
-	pr, err := s.PRRepo.GetByID(ctx, prID)
+func (s *Service) GetAssignmentStats(ctx context.Context) (*domain.AssignmentStats, error) {
+	return s.assignmentStats(ctx, "")
+}
+
+func (s *Service) GetTeamAssignmentStats(ctx context.Context, teamName string) (*domain.AssignmentStats, error) {
+	// Check if team exists, otherwise the stats would just be empty
+	_, err := s.TeamRepo.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.assignmentStats(ctx, teamName)
+}
+
+func (s *Service) assignmentStats(ctx context.Context, teamName string) (*domain.AssignmentStats, error) {
+	users, err := s.StatsRepo.GetUserAssignmentStats(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := s.StatsRepo.GetPullRequestAssignmentStats(ctx, teamName)
 	if err != nil {
 		return nil, err
 	}
 
-	// If already merged, return current state (idempotent)
-	if pr.Status == domain.PullRequestStatusMerged {
-		return pr, nil
+	return &domain.AssignmentStats{Users: users, PullRequests: prs}, nil
+}
+
+// MergePullRequest merges an OPEN pull request that meets the merge policy
+// of the author's team. A lead of that team merging as mergedBy skips the
+// policy, mergedBy may be empty.
+func (s *Service) MergePullRequest(ctx context.Context, prID, mergedBy string) (*domain.PullRequest, error) {
+	return s.transitionPullRequest(ctx, prID, domain.AuditActionPullRequestMerge, domain.PullRequestStatusMerged,
+		[]domain.PullRequestStatus{domain.PullRequestStatusOpen},
+		func(repos Repositories, pr *domain.PullRequest) error {
+			if err := checkMergePolicy(ctx, repos, pr, mergedBy); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			pr.MergedAt = &now
+			return nil
+		},
+	)
+}
+
+// checkMergePolicy returns MergePolicyViolationError with every condition
+// of the author's team policy the pull request fails.
+func checkMergePolicy(ctx context.Context, repos Repositories, pr *domain.PullRequest, mergedBy string) error {
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+	}
+
+	settings, err := repos.Teams.GetSettings(ctx, author.Team)
+	if err != nil {
+		return err
+	}
+	policy := settings.MergePolicy
+
+	reviews, err := repos.Reviews.GetByPullRequest(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+
+	// Verdicts of replaced reviewers do not count
+	approvals := 0
+	var changesRequestedBy []string
+	for _, review := range reviews {
+		if !slices.Contains(pr.AssignedReviewers, review.ReviewerID) {
+			continue
+		}
+		switch review.Verdict {
+		case domain.ReviewVerdictApproved:
+			approvals++
+		case domain.ReviewVerdictChangesRequested:
+			changesRequestedBy = append(changesRequestedBy, review.ReviewerID)
+		}
+	}
+
+	var unmet []string
+	if approvals < policy.MinApprovals {
+		unmet = append(unmet, fmt.Sprintf("needs %d approvals, has %d", policy.MinApprovals, approvals))
+	}
+	if policy.BlockOnChangesRequested && len(changesRequestedBy) > 0 {
+		unmet = append(unmet, fmt.Sprintf("changes requested by %s", strings.Join(changesRequestedBy, ", ")))
+	}
+	if len(unmet) == 0 {
+		return nil
+	}
+
+	if mergedBy != "" && slices.Contains(policy.LeadIDs, mergedBy) {
+		// A lead that has left the team no longer bypasses its policy
+		lead, err := repos.Users.GetByID(ctx, mergedBy)
+		if err == nil && lead.Team == author.Team {
+			return nil
+		}
+	}
+
+	return &domain.MergePolicyViolationError{PullRequestID: pr.ID, Unmet: unmet}
+}
+
+// ClosePullRequest declines an OPEN or DRAFT pull request. Reviewers are
+// kept for the history, closed PRs are not listed in user reviews.
+func (s *Service) ClosePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.transitionPullRequest(ctx, prID, domain.AuditActionPullRequestClose, domain.PullRequestStatusClosed,
+		[]domain.PullRequestStatus{domain.PullRequestStatusOpen, domain.PullRequestStatusDraft},
+		nil,
+	)
+}
+
+// ReopenPullRequest opens a closed pull request again with its old
+// reviewers, a PR closed as a draft gets reviewers assigned. Reviews of old
+// reviewers that can no longer review are handed over like on deactivation.
+func (s *Service) ReopenPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.transitionPullRequest(ctx, prID, domain.AuditActionPullRequestReopen, domain.PullRequestStatusOpen,
+		[]domain.PullRequestStatus{domain.PullRequestStatusClosed},
+		func(repos Repositories, pr *domain.PullRequest) error {
+			if len(pr.AssignedReviewers) == 0 {
+				decision, err := s.assignReviewers(ctx, repos, pr)
+				if err != nil {
+					return err
+				}
+				return saveAssignments(ctx, repos, decision)
+			}
+
+			author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+			if err != nil {
+				return &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+			}
+			gone, unavailable, err := staleReviewers(ctx, repos, pr, author.Team)
+			if err != nil {
+				return err
+			}
+
+			handOvers := []struct {
+				cause   domain.AssignmentCause
+				leaving []string
+			}{
+				{domain.AssignmentCauseDeactivation, gone},
+				{domain.AssignmentCauseUnavailability, unavailable},
+			}
+			for _, handOver := range handOvers {
+				if len(handOver.leaving) == 0 {
+					continue
+				}
+				decisions, _, err := s.replaceReviewers(ctx, repos, pr, handOver.cause, author.Team, handOver.leaving)
+				if err != nil {
+					return err
+				}
+				if err := saveAssignments(ctx, repos, decisions...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// staleReviewers returns the reviewers of a closed pull request whose reviews
+// would have been handed over had it been OPEN: gone are the users that were
+// removed, deactivated or left teamName and its fallback teams, unavailable
+// the ones that are unavailable right now. Reviewers added by hand from other
+// teams are kept.
+func staleReviewers(ctx context.Context, repos Repositories, pr *domain.PullRequest, teamName string) (gone, unavailable []string, err error) {
+	settings, err := repos.Teams.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	pools := append([]string{teamName}, settings.FallbackTeams...)
+
+	changes, err := repos.ReviewerHistory.GetByPullRequest(ctx, pr.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	addedByHand := map[string]bool{}
+	for _, change := range changes {
+		addedByHand[change.UserID] = change.Action == domain.ReviewerChangeAdded
 	}
 
-	// Update status to MERGED and set merged_at timestamp
 	now := time.Now()
-	pr.Status = domain.PullRequestStatusMerged
-	pr.MergedAt = &now
+	for _, reviewerID := range pr.AssignedReviewers {
+		reviewer, err := repos.Users.GetByID(ctx, reviewerID)
+		var userNotFoundErr *domain.UserNotFoundError
+		if errors.As(err, &userNotFoundErr) {
+			gone = append(gone, reviewerID)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !reviewer.IsActive || (!slices.Contains(pools, reviewer.Team) && !addedByHand[reviewerID]) {
+			gone = append(gone, reviewerID)
+			continue
+		}
+
+		periods, err := repos.Unavailability.GetByUser(ctx, reviewerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if slices.ContainsFunc(periods, func(period domain.Unavailability) bool { return period.Covers(now) }) {
+			unavailable = append(unavailable, reviewerID)
+		}
+	}
+	return gone, unavailable, nil
+}
+
+// MarkPullRequestReady moves a draft to review and assigns its reviewers.
+func (s *Service) MarkPullRequestReady(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.transitionPullRequest(ctx, prID, domain.AuditActionPullRequestReady, domain.PullRequestStatusOpen,
+		[]domain.PullRequestStatus{domain.PullRequestStatusDraft},
+		func(repos Repositories, pr *domain.PullRequest) error {
+			decision, err := s.assignReviewers(ctx, repos, pr)
+			if err != nil {
+				return err
+			}
+			return saveAssignments(ctx, repos, decision)
+		},
+	)
+}
+
+// ConvertPullRequestToDraft takes an OPEN pull request back from review,
+// its reviewers are released with their verdicts and picked again once it
+// is ready.
+func (s *Service) ConvertPullRequestToDraft(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.transitionPullRequest(ctx, prID, domain.AuditActionPullRequestDraft, domain.PullRequestStatusDraft,
+		[]domain.PullRequestStatus{domain.PullRequestStatusOpen},
+		func(repos Repositories, pr *domain.PullRequest) error {
+			pr.AssignedReviewers = []string{}
+			return nil
+		},
+	)
+}
+
+// transitionPullRequest moves a pull request from one of the from statuses
+// to the target status, change applies the rest of the update. A PR that is
+// already in the target status is returned as is, so transitions are idempotent.
+// The call is logged as action, events of the status and reviewer changes are published.
+func (s *Service) transitionPullRequest(
+	ctx context.Context,
+	prID string,
+	action domain.AuditAction,
+	to domain.PullRequestStatus,
+	from []domain.PullRequestStatus,
+	change func(repos Repositories, pr *domain.PullRequest) error,
+) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.withinTxRetry(ctx, audited(ctx, action, pullRequestTarget(prID), func(repos Repositories) error {
+		pr, err := repos.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == to {
+			updatedPR = pr
+			return loadReviews(ctx, repos, updatedPR)
+		}
+		if !slices.Contains(from, pr.Status) {
+			return &domain.PRNotModifiableError{PullRequestID: prID, Status: pr.Status}
+		}
+
+		previousStatus, previousReviewers := pr.Status, slices.Clone(pr.AssignedReviewers)
+		pr.Status = to
+		if change != nil {
+			if err := change(repos, pr); err != nil {
+				return err
+			}
+		}
+
+		updatedPR, err = repos.PullRequests.Update(ctx, pr)
+		if err != nil {
+			return err
+		}
+		updatedPR.ReviewerChoices = pr.ReviewerChoices
+
+		released := slices.DeleteFunc(slices.Clone(previousReviewers), func(reviewer string) bool {
+			return slices.Contains(updatedPR.AssignedReviewers, reviewer)
+		})
+		if len(released) > 0 {
+			if err := repos.Reviews.DeleteByReviewers(ctx, prID, released); err != nil {
+				return err
+			}
+		}
 
-	updatedPR, err := s.PRRepo.Update(ctx, pr)
+		var events []domain.Event
+		if eventType, ok := statusEvent(previousStatus, to); ok {
+			events = append(events, domain.Event{Type: eventType})
+		}
+		events = append(events, reviewerEvents(previousReviewers, updatedPR.AssignedReviewers)...)
+		if err := s.publish(ctx, repos, updatedPR, events...); err != nil {
+			return err
+		}
+
+		return loadReviews(ctx, repos, updatedPR)
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -148,9 +1424,26 @@ func (s *Service) MergePullRequest(ctx context.Context, prID string) (*domain.Pu
 	return updatedPR, nil
 }
 
-func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
+// CreatePullRequest creates an OPEN pull request with up to ReviewerCount
+// reviewers from the author's team, or a DRAFT without reviewers. Owners of
+// the changed paths are preferred as reviewers.
+func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID string, changedPaths []string, draft bool) (*domain.PullRequest, error) {
+	var createdPR *domain.PullRequest
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionPullRequestCreate, pullRequestTarget(prID), func(repos Repositories) error {
+		var err error
+		createdPR, err = s.createPullRequest(ctx, repos, prID, prName, authorID, changedPaths, draft)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return createdPR, nil
+}
+
+func (s *Service) createPullRequest(ctx context.Context, repos Repositories, prID, prName, authorID string, changedPaths []string, draft bool) (*domain.PullRequest, error) {
 	// Check if PR already exists
-	exists, err := s.PRRepo.Exists(ctx, prID)
+	exists, err := repos.PullRequests.Exists(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -158,37 +1451,159 @@ func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 		return nil, &domain.PullRequestExistsError{PullRequestID: prID}
 	}
 
+	now := time.Now()
+	pr := &domain.PullRequest{
+		ID:                prID,
+		Name:              prName,
+		AuthorID:          authorID,
+		Status:            domain.PullRequestStatusOpen,
+		AssignedReviewers: []string{},
+		ChangedPaths:      changedPaths,
+		CreatedAt:         &now,
+		MergedAt:          nil,
+	}
+
+	var decision *reviewerDecision
+	if draft {
+		// Drafts only need an existing author, reviewers come with MarkPullRequestReady
+		if _, err := repos.Users.GetByID(ctx, authorID); err != nil {
+			return nil, &domain.AuthorNotFoundError{AuthorID: authorID}
+		}
+		pr.Status = domain.PullRequestStatusDraft
+	} else if decision, err = s.assignReviewers(ctx, repos, pr); err != nil {
+		return nil, err
+	}
+
+	createdPR, err := repos.PullRequests.Create(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	createdPR.ReviewerChoices = pr.ReviewerChoices
+
+	if decision != nil {
+		if err := saveAssignments(ctx, repos, decision); err != nil {
+			return nil, err
+		}
+	}
+
+	events := append([]domain.Event{{Type: domain.EventPullRequestCreated}}, reviewerEvents(nil, createdPR.AssignedReviewers)...)
+	if err := s.publish(ctx, repos, createdPR, events...); err != nil {
+		return nil, err
+	}
+
+	if err := loadReviews(ctx, repos, createdPR); err != nil {
+		return nil, err
+	}
+
+	return createdPR, nil
+}
+
+// assignReviewers sets the reviewers of pr to up to ReviewerCount owners of
+// the changed paths and active teammates of the author, topping up from the
+// fallback teams when the team is too small. The returned decision is saved
+// by the caller once pr is stored.
+func (s *Service) assignReviewers(ctx context.Context, repos Repositories, pr *domain.PullRequest) (*reviewerDecision, error) {
 	// Get author to find their team
-	author, err := s.UserRepo.GetByID(ctx, authorID)
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+	}
+
+	settings, err := repos.Teams.GetSettings(ctx, author.Team)
 	if err != nil {
-		return nil, &domain.AuthorNotFoundError{AuthorID: authorID}
+		return nil, err
 	}
 
-	// Get active team members excluding author
-	reviewers, err := s.UserRepo.GetActiveTeamMembers(ctx, author.Team, authorID)
+	decision := newReviewerDecision(pr, domain.AssignmentCauseInitial)
+	selected, err := s.pickPullRequestReviewers(ctx, repos, decision, author.Team, settings.ReviewerCount)
 	if err != nil {
 		return nil, err
 	}
 
+	pr.AssignedReviewers = reviewerIDs(selected)
+	pr.ReviewerChoices = selected
+	return decision, nil
+}
+
+// reviewerIDs returns the ids of the chosen reviewers, never nil.
+func reviewerIDs(choices []domain.ReviewerChoice) []string {
 	// Нужно инициализировать пустым массивом, иначе gin будет считать
 	// что ничего не было передано
-	var assignedReviewers []string = []string{}
-	maxReviewers := min(len(reviewers), 2)
+	ids := []string{}
+	for _, choice := range choices {
+		ids = append(ids, choice.UserID)
+	}
+	return ids
+}
+
+// withinTxRetry runs fn in a transaction and starts over
+// when it fails because a pull request was changed concurrently.
+func (s *Service) withinTxRetry(ctx context.Context, fn func(repos Repositories) error) error {
+	var err error
+	for range maxConflictRetries {
+		err = s.TxManager.WithinTx(ctx, fn)
+
+		var conflictErr *domain.PullRequestConflictError
+		if !errors.As(err, &conflictErr) {
+			return err
+		}
+	}
+	return err
+}
 
-	for i := range maxReviewers {
-		assignedReviewers = append(assignedReviewers, reviewers[i].Id)
+// pickReviewers picks up to count reviewers for the decision: members of
+// teamName first, then members of its fallback teams in order. Each team is
+// picked from with its own reviewer strategy.
+func (s *Service) pickReviewers(ctx context.Context, repos Repositories, decision *reviewerDecision, teamName string, count int) ([]domain.ReviewerChoice, error) {
+	settings, err := repos.Teams.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
-	pr := &domain.PullRequest{
-		ID:                prID,
-		Name:              prName,
-		AuthorID:          authorID,
-		Status:            domain.PullRequestStatusOpen,
-		AssignedReviewers: assignedReviewers,
-		CreatedAt:         &now,
-		MergedAt:          nil,
+	selected := []domain.ReviewerChoice{}
+	for _, poolTeam := range append([]string{teamName}, settings.FallbackTeams...) {
+		if len(selected) >= count {
+			break
+		}
+
+		team, err := repos.Teams.Get(ctx, poolTeam)
+		if err != nil {
+			return nil, err
+		}
+
+		available, err := repos.Users.GetActiveTeamMembers(ctx, poolTeam, "")
+		if err != nil {
+			return nil, err
+		}
+
+		reason := domain.ReviewerReasonTeam
+		if poolTeam != teamName {
+			reason = domain.ReviewerReasonFallbackTeam
+		}
+
+		picked, err := s.pickFromPool(ctx, repos, decision, reason, poolTeam, team.Members, available, count-len(selected))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reviewer := range picked {
+			selected = append(selected, domain.ReviewerChoice{UserID: reviewer.Id, Reason: reason, Team: poolTeam})
+		}
+	}
+
+	return selected, nil
+}
+
+func (s *Service) teamSelector(ctx context.Context, repos Repositories, teamName string) (ReviewerSelector, domain.ReviewerStrategy, error) {
+	settings, err := repos.Teams.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	selector, ok := s.Selectors[settings.ReviewerStrategy]
+	if !ok {
+		return nil, "", &domain.UnknownReviewerStrategyError{Strategy: settings.ReviewerStrategy}
 	}
 
-	return s.PRRepo.Create(ctx, pr)
+	return selector, settings.ReviewerStrategy, nil
 }
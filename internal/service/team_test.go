@@ -0,0 +1,36 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+func TestRemovedReviewerKeepsMergedPullRequestLoadable(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	srv := service.CreateService(repos, memory.NewStatsRepo(store), memory.NewTxManager(store))
+
+	newTeam(t, ctx, repos, "backend", "author", "b", "c")
+	newPR(t, ctx, repos, "pr-1", "author", "b", "c")
+	if _, err := srv.MergePullRequest(ctx, "pr-1", ""); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	if _, err := srv.RemoveTeamMember(ctx, "backend", "b"); err != nil {
+		t.Fatalf("Removing member failed: %v", err)
+	}
+
+	pr, err := srv.MergePullRequest(ctx, "pr-1", "")
+	if err != nil {
+		t.Fatalf("Merging again failed: %v", err)
+	}
+	if pr.Status != domain.PullRequestStatusMerged || !slices.Contains(pr.AssignedReviewers, "b") {
+		t.Fatalf("Merged PR lost its reviewers: %v %v", pr.Status, pr.AssignedReviewers)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+// AddUnavailability schedules a period when the user gets no new reviews.
+// When the period has already started, OPEN reviews of the user are handed
+// over right away following the ReassignReviewer rules, otherwise
+// HandOverUnavailableReviews does it once the period starts.
+func (s *Service) AddUnavailability(ctx context.Context, unavailability *domain.Unavailability) (*domain.UnavailabilityResult, error) {
+	now := time.Now()
+	if err := validateUnavailability(unavailability, now); err != nil {
+		return nil, err
+	}
+
+	var result *domain.UnavailabilityResult
+	err := s.withinTxRetry(ctx, audited(ctx, domain.AuditActionUserAddUnavailability, unavailabilityTarget(unavailability.UserID), func(repos Repositories) error {
+		user, err := repos.Users.GetByID(ctx, unavailability.UserID)
+		if err != nil {
+			return &domain.UserNotFoundError{UserID: unavailability.UserID}
+		}
+
+		pending := *unavailability
+		pending.HandedOver = false
+		created, err := repos.Unavailability.Create(ctx, &pending)
+		if err != nil {
+			return err
+		}
+
+		result = &domain.UnavailabilityResult{
+			Unavailability: *created,
+			Replacements:   []domain.ReviewerReplacement{},
+		}
+		if !created.Covers(now) {
+			return nil
+		}
+
+		claimed, err := claimHandOver(ctx, repos, created.ID)
+		if err != nil || !claimed {
+			return err
+		}
+		result.Unavailability.HandedOver = true
+
+		result.Replacements, err = s.handOverReviews(ctx, repos, domain.AssignmentCauseUnavailability, user.Team, []string{user.Id})
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateUnavailability(unavailability *domain.Unavailability, now time.Time) error {
+	invalid := func(reason string) error {
+		return &domain.InvalidUnavailabilityError{UserID: unavailability.UserID, Reason: reason}
+	}
+
+	switch {
+	case unavailability.StartsAt.IsZero():
+		return invalid("starts_at is required")
+	case unavailability.EndsAt.IsZero():
+		return invalid("ends_at is required")
+	case !unavailability.EndsAt.After(unavailability.StartsAt):
+		return invalid("ends_at must be after starts_at")
+	case !unavailability.EndsAt.After(now):
+		return invalid("the period is already over")
+	}
+	return nil
+}
+
+// GetUnavailability returns the periods of a user, past ones included, ordered by start.
+func (s *Service) GetUnavailability(ctx context.Context, userID string) ([]domain.Unavailability, error) {
+	if _, err := s.UserRepo.GetByID(ctx, userID); err != nil {
+		return nil, &domain.UserNotFoundError{UserID: userID}
+	}
+
+	return s.UnavailabilityRepo.GetByUser(ctx, userID)
+}
+
+// DeleteUnavailability removes a period. Reviews that were already handed
+// over are not given back.
+func (s *Service) DeleteUnavailability(ctx context.Context, id int64) error {
+	return s.TxManager.WithinTx(ctx, func(repos Repositories) error {
+		unavailability, err := repos.Unavailability.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		target := unavailabilityTarget(unavailability.UserID)
+		return audited(ctx, domain.AuditActionUserDeleteUnavailability, target, func(repos Repositories) error {
+			return repos.Unavailability.Delete(ctx, id)
+		})(repos)
+	})
+}
+
+// HandOverUnavailableReviews hands over OPEN reviews of users whose
+// unavailability has started since the last run. Every period is handled in
+// its own transaction, so one failing period does not hold back the others.
+func (s *Service) HandOverUnavailableReviews(ctx context.Context) ([]domain.ReviewerReplacement, error) {
+	pending, err := s.UnavailabilityRepo.GetPendingHandOvers(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	replacements := []domain.ReviewerReplacement{}
+	var errs []error
+	for _, unavailability := range pending {
+		var handedOver []domain.ReviewerReplacement
+		err := s.withinTxRetry(ctx, func(repos Repositories) error {
+			handedOver = nil
+			claimed, err := claimHandOver(ctx, repos, unavailability.ID)
+			if err != nil || !claimed {
+				return err
+			}
+
+			user, err := repos.Users.GetByID(ctx, unavailability.UserID)
+			if err != nil {
+				return &domain.UserNotFoundError{UserID: unavailability.UserID}
+			}
+
+			handedOver, err = s.handOverReviews(ctx, repos, domain.AssignmentCauseUnavailability, user.Team, []string{user.Id})
+			return err
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		replacements = append(replacements, handedOver...)
+	}
+
+	return replacements, errors.Join(errs...)
+}
+
+// claimHandOver marks the period as handed over and reports whether the
+// caller is the one to hand its reviews over. It is false when the period
+// has been handed over by another caller in the meantime.
+func claimHandOver(ctx context.Context, repos Repositories, id int64) (bool, error) {
+	err := repos.Unavailability.MarkHandedOver(ctx, id)
+	var handedOverErr *domain.UnavailabilityHandedOverError
+	if errors.As(err, &handedOverErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
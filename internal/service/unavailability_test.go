@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// stalePending replays pending hand-overs read before another caller
+// handed them over.
+type stalePending struct {
+	service.UnavailabilityRepository
+	pending []domain.Unavailability
+}
+
+func (r stalePending) GetPendingHandOvers(ctx context.Context, at time.Time) ([]domain.Unavailability, error) {
+	return r.pending, nil
+}
+
+func TestUnavailabilityIsHandedOverOnce(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	srv := service.CreateService(repos, memory.NewStatsRepo(store), memory.NewTxManager(store))
+
+	newTeam(t, ctx, repos, "backend", "author", "b", "c", "d")
+	newPR(t, ctx, repos, "pr-1", "author", "b")
+	now := time.Now()
+	_, err := repos.Unavailability.Create(ctx, &domain.Unavailability{
+		UserID:   "b",
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to make b unavailable: %v", err)
+	}
+	pending, err := repos.Unavailability.GetPendingHandOvers(ctx, now)
+	if err != nil {
+		t.Fatalf("Failed to get pending hand-overs: %v", err)
+	}
+
+	replacements, err := srv.HandOverUnavailableReviews(ctx)
+	if err != nil || len(replacements) != 1 {
+		t.Fatalf("First hand-over failed: %v %v", replacements, err)
+	}
+
+	// b is assigned by hand after the hand-over and keeps the review
+	newPR(t, ctx, repos, "pr-2", "author", "b")
+	srv.UnavailabilityRepo = stalePending{UnavailabilityRepository: repos.Unavailability, pending: pending}
+	replacements, err = srv.HandOverUnavailableReviews(ctx)
+	if err != nil || len(replacements) != 0 {
+		t.Fatalf("Period was handed over twice: %v %v", replacements, err)
+	}
+	if load := openReviews(t, ctx, repos, "b"); load["b"] != 1 {
+		t.Fatalf("Review of b was handed over again: %v", load)
+	}
+}
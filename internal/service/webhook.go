@@ -0,0 +1,513 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/domain"
+)
+
+const (
+	// DefaultWebhookDeliveriesPageSize is the number of deliveries
+	// GetWebhookDeliveries returns without a limit
+	DefaultWebhookDeliveriesPageSize = 50
+	// MaxWebhookDeliveriesPageSize bounds the limit of GetWebhookDeliveries
+	MaxWebhookDeliveriesPageSize = 500
+
+	// webhookDeliveryBatch bounds the deliveries sent by one DeliverWebhooks call
+	webhookDeliveryBatch = 100
+	// webhookSendsPerSubscription bounds the requests sent to one
+	// subscription at a time
+	webhookSendsPerSubscription = 4
+)
+
+// WebhookRetryPolicy spaces out the attempts of a delivery: the n-th retry
+// waits BaseDelay * 2^(n-1), but no longer than MaxDelay. A delivery fails
+// for good after MaxAttempts. Lease is how long a claimed delivery is left
+// to the worker that claimed it, after that it is attempted again even if
+// that worker never recorded the attempt.
+type WebhookRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Lease       time.Duration
+}
+
+func DefaultWebhookRetryPolicy() WebhookRetryPolicy {
+	return WebhookRetryPolicy{
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+		Lease:       5 * time.Minute,
+	}
+}
+
+// Delay is the wait before the next attempt of a delivery that has failed attempts times.
+func (p WebhookRetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of payload keyed with
+// secret, receivers compute the same to check where a payload comes from.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookPublisher is the default EventPublisher, it queues a delivery of
+// every event for each active subscription that wants it.
+type WebhookPublisher struct{}
+
+func (WebhookPublisher) Publish(ctx context.Context, repos Repositories, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	subscriptions, err := repos.Webhooks.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var payload json.RawMessage
+		for _, subscription := range subscriptions {
+			if !subscription.Matches(event) {
+				continue
+			}
+
+			if payload == nil {
+				if payload, err = newWebhookPayload(event); err != nil {
+					return err
+				}
+			}
+
+			_, err := repos.Deliveries.Create(ctx, &domain.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				EventType:      event.Type,
+				Payload:        payload,
+				Status:         domain.WebhookDeliveryPending,
+				NextAttemptAt:  event.OccurredAt,
+				CreatedAt:      event.OccurredAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Payloads use the field names of the API, like its pull request responses.
+
+type webhookPullRequest struct {
+	PullRequestID     string     `json:"pull_request_id"`
+	PullRequestName   string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         *time.Time `json:"createdAt"`
+	MergedAt          *time.Time `json:"mergedAt"`
+}
+
+type webhookPayload struct {
+	Event       string             `json:"event"`
+	OccurredAt  time.Time          `json:"occurred_at"`
+	TeamName    string             `json:"team_name"`
+	PullRequest webhookPullRequest `json:"pull_request"`
+	// UserID is the reviewer of reviewer events
+	UserID         string `json:"user_id,omitempty"`
+	ReplacedUserID string `json:"replaced_user_id,omitempty"`
+}
+
+func newWebhookPayload(event domain.Event) (json.RawMessage, error) {
+	pr := event.PullRequest
+	payload, err := json.Marshal(webhookPayload{
+		Event:      string(event.Type),
+		OccurredAt: event.OccurredAt,
+		TeamName:   event.TeamName,
+		PullRequest: webhookPullRequest{
+			PullRequestID:     pr.ID,
+			PullRequestName:   pr.Name,
+			AuthorID:          pr.AuthorID,
+			Status:            string(pr.Status),
+			AssignedReviewers: append([]string{}, pr.AssignedReviewers...),
+			CreatedAt:         pr.CreatedAt,
+			MergedAt:          pr.MergedAt,
+		},
+		UserID:         event.UserID,
+		ReplacedUserID: event.ReplacedUserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s event of %s: %w", event.Type, pr.ID, err)
+	}
+	return payload, nil
+}
+
+// publish hands events about pr over to s.Events, filling in the pull
+// request, the team of its author and the time.
+func (s *Service) publish(ctx context.Context, repos Repositories, pr *domain.PullRequest, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return &domain.AuthorNotFoundError{AuthorID: pr.AuthorID}
+	}
+
+	now := time.Now()
+	for i := range events {
+		events[i].TeamName = author.Team
+		events[i].PullRequest = *pr
+		events[i].OccurredAt = now
+	}
+	return s.Events.Publish(ctx, repos, events...)
+}
+
+// reviewerEvents describes how the reviewers of a pull request changed
+// from before to after, in the order of after and then before.
+func reviewerEvents(before, after []string) []domain.Event {
+	var events []domain.Event
+	for _, userID := range after {
+		if !slices.Contains(before, userID) {
+			events = append(events, domain.Event{Type: domain.EventReviewerAssigned, UserID: userID})
+		}
+	}
+	for _, userID := range before {
+		if !slices.Contains(after, userID) {
+			events = append(events, domain.Event{Type: domain.EventReviewerRemoved, UserID: userID})
+		}
+	}
+	return events
+}
+
+// statusEvent is the event of a pull request moving from one status to
+// another, moving a draft to review has none.
+func statusEvent(from, to domain.PullRequestStatus) (domain.EventType, bool) {
+	switch {
+	case to == domain.PullRequestStatusMerged:
+		return domain.EventPullRequestMerged, true
+	case to == domain.PullRequestStatusClosed:
+		return domain.EventPullRequestClosed, true
+	case from == domain.PullRequestStatusClosed && to == domain.PullRequestStatusOpen:
+		return domain.EventPullRequestReopened, true
+	}
+	return "", false
+}
+
+// replacementEvents describes the reviewer replacements of one pull request.
+func replacementEvents(replacements []domain.ReviewerReplacement) []domain.Event {
+	events := make([]domain.Event, 0, len(replacements))
+	for _, replacement := range replacements {
+		if replacement.NewUserID == "" {
+			events = append(events, domain.Event{Type: domain.EventReviewerRemoved, UserID: replacement.OldUserID})
+			continue
+		}
+		events = append(events, domain.Event{
+			Type:           domain.EventReviewerReassigned,
+			UserID:         replacement.NewUserID,
+			ReplacedUserID: replacement.OldUserID,
+		})
+	}
+	return events
+}
+
+// CreateWebhook subscribes subscription.URL to events, a new subscription is active.
+func (s *Service) CreateWebhook(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := validateWebhook(subscription); err != nil {
+		return nil, err
+	}
+
+	var created *domain.WebhookSubscription
+	err := s.TxManager.WithinTx(ctx, func(repos Repositories) error {
+		if err := checkWebhookTeam(ctx, repos, subscription.TeamName); err != nil {
+			return err
+		}
+
+		pending := *subscription
+		pending.Active = true
+		pending.CreatedAt = time.Now()
+		var err error
+		created, err = repos.Webhooks.Create(ctx, &pending)
+		if err != nil {
+			return err
+		}
+
+		return auditCreated(ctx, repos, domain.AuditActionWebhookCreate, webhookTarget(created.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetWebhook(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	return s.WebhookRepo.Get(ctx, id)
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.WebhookRepo.List(ctx)
+}
+
+// UpdateWebhook replaces the URL, events and team of a subscription and sets
+// its activity to active. An empty Secret and a nil active keep the current ones.
+func (s *Service) UpdateWebhook(ctx context.Context, subscription *domain.WebhookSubscription, active *bool) (*domain.WebhookSubscription, error) {
+	var updated *domain.WebhookSubscription
+	err := s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionWebhookUpdate, webhookTarget(subscription.ID), func(repos Repositories) error {
+		current, err := repos.Webhooks.Get(ctx, subscription.ID)
+		if err != nil {
+			return err
+		}
+
+		pending := *subscription
+		if pending.Secret == "" {
+			pending.Secret = current.Secret
+		}
+		pending.Active = current.Active
+		if active != nil {
+			pending.Active = *active
+		}
+		if err := validateWebhook(&pending); err != nil {
+			return err
+		}
+		if err := checkWebhookTeam(ctx, repos, pending.TeamName); err != nil {
+			return err
+		}
+
+		updated, err = repos.Webhooks.Update(ctx, &pending)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteWebhook removes a subscription together with its delivery log.
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.TxManager.WithinTx(ctx, audited(ctx, domain.AuditActionWebhookDelete, webhookTarget(id), func(repos Repositories) error {
+		return repos.Webhooks.Delete(ctx, id)
+	}))
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a subscription with their attempts.
+func (s *Service) GetWebhookDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDeliveryLog, error) {
+	if limit < 0 || limit > MaxWebhookDeliveriesPageSize {
+		return nil, &domain.InvalidWebhookError{Reason: fmt.Sprintf("limit must be between 1 and %d", MaxWebhookDeliveriesPageSize)}
+	}
+	if limit == 0 {
+		limit = DefaultWebhookDeliveriesPageSize
+	}
+
+	if _, err := s.WebhookRepo.Get(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.DeliveryRepo.GetBySubscription(ctx, subscriptionID, limit)
+}
+
+func validateWebhook(subscription *domain.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return &domain.InvalidWebhookError{Reason: fmt.Sprintf("url %q must be an absolute http or https URL", subscription.URL)}
+	}
+	if subscription.Secret == "" {
+		return &domain.InvalidWebhookError{Reason: "secret is required"}
+	}
+
+	for i, eventType := range subscription.Events {
+		if !eventType.IsValid() {
+			return &domain.InvalidWebhookError{Reason: fmt.Sprintf("unknown event %s", eventType)}
+		}
+		if slices.Contains(subscription.Events[:i], eventType) {
+			return &domain.InvalidWebhookError{Reason: fmt.Sprintf("%s is listed twice", eventType)}
+		}
+	}
+	return nil
+}
+
+// checkWebhookTeam makes sure a subscription is scoped to an existing team.
+func checkWebhookTeam(ctx context.Context, repos Repositories, teamName string) error {
+	if teamName == "" {
+		return nil
+	}
+
+	_, err := repos.Teams.GetSettings(ctx, teamName)
+	var teamNotFoundErr *domain.TeamNotFoundError
+	if errors.As(err, &teamNotFoundErr) {
+		return &domain.InvalidWebhookError{Reason: err.Error()}
+	}
+	return err
+}
+
+// replaceWebhookTeam moves subscriptions scoped to oldName to newName. An
+// empty newName deactivates them instead, they keep the old name so it is
+// clear why they stopped.
+func replaceWebhookTeam(ctx context.Context, repos Repositories, oldName, newName string) error {
+	subscriptions, err := repos.Webhooks.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.TeamName != oldName {
+			continue
+		}
+
+		if newName != "" {
+			subscription.TeamName = newName
+		} else {
+			subscription.Active = false
+		}
+		if _, err := repos.Webhooks.Update(ctx, &subscription); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverWebhooks sends the deliveries that are due and returns how many
+// it attempted. The deliveries are claimed first, so several workers never
+// send the same one. Subscriptions are served concurrently, so a slow
+// receiver holds back only its own deliveries. A failed attempt is retried
+// following s.WebhookRetry. Every attempt is recorded in its own
+// transaction, so one failing delivery does not hold back the others.
+func (s *Service) DeliverWebhooks(ctx context.Context) (int, error) {
+	now := time.Now()
+	var due []domain.WebhookDelivery
+	err := s.TxManager.WithinTx(ctx, func(repos Repositories) error {
+		var err error
+		due, err = repos.Deliveries.ClaimDue(ctx, now, now.Add(s.WebhookRetry.Lease), webhookDeliveryBatch)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bySubscription := map[int64][]*domain.WebhookDelivery{}
+	for i := range due {
+		delivery := &due[i]
+		bySubscription[delivery.SubscriptionID] = append(bySubscription[delivery.SubscriptionID], delivery)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	attempted := 0
+	var errs []error
+	for subscriptionID, deliveries := range bySubscription {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sent, err := s.deliverToSubscription(ctx, subscriptionID, deliveries)
+
+			mu.Lock()
+			defer mu.Unlock()
+			attempted += sent
+			errs = append(errs, err)
+		}()
+	}
+	wg.Wait()
+
+	return attempted, errors.Join(errs...)
+}
+
+// deliverToSubscription sends the deliveries of one subscription, at most
+// webhookSendsPerSubscription at a time, and returns how many it attempted.
+// A subscription deactivated since the deliveries were claimed gets nothing,
+// they wait until it is active again.
+func (s *Service) deliverToSubscription(ctx context.Context, subscriptionID int64, deliveries []*domain.WebhookDelivery) (int, error) {
+	// A subscription deleted since takes its deliveries with it
+	subscription, err := s.WebhookRepo.Get(ctx, subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	if !subscription.Active {
+		return 0, nil
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	sending := make(chan struct{}, webhookSendsPerSubscription)
+	for _, delivery := range deliveries {
+		sending <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sending
+				wg.Done()
+			}()
+
+			attempt := s.sendWebhook(ctx, subscription, delivery)
+			err := s.TxManager.WithinTx(ctx, func(repos Repositories) error {
+				return repos.Deliveries.RecordAttempt(ctx, delivery, attempt)
+			})
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+// sendWebhook makes the next attempt of delivery and moves it on: it is
+// delivered on a 2xx response, rescheduled otherwise and failed once it is
+// out of attempts.
+func (s *Service) sendWebhook(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) *domain.WebhookAttempt {
+	now := time.Now()
+	attempt := &domain.WebhookAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: now,
+	}
+
+	statusCode, err := s.WebhookSender.Send(ctx, WebhookRequest{
+		URL:        subscription.URL,
+		DeliveryID: delivery.ID,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+		Signature:  SignWebhookPayload(subscription.Secret, delivery.Payload),
+	})
+	attempt.StatusCode = statusCode
+	if err != nil {
+		attempt.Error = err.Error()
+	} else if statusCode < 200 || statusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected response status %d", statusCode)
+	}
+
+	delivery.Attempts = attempt.Attempt
+	delivery.LastError = attempt.Error
+	switch {
+	case attempt.Error == "":
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.WebhookRetry.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(s.WebhookRetry.Delay(delivery.Attempts))
+	}
+
+	return attempt
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raccoon00/avito-pr/internal/adapter/memory"
+	"github.com/raccoon00/avito-pr/internal/domain"
+	"github.com/raccoon00/avito-pr/internal/service"
+)
+
+// stubSender answers 204, requests to slowURL wait until release is closed.
+type stubSender struct {
+	slowURL string
+	release chan struct{}
+
+	mu sync.Mutex
+	// sent counts the requests per URL, inFlight and maxInFlight the
+	// requests to slowURL
+	sent        map[string]int
+	inFlight    int
+	maxInFlight int
+}
+
+func (s *stubSender) Send(ctx context.Context, request service.WebhookRequest) (int, error) {
+	s.mu.Lock()
+	s.sent[request.URL]++
+	slow := request.URL == s.slowURL
+	if slow {
+		s.inFlight++
+		s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	}
+	s.mu.Unlock()
+
+	if slow {
+		<-s.release
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}
+	return 204, nil
+}
+
+func (s *stubSender) stats() (sent map[string]int, inFlight, maxInFlight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent = make(map[string]int, len(s.sent))
+	for url, count := range s.sent {
+		sent[url] = count
+	}
+	return sent, s.inFlight, s.maxInFlight
+}
+
+func newWebhookService(store *memory.Store, sender *stubSender) *service.Service {
+	srv := service.CreateService(memory.NewRepositories(store), memory.NewStatsRepo(store), memory.NewTxManager(store))
+	srv.WebhookSender = sender
+	return srv
+}
+
+// newSubscription stores an active subscription of url with count due deliveries.
+func newSubscription(t *testing.T, ctx context.Context, repos service.Repositories, url string, count int) *domain.WebhookSubscription {
+	now := time.Now()
+	subscription, err := repos.Webhooks.Create(ctx, &domain.WebhookSubscription{URL: url, Secret: "secret", Active: true, CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+
+	for range count {
+		_, err := repos.Deliveries.Create(ctx, &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventType:      domain.EventReviewerAssigned,
+			Payload:        json.RawMessage(`{}`),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create delivery: %v", err)
+		}
+	}
+	return subscription
+}
+
+func deliveryStatuses(t *testing.T, ctx context.Context, repos service.Repositories, subscriptionID int64) []domain.WebhookDeliveryStatus {
+	logs, err := repos.Deliveries.GetBySubscription(ctx, subscriptionID, 100)
+	if err != nil {
+		t.Fatalf("Failed to get deliveries: %v", err)
+	}
+	statuses := make([]domain.WebhookDeliveryStatus, 0, len(logs))
+	for _, log := range logs {
+		statuses = append(statuses, log.Delivery.Status)
+	}
+	return statuses
+}
+
+func TestDeliverWebhooksSkipsInactiveSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	sender := &stubSender{sent: map[string]int{}}
+	srv := newWebhookService(store, sender)
+
+	subscription := newSubscription(t, ctx, repos, "https://inactive.example.com", 2)
+	subscription.Active = false
+	if _, err := repos.Webhooks.Update(ctx, subscription); err != nil {
+		t.Fatalf("Failed to deactivate subscription: %v", err)
+	}
+
+	attempted, err := srv.DeliverWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("Delivery failed: %v", err)
+	}
+	sent, _, _ := sender.stats()
+	if attempted != 0 || len(sent) != 0 {
+		t.Fatalf("Inactive subscription got %d attempts, sent %v", attempted, sent)
+	}
+	for _, status := range deliveryStatuses(t, ctx, repos, subscription.ID) {
+		if status != domain.WebhookDeliveryPending {
+			t.Fatalf("Delivery of an inactive subscription is %s, want it pending", status)
+		}
+	}
+}
+
+func TestDeliverWebhooksIsNotHeldBackBySlowReceiver(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repos := memory.NewRepositories(store)
+	sender := &stubSender{slowURL: "https://slow.example.com", release: make(chan struct{}), sent: map[string]int{}}
+	srv := newWebhookService(store, sender)
+
+	newSubscription(t, ctx, repos, sender.slowURL, 6)
+	fast := newSubscription(t, ctx, repos, "https://fast.example.com", 1)
+
+	type result struct {
+		attempted int
+		err       error
+	}
+	done := make(chan result)
+	go func() {
+		attempted, err := srv.DeliverWebhooks(ctx)
+		done <- result{attempted, err}
+	}()
+
+	// The fast receiver is served while the slow one has the four
+	// requests it is allowed at a time in flight
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		statuses := deliveryStatuses(t, ctx, repos, fast.ID)
+		_, inFlight, _ := sender.stats()
+		if len(statuses) == 1 && statuses[0] == domain.WebhookDeliveryDelivered && inFlight == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(sender.release)
+			t.Fatalf("Fast delivery is %v with %d slow requests in flight", statuses, inFlight)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(sender.release)
+	res := <-done
+	if res.err != nil || res.attempted != 7 {
+		t.Fatalf("Delivery attempted %d, failed with %v", res.attempted, res.err)
+	}
+	sent, _, maxInFlight := sender.stats()
+	if sent[sender.slowURL] != 6 || maxInFlight != 4 {
+		t.Fatalf("Slow receiver got %d requests, %d at a time", sent[sender.slowURL], maxInFlight)
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE pr_requests ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
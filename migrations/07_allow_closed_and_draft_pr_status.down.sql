@@ -0,0 +1,4 @@
+-- The old constraint knows nothing about closed and draft PRs, both go back to OPEN
+UPDATE pr_requests SET status = 'OPEN' WHERE status IN ('CLOSED', 'DRAFT');
+ALTER TABLE pr_requests DROP CONSTRAINT IF EXISTS pr_requests_status_check;
+ALTER TABLE pr_requests ADD CONSTRAINT pr_requests_status_check CHECK (status IN ('OPEN', 'MERGED'));
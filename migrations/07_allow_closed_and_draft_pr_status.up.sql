@@ -0,0 +1,2 @@
+ALTER TABLE pr_requests DROP CONSTRAINT IF EXISTS pr_requests_status_check;
+ALTER TABLE pr_requests ADD CONSTRAINT pr_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED', 'DRAFT'));
@@ -0,0 +1,3 @@
+ALTER TABLE teams DROP COLUMN IF EXISTS merge_lead_ids;
+ALTER TABLE teams DROP COLUMN IF EXISTS block_on_changes_requested;
+ALTER TABLE teams DROP COLUMN IF EXISTS min_approvals;
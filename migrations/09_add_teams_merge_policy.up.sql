@@ -0,0 +1,3 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_approvals INTEGER NOT NULL DEFAULT 0 CHECK (min_approvals >= 0);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS merge_lead_ids TEXT[] NOT NULL DEFAULT '{}';
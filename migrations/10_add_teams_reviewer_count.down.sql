@@ -0,0 +1,3 @@
+-- NOT VALID keeps pull requests that already have more than two reviewers
+ALTER TABLE pr_requests ADD CONSTRAINT max_reviewers CHECK (array_length(assigned_reviewers, 1) <= 2) NOT VALID;
+ALTER TABLE teams DROP COLUMN IF EXISTS reviewer_count;
@@ -0,0 +1,3 @@
+-- The number of reviewers is a team setting validated by the service, it replaces the max_reviewers constraint
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS reviewer_count INTEGER NOT NULL DEFAULT 2;
+ALTER TABLE pr_requests DROP CONSTRAINT IF EXISTS max_reviewers;
@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS fallback_teams TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- ownership_rules is an ordered JSON array of {"pattern": ..., "owners": [...]}, the last matching rule wins
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS ownership_rules JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+ALTER TABLE pr_requests DROP COLUMN IF EXISTS changed_paths;
@@ -0,0 +1 @@
+ALTER TABLE pr_requests ADD COLUMN IF NOT EXISTS changed_paths TEXT[] NOT NULL DEFAULT '{}';
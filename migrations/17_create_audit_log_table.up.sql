@@ -0,0 +1,29 @@
+-- One row per change made through the API. Targets have no foreign keys,
+-- so entries outlive the entities they describe. before and after are JSON
+-- snapshots of the target, NULL when it did not exist.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL CHECK (target_type IN ('TEAM', 'USER', 'PULL_REQUEST', 'UNAVAILABILITY', 'ROSTER')),
+    target_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+
+-- The log is append-only
+CREATE OR REPLACE FUNCTION audit_log_forbid_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_forbid_change();
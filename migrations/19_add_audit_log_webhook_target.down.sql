@@ -0,0 +1,4 @@
+-- The log is append-only, NOT VALID keeps the WEBHOOK entries written so far
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_target_type_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_target_type_check
+    CHECK (target_type IN ('TEAM', 'USER', 'PULL_REQUEST', 'UNAVAILABILITY', 'ROSTER')) NOT VALID;
@@ -0,0 +1,3 @@
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_target_type_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_target_type_check
+    CHECK (target_type IN ('TEAM', 'USER', 'PULL_REQUEST', 'UNAVAILABILITY', 'ROSTER', 'WEBHOOK'));
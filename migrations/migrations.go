@@ -0,0 +1,10 @@
+// Package migrations embeds the Postgres schema migrations, so the service
+// binary can apply them without the migrate container.
+package migrations
+
+import "embed"
+
+// FS holds the NN_name.up.sql and NN_name.down.sql files in the golang-migrate layout.
+//
+//go:embed *.sql
+var FS embed.FS
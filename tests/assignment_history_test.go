@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+)
+
+type AssignmentHistoryResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	History       []struct {
+		Cause          string   `json:"cause"`
+		ReplacedUserID string   `json:"replaced_user_id"`
+		Pool           string   `json:"pool"`
+		TeamName       string   `json:"team_name"`
+		Strategy       string   `json:"strategy"`
+		Candidates     []string `json:"candidates"`
+		Excluded       []struct {
+			UserID string `json:"user_id"`
+			Reason string `json:"reason"`
+		} `json:"excluded"`
+		Chosen    []string `json:"chosen"`
+		DecidedAt string   `json:"decided_at"`
+	} `json:"history"`
+}
+
+func TestAssignmentHistory(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	getHistory := func(t *testing.T, prID string) AssignmentHistoryResponse {
+		resp, err := http.Get(baseURL + "/pullRequest/assignmentHistory?pull_request_id=" + prID)
+		if err != nil {
+			t.Logf("Failed to get assignment history: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, http.StatusOK, resp.StatusCode, "Getting the history should succeed")
+
+		var historyResp AssignmentHistoryResponse
+		err = json.NewDecoder(resp.Body).Decode(&historyResp)
+		if err != nil {
+			t.Logf("Failed to decode history response: %v", err)
+			t.FailNow()
+		}
+		return historyResp
+	}
+
+	exclusions := func(excluded []struct {
+		UserID string `json:"user_id"`
+		Reason string `json:"reason"`
+	}) map[string]string {
+		reasons := map[string]string{}
+		for _, candidate := range excluded {
+			reasons[candidate.UserID] = candidate.Reason
+		}
+		return reasons
+	}
+
+	team := Team{Name: "assignment-core", Members: []TeamMember{
+		{Id: "u33000", Name: "Alice", IsActive: true},
+		{Id: "u33001", Name: "Bob", IsActive: true},
+		{Id: "u33002", Name: "Charlie", IsActive: false},
+		{Id: "u33003", Name: "Dave", IsActive: true},
+		{Id: "u33004", Name: "Eve", IsActive: true},
+		{Id: "u33005", Name: "Frank", IsActive: true},
+	}}
+	sc, _ := post(t, "/team/add", team)
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	sc, bodyBytes := post(t, "/users/setReviewLimit", map[string]any{"user_id": "u33003", "max_open_reviews": 0})
+	assertEqual(t, http.StatusOK, sc, "Setting the review limit should succeed\n"+string(bodyBytes))
+
+	var reviewers []string
+	t.Run("Initial assignment is recorded", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/create", CreatePullRequestRequest{
+			PullRequestID:   "pr-assignment-001",
+			PullRequestName: "Assignment PR",
+			AuthorID:        "u33000",
+		})
+		assertEqual(t, http.StatusCreated, sc, "PR creation should succeed\n"+string(bodyBytes))
+
+		var prResp PullRequestResponse
+		err := json.Unmarshal(bodyBytes, &prResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+		reviewers = prResp.PR.AssignedReviewers
+		assertEqual(t, 2, len(reviewers), "Two reviewers should be assigned")
+
+		historyResp := getHistory(t, "pr-assignment-001")
+		assertEqual(t, 1, len(historyResp.History), "One decision should be recorded")
+
+		record := historyResp.History[0]
+		assertEqual(t, "INITIAL", record.Cause, "Cause")
+		assertEqual(t, "TEAM_MEMBER", record.Pool, "Pool")
+		assertEqual(t, "assignment-core", record.TeamName, "Team")
+		assertEqual(t, "RANDOM", record.Strategy, "Strategy")
+		assertEqual(t, "", record.ReplacedUserID, "Nobody is replaced")
+		assertEqual(t, 3, len(record.Candidates), "Three candidates")
+		assertTrue(t, slices.Equal(reviewers, record.Chosen), "Chosen users are the reviewers")
+
+		reasons := exclusions(record.Excluded)
+		assertEqual(t, 3, len(reasons), "Three users should be excluded")
+		assertEqual(t, "AUTHOR", reasons["u33000"], "Author exclusion")
+		assertEqual(t, "INACTIVE", reasons["u33002"], "Inactive exclusion")
+		assertEqual(t, "OVER_CAPACITY", reasons["u33003"], "Capacity exclusion")
+	})
+
+	t.Run("Reassignment is recorded", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/reassign", ReassignReviewerRequest{
+			PullRequestID: "pr-assignment-001",
+			OldUserID:     reviewers[0],
+		})
+		assertEqual(t, http.StatusOK, sc, "Reassign should succeed\n"+string(bodyBytes))
+
+		historyResp := getHistory(t, "pr-assignment-001")
+		assertEqual(t, 2, len(historyResp.History), "Two decisions should be recorded")
+
+		record := historyResp.History[1]
+		assertEqual(t, "REASSIGNMENT", record.Cause, "Cause")
+		assertEqual(t, reviewers[0], record.ReplacedUserID, "Replaced user")
+		assertEqual(t, 1, len(record.Candidates), "One candidate is left")
+		assertEqual(t, 1, len(record.Chosen), "One user should be chosen")
+		assertEqual(t, record.Candidates[0], record.Chosen[0], "The only candidate is chosen")
+
+		reasons := exclusions(record.Excluded)
+		assertEqual(t, "REPLACED", reasons[reviewers[0]], "Replaced reviewer exclusion")
+		assertEqual(t, "ALREADY_ASSIGNED", reasons[reviewers[1]], "Other reviewer exclusion")
+	})
+
+	t.Run("Top-up is recorded", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/removeReviewer", map[string]string{
+			"pull_request_id": "pr-assignment-001",
+			"user_id":         reviewers[1],
+		})
+		assertEqual(t, http.StatusOK, sc, "Removing a reviewer should succeed\n"+string(bodyBytes))
+
+		sc, bodyBytes = post(t, "/pullRequest/fillReviewers", map[string]string{"pull_request_id": "pr-assignment-001"})
+		assertEqual(t, http.StatusOK, sc, "Top-up should succeed\n"+string(bodyBytes))
+
+		historyResp := getHistory(t, "pr-assignment-001")
+		assertEqual(t, 3, len(historyResp.History), "Three decisions should be recorded")
+		assertEqual(t, "TOP_UP", historyResp.History[2].Cause, "Cause")
+		assertEqual(t, 1, len(historyResp.History[2].Chosen), "One user should be chosen")
+	})
+
+	t.Run("Deactivation is recorded", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/deactivateMembers", map[string]any{
+			"team_name": "assignment-core",
+			"user_ids":  []string{"u33001", "u33004", "u33005"},
+		})
+		assertEqual(t, http.StatusOK, sc, "Deactivation should succeed\n"+string(bodyBytes))
+
+		historyResp := getHistory(t, "pr-assignment-001")
+		assertEqual(t, 5, len(historyResp.History), "Both reviewers should be handed over")
+		for _, record := range historyResp.History[3:] {
+			assertEqual(t, "DEACTIVATION", record.Cause, "Cause")
+			assertEqual(t, 0, len(record.Chosen), "Nobody is left to choose")
+			assertEqual(t, "REPLACED", exclusions(record.Excluded)[record.ReplacedUserID], "Deactivated reviewer exclusion")
+		}
+	})
+
+	t.Run("History of a missing PR", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/pullRequest/assignmentHistory?pull_request_id=pr-assignment-missing")
+		if err != nil {
+			t.Logf("Failed to get assignment history: %v", err)
+			t.FailNow()
+		}
+		resp.Body.Close()
+		assertEqual(t, http.StatusNotFound, resp.StatusCode, "Missing PR should return 404")
+
+		resp, err = http.Get(baseURL + "/pullRequest/assignmentHistory")
+		if err != nil {
+			t.Logf("Failed to get assignment history: %v", err)
+			t.FailNow()
+		}
+		resp.Body.Close()
+		assertEqual(t, http.StatusBadRequest, resp.StatusCode, "Missing pull_request_id should return 400")
+	})
+}
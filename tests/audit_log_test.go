@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	Entries     []AuditEntry `json:"entries"`
+	NextAfterID *int64       `json:"next_after_id"`
+}
+
+func TestAuditLog(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	// postAs sends the request on behalf of actor within requestID
+	postAs := func(t *testing.T, path, actor, requestID string, body any) (int, []byte) {
+		reqJSON, err := json.Marshal(body)
+		if err != nil {
+			t.Log("Failed to marshal request")
+			t.FailNow()
+		}
+
+		req, err := http.NewRequest(http.MethodPost, baseURL+path, bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to build request to %s: %v", path, err)
+			t.FailNow()
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Actor", actor)
+		req.Header.Set("X-Request-ID", requestID)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Logf("Failed to send request to %s: %v", path, err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, requestID, resp.Header.Get("X-Request-ID"), "Request id is echoed")
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+		return resp.StatusCode, bodyBytes
+	}
+
+	getLog := func(t *testing.T, query string) AuditLogResponse {
+		resp, err := http.Get(baseURL + "/audit/log?" + query)
+		if err != nil {
+			t.Logf("Failed to get audit log: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, http.StatusOK, resp.StatusCode, "Getting the audit log should succeed")
+
+		var logResp AuditLogResponse
+		err = json.NewDecoder(resp.Body).Decode(&logResp)
+		if err != nil {
+			t.Logf("Failed to decode audit log response: %v", err)
+			t.FailNow()
+		}
+		return logResp
+	}
+
+	snapshotField := func(t *testing.T, snapshot json.RawMessage, field string) any {
+		var fields map[string]any
+		if err := json.Unmarshal(snapshot, &fields); err != nil {
+			t.Logf("Failed to decode snapshot %s: %v", snapshot, err)
+			t.FailNow()
+		}
+		return fields[field]
+	}
+
+	team := Team{Name: "audit-core", Members: []TeamMember{
+		{Id: "u34000", Name: "Alice", IsActive: true},
+		{Id: "u34001", Name: "Bob", IsActive: true},
+		{Id: "u34002", Name: "Charlie", IsActive: true},
+		{Id: "u34003", Name: "Dave", IsActive: true},
+	}}
+	sc, bodyBytes := postAs(t, "/team/add", "alice", "audit-req-team", team)
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed\n"+string(bodyBytes))
+
+	t.Run("Team creation is logged", func(t *testing.T) {
+		logResp := getLog(t, "request_id=audit-req-team")
+		assertEqual(t, 1, len(logResp.Entries), "One entry for the request")
+
+		entry := logResp.Entries[0]
+		assertEqual(t, "alice", entry.Actor, "Actor")
+		assertEqual(t, "TEAM_ADD", entry.Action, "Action")
+		assertEqual(t, "TEAM", entry.TargetType, "Target type")
+		assertEqual(t, "audit-core", entry.TargetID, "Target id")
+		assertEqual(t, "null", string(entry.Before), "The team did not exist")
+		assertEqual(t, "audit-core", snapshotField(t, entry.After, "team_name"), "Created team")
+	})
+
+	t.Run("User deactivation is logged with both snapshots", func(t *testing.T) {
+		sc, bodyBytes := postAs(t, "/users/setIsActive", "bob", "audit-req-deactivate", map[string]any{"user_id": "u34003", "is_active": false})
+		assertEqual(t, http.StatusOK, sc, "Deactivation should succeed\n"+string(bodyBytes))
+
+		logResp := getLog(t, "target_type=USER&target_id=u34003")
+		assertEqual(t, 1, len(logResp.Entries), "One entry for the user")
+
+		entry := logResp.Entries[0]
+		assertEqual(t, "bob", entry.Actor, "Actor")
+		assertEqual(t, "USER_SET_IS_ACTIVE", entry.Action, "Action")
+		assertEqual(t, "audit-req-deactivate", entry.RequestID, "Request id")
+		assertEqual(t, true, snapshotField(t, entry.Before, "is_active"), "Active before")
+		assertEqual(t, false, snapshotField(t, entry.After, "is_active"), "Inactive after")
+	})
+
+	t.Run("Failed calls are not logged", func(t *testing.T) {
+		sc, _ := postAs(t, "/users/setIsActive", "bob", "audit-req-missing", map[string]any{"user_id": "u34999", "is_active": false})
+		assertEqual(t, http.StatusNotFound, sc, "Missing user should return 404")
+
+		logResp := getLog(t, "request_id=audit-req-missing")
+		assertEqual(t, 0, len(logResp.Entries), "Nothing is logged")
+	})
+
+	t.Run("Pull request changes are logged in order", func(t *testing.T) {
+		sc, bodyBytes := postAs(t, "/pullRequest/create", "alice", "audit-req-pr-create", CreatePullRequestRequest{
+			PullRequestID:   "pr-audit-001",
+			PullRequestName: "Audit PR",
+			AuthorID:        "u34000",
+		})
+		assertEqual(t, http.StatusCreated, sc, "PR creation should succeed\n"+string(bodyBytes))
+
+		sc, bodyBytes = postAs(t, "/pullRequest/merge", "carol", "audit-req-pr-merge", map[string]string{"pull_request_id": "pr-audit-001"})
+		assertEqual(t, http.StatusOK, sc, "Merge should succeed\n"+string(bodyBytes))
+
+		logResp := getLog(t, "target_type=PULL_REQUEST&target_id=pr-audit-001")
+		assertEqual(t, 2, len(logResp.Entries), "Two entries for the PR")
+		assertEqual(t, "PULL_REQUEST_CREATE", logResp.Entries[0].Action, "First action")
+		assertEqual(t, "PULL_REQUEST_MERGE", logResp.Entries[1].Action, "Second action")
+		assertEqual(t, "carol", logResp.Entries[1].Actor, "Merged by")
+		assertEqual(t, "OPEN", snapshotField(t, logResp.Entries[1].Before, "status"), "Open before the merge")
+		assertEqual(t, "MERGED", snapshotField(t, logResp.Entries[1].After, "status"), "Merged after the merge")
+		assertTrue(t, logResp.NextAfterID == nil, "Everything fits on one page")
+
+		firstPage := getLog(t, "target_id=pr-audit-001&limit=1")
+		assertEqual(t, 1, len(firstPage.Entries), "First page size")
+		assertEqual(t, "PULL_REQUEST_CREATE", firstPage.Entries[0].Action, "First page entry")
+		assertTrue(t, firstPage.NextAfterID != nil, "First page points to the next one")
+
+		secondPage := getLog(t, fmt.Sprintf("target_id=pr-audit-001&limit=1&after_id=%d", *firstPage.NextAfterID))
+		assertEqual(t, 1, len(secondPage.Entries), "Second page size")
+		assertEqual(t, "PULL_REQUEST_MERGE", secondPage.Entries[0].Action, "Second page entry")
+	})
+
+	t.Run("Export streams NDJSON", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/audit/export?target_type=PULL_REQUEST&target_id=pr-audit-001")
+		if err != nil {
+			t.Logf("Failed to export audit log: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, http.StatusOK, resp.StatusCode, "Export should succeed")
+		assertTrue(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "application/x-ndjson"), "NDJSON content type")
+
+		var actions []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var entry AuditEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Logf("Failed to decode line %s: %v", scanner.Text(), err)
+				t.FailNow()
+			}
+			actions = append(actions, entry.Action)
+		}
+		assertEqual(t, "PULL_REQUEST_CREATE,PULL_REQUEST_MERGE", strings.Join(actions, ","), "Exported actions")
+	})
+
+	t.Run("Requests without an id get one", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/team/get?team_name=audit-core")
+		if err != nil {
+			t.Logf("Failed to get team: %v", err)
+			t.FailNow()
+		}
+		resp.Body.Close()
+		assertEqual(t, 32, len(resp.Header.Get("X-Request-ID")), "Generated request id")
+	})
+
+	t.Run("Invalid queries", func(t *testing.T) {
+		for _, query := range []string{"limit=1000", "limit=-1", "from=yesterday", "from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z"} {
+			resp, err := http.Get(baseURL + "/audit/log?" + query)
+			if err != nil {
+				t.Logf("Failed to get audit log: %v", err)
+				t.FailNow()
+			}
+			resp.Body.Close()
+			assertEqual(t, http.StatusBadRequest, resp.StatusCode, "Query "+query+" should return 400")
+		}
+	})
+}
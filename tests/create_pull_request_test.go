@@ -10,24 +10,6 @@ import (
 	"testing"
 )
 
-type CreatePullRequestRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
-}
-
-type PullRequestResponse struct {
-	PR struct {
-		PullRequestID     string   `json:"pull_request_id"`
-		PullRequestName   string   `json:"pull_request_name"`
-		AuthorID          string   `json:"author_id"`
-		Status            string   `json:"status"`
-		AssignedReviewers []string `json:"assigned_reviewers"`
-		CreatedAt         *string  `json:"createdAt,omitempty"`
-		MergedAt          *string  `json:"mergedAt,omitempty"`
-	} `json:"pr"`
-}
-
 func TestCreatePullRequest(t *testing.T) {
 	port := os.Getenv("SERVICE_PORT")
 	if port == "" {
@@ -93,3 +93,123 @@ func TestTeamAdd(t *testing.T) {
 		t.Logf("Expected sc to be a Bad Request (400), got %v\n", sc)
 	}
 }
+
+type TeamAddResponse struct {
+	Team           Team     `json:"team"`
+	CreatedMembers []string `json:"created_members"`
+	UpdatedMembers []string `json:"updated_members"`
+}
+
+func TestTeamAddUpdatesExistingUsers(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	addTeam := func(t *testing.T, team Team) TeamAddResponse {
+		teamJSON, err := json.Marshal(team)
+		if err != nil {
+			t.Log("Failed to marshal team")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(teamJSON))
+		if err != nil {
+			t.Logf("Failed to create team: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Logf("Team creation should succeed, got status: %d\n%s", resp.StatusCode, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var addResp TeamAddResponse
+		err = json.Unmarshal(bodyBytes, &addResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal team response: %v", err)
+			t.FailNow()
+		}
+		return addResp
+	}
+
+	addResp := addTeam(t, Team{
+		Name: "upsert-old-team",
+		Members: []TeamMember{
+			{Id: "u22000", Name: "Alice", IsActive: true},
+			{Id: "u22001", Name: "Bob", IsActive: true},
+		},
+	})
+	assertEqual(t, "[u22000 u22001]", fmt.Sprint(addResp.CreatedMembers), "Created members")
+	assertEqual(t, 0, len(addResp.UpdatedMembers), "Updated members count")
+
+	addResp = addTeam(t, Team{
+		Name: "upsert-new-team",
+		Members: []TeamMember{
+			{Id: "u22001", Name: "Robert", IsActive: false},
+			{Id: "u22002", Name: "Charlie", IsActive: true},
+		},
+	})
+	assertEqual(t, "[u22002]", fmt.Sprint(addResp.CreatedMembers), "Created members")
+	assertEqual(t, "[u22001]", fmt.Sprint(addResp.UpdatedMembers), "Updated members")
+	assertEqual(t, "Robert", addResp.Team.Members[0].Name, "Updated name")
+	assertEqual(t, false, addResp.Team.Members[0].IsActive, "Updated activity")
+
+	resp, err := http.Get(baseURL + "/team/get?team_name=upsert-old-team")
+	_, oldTeam, _ := processResponse(t, resp, err)
+	assertLen(t, oldTeam.Members, 1, "Moved user leaves the old team")
+	assertEqual(t, "u22000", oldTeam.Members[0].Id, "Remaining member")
+}
+
+func TestTeamAddHandsOverReviewsOfMovedUsers(t *testing.T) {
+	createTeam(t, Team{
+		Name: "handover-old-team",
+		Members: []TeamMember{
+			{Id: "u36000", Name: "Author", IsActive: true},
+			{Id: "u36001", Name: "Alice", IsActive: true},
+			{Id: "u36002", Name: "Bob", IsActive: true},
+			{Id: "u36003", Name: "Charlie", IsActive: true},
+		},
+	})
+	reviewers := createPR(t, "pr-36000", "u36000").PR.AssignedReviewers
+	assertEqual(t, 2, len(reviewers), "Assigned reviewers count")
+	moved := reviewers[0]
+
+	sc, bodyBytes := post(t, "/team/add", Team{
+		Name:    "handover-new-team",
+		Members: []TeamMember{{Id: moved, Name: "Moved", IsActive: false}},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed\n"+string(bodyBytes))
+
+	var addResp struct {
+		TeamAddResponse
+		Reassignments []struct {
+			PullRequestID string  `json:"pull_request_id"`
+			OldUserID     string  `json:"old_user_id"`
+			NewUserID     *string `json:"new_user_id"`
+		} `json:"reassignments"`
+	}
+	decode(t, bodyBytes, &addResp)
+	assertEqual(t, fmt.Sprint([]string{moved}), fmt.Sprint(addResp.UpdatedMembers), "Updated members")
+	assertEqual(t, 1, len(addResp.Reassignments), "Reassignments count")
+	assertEqual(t, "pr-36000", addResp.Reassignments[0].PullRequestID, "Reassigned PR")
+	assertEqual(t, moved, addResp.Reassignments[0].OldUserID, "Replaced reviewer")
+	assertTrue(t, addResp.Reassignments[0].NewUserID != nil, "A remaining teammate takes over")
+
+	newReviewer := *addResp.Reassignments[0].NewUserID
+	assertTrue(t, newReviewer != moved && newReviewer != reviewers[1] && newReviewer != "u36000", "New reviewer is a free old teammate")
+
+	sc, bodyBytes = get(t, "/users/getReview?user_id="+moved)
+	assertEqual(t, http.StatusOK, sc, "Getting reviews should succeed")
+	var reviewsResp GetUserReviewsResponse
+	decode(t, bodyBytes, &reviewsResp)
+	assertEqual(t, 0, len(reviewsResp.PullRequests), "Moved user has no reviews left")
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+)
+
+type DeactivateTeamMembersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type DeactivateTeamMembersResponse struct {
+	TeamName         string `json:"team_name"`
+	DeactivatedUsers []struct {
+		UserID   string `json:"user_id"`
+		IsActive bool   `json:"is_active"`
+	} `json:"deactivated_users"`
+	Reassignments []struct {
+		PullRequestID string  `json:"pull_request_id"`
+		OldUserID     string  `json:"old_user_id"`
+		NewUserID     *string `json:"new_user_id"`
+	} `json:"reassignments"`
+}
+
+func TestDeactivateTeamMembers(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	deactivate := func(t *testing.T, req DeactivateTeamMembersRequest) (int, []byte) {
+		reqJSON, err := json.Marshal(req)
+		if err != nil {
+			t.Log("Failed to marshal deactivation request")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/deactivateMembers", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to deactivate members: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+		return resp.StatusCode, bodyBytes
+	}
+
+	t.Run("Deactivated reviewers are replaced", func(t *testing.T) {
+		createTeam(t, Team{
+			Name: "deactivation-team",
+			Members: []TeamMember{
+				{Id: "u18000", Name: "Alice", IsActive: true},
+				{Id: "u18001", Name: "Bob", IsActive: true},
+				{Id: "u18002", Name: "Charlie", IsActive: true},
+				{Id: "u18003", Name: "David", IsActive: true},
+				{Id: "u18004", Name: "Eve", IsActive: true},
+			},
+		})
+
+		reviewers := createPR(t, "pr-deactivation-001", "u18000").PR.AssignedReviewers
+		assertEqual(t, 2, len(reviewers), "Initial reviewers count")
+
+		sc, bodyBytes := deactivate(t, DeactivateTeamMembersRequest{
+			TeamName: "deactivation-team",
+			UserIDs:  reviewers,
+		})
+		if sc != http.StatusOK {
+			t.Logf("Deactivation should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var deactivationResp DeactivateTeamMembersResponse
+		err := json.Unmarshal(bodyBytes, &deactivationResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal deactivation response: %v", err)
+			t.FailNow()
+		}
+
+		assertEqual(t, 2, len(deactivationResp.DeactivatedUsers), "Deactivated users count")
+		for _, user := range deactivationResp.DeactivatedUsers {
+			assertTrue(t, !user.IsActive, "Deactivated user should be inactive")
+		}
+
+		assertEqual(t, 2, len(deactivationResp.Reassignments), "Reassignments count")
+		for _, reassignment := range deactivationResp.Reassignments {
+			assertEqual(t, "pr-deactivation-001", reassignment.PullRequestID, "Reassigned PR")
+			assertTrue(t, reassignment.NewUserID != nil, "Replacement should be found")
+			assertTrue(t, !slices.Contains(reviewers, *reassignment.NewUserID), "Replacement should be active")
+			assertTrue(t, *reassignment.NewUserID != "u18000", "Author should not be a replacement")
+		}
+
+		resp, err := http.Get(baseURL + "/users/getReview?user_id=" + reviewers[0])
+		if err != nil {
+			t.Logf("Failed to get reviews: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err = io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+
+		var reviewsResp GetUserReviewsResponse
+		err = json.Unmarshal(bodyBytes, &reviewsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal reviews response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 0, len(reviewsResp.PullRequests), "Deactivated user should have no reviews")
+	})
+
+	t.Run("Slot is dropped when nobody is left", func(t *testing.T) {
+		createTeam(t, Team{
+			Name: "deactivation-small-team",
+			Members: []TeamMember{
+				{Id: "u18100", Name: "Frank", IsActive: true},
+				{Id: "u18101", Name: "Grace", IsActive: true},
+			},
+		})
+
+		reviewers := createPR(t, "pr-deactivation-002", "u18100").PR.AssignedReviewers
+		assertEqual(t, 1, len(reviewers), "Initial reviewers count")
+
+		sc, bodyBytes := deactivate(t, DeactivateTeamMembersRequest{
+			TeamName: "deactivation-small-team",
+			UserIDs:  []string{"u18101"},
+		})
+		assertEqual(t, http.StatusOK, sc, "Deactivation should succeed")
+
+		var deactivationResp DeactivateTeamMembersResponse
+		err := json.Unmarshal(bodyBytes, &deactivationResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal deactivation response: %v", err)
+			t.FailNow()
+		}
+
+		assertEqual(t, 1, len(deactivationResp.Reassignments), "Reassignments count")
+		assertEqual(t, "u18101", deactivationResp.Reassignments[0].OldUserID, "Removed reviewer")
+		assertTrue(t, deactivationResp.Reassignments[0].NewUserID == nil, "Slot should be dropped")
+	})
+
+	t.Run("Deactivate user from another team", func(t *testing.T) {
+		sc, bodyBytes := deactivate(t, DeactivateTeamMembersRequest{
+			TeamName: "deactivation-small-team",
+			UserIDs:  []string{"u18001"},
+		})
+		assertEqual(t, http.StatusNotFound, sc, "User from another team should return 404")
+
+		var errorResp ErrorResponse
+		err := json.Unmarshal(bodyBytes, &errorResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal error response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "NOT_FOUND", errorResp.Error.Code, "Error code")
+	})
+
+	t.Run("Deactivate without users", func(t *testing.T) {
+		sc, _ := deactivate(t, DeactivateTeamMembersRequest{
+			TeamName: "deactivation-small-team",
+			UserIDs:  []string{},
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Empty user list should return 400")
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type FallbackReassignResponse struct {
+	PR struct {
+		AssignedReviewers []string        `json:"assigned_reviewers"`
+		Reviews           []ReviewerState `json:"reviews"`
+	} `json:"pr"`
+	ReplacedBy string `json:"replaced_by"`
+}
+
+func TestFallbackTeams(t *testing.T) {
+	poolMembers := []string{"u28010", "u28011", "u28012"}
+	teams := []Team{
+		{Name: "fallback-solo", Members: []TeamMember{{Id: "u28000", Name: "Alice", IsActive: true}}},
+		{Name: "fallback-duo", Members: []TeamMember{
+			{Id: "u28020", Name: "Bob", IsActive: true},
+			{Id: "u28021", Name: "Charlie", IsActive: true},
+		}},
+		{Name: "fallback-pool", Members: []TeamMember{
+			{Id: "u28010", Name: "Dave", IsActive: true},
+			{Id: "u28011", Name: "Eve", IsActive: true},
+			{Id: "u28012", Name: "Frank", IsActive: true},
+		}},
+	}
+	for _, team := range teams {
+		sc, _ := post(t, "/team/add", team)
+		assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+	}
+
+	t.Run("Team without fallback gets no reviewers", func(t *testing.T) {
+		prResp := createPR(t, "pr-fallback-001", "u28000")
+		assertEqual(t, 0, len(prResp.PR.AssignedReviewers), "No reviewers should be assigned")
+	})
+
+	t.Run("Invalid fallback teams", func(t *testing.T) {
+		sc, _ := post(t, "/team/setFallbackTeams", map[string]any{"team_name": "fallback-solo", "fallback_teams": []string{"fallback-solo"}})
+		assertEqual(t, http.StatusBadRequest, sc, "Falling back to itself should return 400")
+
+		sc, _ = post(t, "/team/setFallbackTeams", map[string]any{"team_name": "fallback-solo", "fallback_teams": []string{"fallback-missing"}})
+		assertEqual(t, http.StatusBadRequest, sc, "Missing fallback team should return 400")
+
+		sc, _ = post(t, "/team/setFallbackTeams", map[string]any{"team_name": "fallback-solo", "fallback_teams": []string{"fallback-pool", "fallback-pool"}})
+		assertEqual(t, http.StatusBadRequest, sc, "Duplicate fallback team should return 400")
+
+		sc, _ = post(t, "/team/setFallbackTeams", map[string]any{"team_name": "fallback-missing", "fallback_teams": []string{"fallback-pool"}})
+		assertEqual(t, http.StatusNotFound, sc, "Missing team should return 404")
+	})
+
+	for _, teamName := range []string{"fallback-solo", "fallback-duo"} {
+		sc, bodyBytes := post(t, "/team/setFallbackTeams", map[string]any{"team_name": teamName, "fallback_teams": []string{"fallback-pool"}})
+		assertEqual(t, http.StatusOK, sc, "Setting fallback teams should succeed\n"+string(bodyBytes))
+	}
+
+	t.Run("Empty team is filled from the fallback", func(t *testing.T) {
+		prResp := createPR(t, "pr-fallback-002", "u28000")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Two reviewers should be assigned")
+		for _, review := range prResp.PR.Reviews {
+			assertTrue(t, slices.Contains(poolMembers, review.UserID), "Reviewer should come from the pool")
+			assertTrue(t, review.External, "Reviewer should be marked external")
+		}
+	})
+
+	t.Run("Own team is asked first", func(t *testing.T) {
+		prResp := createPR(t, "pr-fallback-003", "u28020")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Two reviewers should be assigned")
+		assertEqual(t, "u28021", prResp.PR.Reviews[0].UserID, "Teammate should be picked first")
+		assertTrue(t, !prResp.PR.Reviews[0].External, "Teammate should not be external")
+		assertTrue(t, prResp.PR.Reviews[1].External, "Second reviewer should be external")
+	})
+
+	t.Run("Reassign falls back when the team is exhausted", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/reassign", ReassignReviewerRequest{
+			PullRequestID: "pr-fallback-003",
+			OldUserID:     "u28021",
+		})
+		assertEqual(t, http.StatusOK, sc, "Reassign should succeed\n"+string(bodyBytes))
+
+		var reassignResp FallbackReassignResponse
+		err := json.Unmarshal(bodyBytes, &reassignResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal reassign response: %v", err)
+			t.FailNow()
+		}
+		assertTrue(t, slices.Contains(poolMembers, reassignResp.ReplacedBy), "Replacement should come from the pool")
+		for _, review := range reassignResp.PR.Reviews {
+			assertTrue(t, review.External, "Every reviewer should be external")
+		}
+	})
+
+	t.Run("Renamed fallback team is still used", func(t *testing.T) {
+		sc, _ := post(t, "/team/rename", map[string]any{
+			"team_name":     "fallback-pool",
+			"new_team_name": "fallback-pool-renamed",
+		})
+		assertEqual(t, http.StatusOK, sc, "Rename should succeed")
+
+		prResp := createPR(t, "pr-fallback-004", "u28000")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Two reviewers should be assigned")
+	})
+}
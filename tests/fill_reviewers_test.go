@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type FillReviewersResponse struct {
+	PR struct {
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	} `json:"pr"`
+	AddedReviewers []string `json:"added_reviewers"`
+}
+
+type FillTeamReviewersResponse struct {
+	TeamName string `json:"team_name"`
+	Filled   []struct {
+		PullRequestID  string   `json:"pull_request_id"`
+		AddedReviewers []string `json:"added_reviewers"`
+	} `json:"filled"`
+}
+
+func TestFillReviewers(t *testing.T) {
+	fill := func(t *testing.T, prID string) FillReviewersResponse {
+		sc, bodyBytes := post(t, "/pullRequest/fillReviewers", map[string]string{"pull_request_id": prID})
+		assertEqual(t, http.StatusOK, sc, "Filling reviewers should succeed\n"+string(bodyBytes))
+
+		var fillResp FillReviewersResponse
+		err := json.Unmarshal(bodyBytes, &fillResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal fill response: %v", err)
+			t.FailNow()
+		}
+		return fillResp
+	}
+
+	sc, _ := post(t, "/team/add", Team{
+		Name: "fill-team",
+		Members: []TeamMember{
+			{Id: "u29000", Name: "Alice", IsActive: true},
+			{Id: "u29001", Name: "Bob", IsActive: true},
+			{Id: "u29002", Name: "Charlie", IsActive: false},
+		},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	for _, prID := range []string{"pr-fill-001", "pr-fill-002"} {
+		sc, _ := post(t, "/pullRequest/create", CreatePullRequestRequest{
+			PullRequestID:   prID,
+			PullRequestName: "Fill PR",
+			AuthorID:        "u29000",
+		})
+		assertEqual(t, http.StatusCreated, sc, "PR creation should succeed")
+	}
+
+	t.Run("Nothing changes without candidates", func(t *testing.T) {
+		fillResp := fill(t, "pr-fill-001")
+		assertEqual(t, "[]", fmt.Sprint(fillResp.AddedReviewers), "Added reviewers")
+		assertEqual(t, "[u29001]", fmt.Sprint(fillResp.PR.AssignedReviewers), "Assigned reviewers")
+	})
+
+	sc, _ = post(t, "/users/setIsActive", map[string]any{"user_id": "u29002", "is_active": true})
+	assertEqual(t, http.StatusOK, sc, "Activation should succeed")
+
+	t.Run("Returning teammate fills the slot", func(t *testing.T) {
+		fillResp := fill(t, "pr-fill-001")
+		assertEqual(t, "[u29002]", fmt.Sprint(fillResp.AddedReviewers), "Added reviewers")
+		assertEqual(t, "[u29001 u29002]", fmt.Sprint(fillResp.PR.AssignedReviewers), "Assigned reviewers")
+
+		fillResp = fill(t, "pr-fill-001")
+		assertEqual(t, "[]", fmt.Sprint(fillResp.AddedReviewers), "Full PR is not changed")
+	})
+
+	t.Run("Team-wide fill", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/fillReviewers", map[string]string{"team_name": "fill-team"})
+		assertEqual(t, http.StatusOK, sc, "Filling team reviewers should succeed\n"+string(bodyBytes))
+
+		var fillResp FillTeamReviewersResponse
+		err := json.Unmarshal(bodyBytes, &fillResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal fill response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 1, len(fillResp.Filled), "Only the incomplete PR is filled")
+		assertEqual(t, "pr-fill-002", fillResp.Filled[0].PullRequestID, "Filled PR")
+		assertEqual(t, "[u29002]", fmt.Sprint(fillResp.Filled[0].AddedReviewers), "Added reviewers")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		sc, _ := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-fill-002"})
+		assertEqual(t, http.StatusOK, sc, "Merge should succeed")
+
+		sc, _ = post(t, "/pullRequest/fillReviewers", map[string]string{"pull_request_id": "pr-fill-002"})
+		assertEqual(t, http.StatusConflict, sc, "Merged PR should return 409")
+
+		sc, _ = post(t, "/pullRequest/fillReviewers", map[string]string{"pull_request_id": "pr-fill-missing"})
+		assertEqual(t, http.StatusNotFound, sc, "Missing PR should return 404")
+
+		sc, _ = post(t, "/team/fillReviewers", map[string]string{"team_name": "fill-team-missing"})
+		assertEqual(t, http.StatusNotFound, sc, "Missing team should return 404")
+	})
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+type AssignmentStatsResponse struct {
+	TeamName string `json:"team_name"`
+	Users    []struct {
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+		Assigned int    `json:"assigned"`
+		Open     int    `json:"open"`
+		Merged   int    `json:"merged"`
+		Draft    int    `json:"draft"`
+		Closed   int    `json:"closed"`
+	} `json:"users"`
+	PullRequests []struct {
+		PullRequestID  string `json:"pull_request_id"`
+		Status         string `json:"status"`
+		ReviewersCount int    `json:"reviewers_count"`
+	} `json:"pull_requests"`
+}
+
+func TestGetAssignmentStats(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	getStats := func(t *testing.T, url string) AssignmentStatsResponse {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Logf("Failed to get stats: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Logf("Get stats should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+
+		var statsResp AssignmentStatsResponse
+		err = json.Unmarshal(bodyBytes, &statsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal stats response: %v", err)
+			t.FailNow()
+		}
+		return statsResp
+	}
+
+	t.Run("Stats count open and merged reviews", func(t *testing.T) {
+		testTeam := Team{
+			Name: "stats-team",
+			Members: []TeamMember{
+				{Id: "u17000", Name: "Alice", IsActive: true},
+				{Id: "u17001", Name: "Bob", IsActive: true},
+				{Id: "u17002", Name: "Charlie", IsActive: true},
+			},
+		}
+
+		teamJSON, err := json.Marshal(testTeam)
+		if err != nil {
+			t.Log("Failed to marshal team")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(teamJSON))
+		if err != nil {
+			t.Logf("Failed to create team: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Logf("Team creation should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		for _, prID := range []string{"pr-stats-001", "pr-stats-002", "pr-stats-003"} {
+			reqJSON, err := json.Marshal(CreatePullRequestRequest{
+				PullRequestID:   prID,
+				PullRequestName: "Stats PR",
+				AuthorID:        "u17000",
+			})
+			if err != nil {
+				t.Log("Failed to marshal create PR request")
+				t.FailNow()
+			}
+
+			resp, err = http.Post(baseURL+"/pullRequest/create", "application/json", bytes.NewBuffer(reqJSON))
+			if err != nil {
+				t.Logf("Failed to create PR: %v", err)
+				t.FailNow()
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusCreated {
+				t.Logf("PR creation should succeed, got status: %d", resp.StatusCode)
+				t.FailNow()
+			}
+		}
+
+		reqJSON, err := json.Marshal(MergePullRequestRequest{PullRequestID: "pr-stats-001"})
+		if err != nil {
+			t.Log("Failed to marshal merge request")
+			t.FailNow()
+		}
+
+		resp, err = http.Post(baseURL+"/pullRequest/merge", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to merge PR: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Logf("Merge should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		reqJSON, err = json.Marshal(map[string]string{"pull_request_id": "pr-stats-003"})
+		if err != nil {
+			t.Log("Failed to marshal close request")
+			t.FailNow()
+		}
+
+		resp, err = http.Post(baseURL+"/pullRequest/close", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to close PR: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Logf("Close should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		teamStats := getStats(t, baseURL+"/stats/assignments/team?team_name=stats-team")
+
+		assertEqual(t, "stats-team", teamStats.TeamName, "Team name")
+		assertEqual(t, 3, len(teamStats.Users), "Users in team stats")
+		assertEqual(t, 3, len(teamStats.PullRequests), "PRs in team stats")
+
+		for _, user := range teamStats.Users {
+			switch user.UserID {
+			case "u17000":
+				assertEqual(t, 0, user.Assigned, "Author assigned reviews")
+			case "u17001", "u17002":
+				assertEqual(t, 3, user.Assigned, "Reviewer assigned reviews in every status")
+				assertEqual(t, 1, user.Open, "Reviewer open reviews")
+				assertEqual(t, 1, user.Merged, "Reviewer merged reviews")
+				assertEqual(t, 1, user.Closed, "Reviewer closed reviews")
+				assertEqual(t, 0, user.Draft, "Reviewer draft reviews")
+			default:
+				t.Logf("Unexpected user in team stats: %s", user.UserID)
+				t.FailNow()
+			}
+		}
+
+		for _, pr := range teamStats.PullRequests {
+			assertEqual(t, 2, pr.ReviewersCount, "Reviewers per PR")
+		}
+
+		allStats := getStats(t, baseURL+"/stats/assignments")
+		found := false
+		for _, user := range allStats.Users {
+			if user.UserID == "u17001" {
+				found = true
+				assertEqual(t, 3, user.Assigned, "Reviewer assigned reviews in global stats")
+			}
+		}
+		assertTrue(t, found, "Global stats should include team members")
+	})
+
+	t.Run("Stats for non-existent team", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/stats/assignments/team?team_name=no-such-stats-team")
+		sc, _, errResp := processResponse(t, resp, err)
+
+		assertEqual(t, http.StatusNotFound, sc, "Non-existent team should return 404")
+		assertEqual(t, "NOT_FOUND", errResp.Error.Code, "Error code")
+	})
+
+	t.Run("Team stats without team_name", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/stats/assignments/team")
+		sc, _, errResp := processResponse(t, resp, err)
+
+		assertEqual(t, http.StatusBadRequest, sc, "Missing team_name should return 400")
+		assertEqual(t, "BAD_REQUEST", errResp.Error.Code, "Error code")
+	})
+}
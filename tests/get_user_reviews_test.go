@@ -36,7 +36,7 @@ func TestGetUserReviews(t *testing.T) {
 				{Id: "u12000", Name: "Alice", IsActive: true},
 				{Id: "u12001", Name: "Bob", IsActive: true},
 				{Id: "u12002", Name: "Charlie", IsActive: true},
-				{Id: "u12003", Name: "David", IsActive: true},
+				{Id: "u12003", Name: "David", IsActive: false},
 			},
 		}
 
@@ -66,7 +66,7 @@ func TestGetUserReviews(t *testing.T) {
 		}{
 			{"pr-review-001", "Feature A", "u12000"},
 			{"pr-review-002", "Feature B", "u12002"},
-			{"pr-review-003", "Feature C", "u12003"},
+			{"pr-review-003", "Feature C", "u12002"},
 		}
 
 		for _, prData := range prsToCreate {
@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+)
+
+type ReviewerHistoryResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	History       []struct {
+		UserID    string `json:"user_id"`
+		Action    string `json:"action"`
+		ChangedAt string `json:"changed_at"`
+	} `json:"history"`
+}
+
+func TestManualReviewers(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	changeReviewer := func(t *testing.T, path, userID string) (int, PullRequestResponse, string) {
+		sc, bodyBytes := post(t, path, map[string]string{"pull_request_id": "pr-manual-001", "user_id": userID})
+
+		var prResp PullRequestResponse
+		var errResp ErrorResponse
+		if sc == http.StatusOK {
+			err := json.Unmarshal(bodyBytes, &prResp)
+			if err != nil {
+				t.Logf("Failed to unmarshal PR response: %v", err)
+				t.FailNow()
+			}
+		} else {
+			err := json.Unmarshal(bodyBytes, &errResp)
+			if err != nil {
+				t.Logf("Failed to unmarshal error response: %v", err)
+				t.FailNow()
+			}
+		}
+		return sc, prResp, errResp.Error.Code
+	}
+
+	sc, _ := post(t, "/team/add", map[string]any{
+		"team_name": "manual-team",
+		"members": []TeamMember{
+			{Id: "u30000", Name: "Alice", IsActive: true},
+			{Id: "u30001", Name: "Bob", IsActive: true},
+			{Id: "u30002", Name: "Charlie", IsActive: true},
+			{Id: "u30003", Name: "Dave", IsActive: false},
+		},
+		"reviewer_count": 3,
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	sc, _ = post(t, "/team/add", Team{
+		Name: "manual-experts",
+		Members: []TeamMember{
+			{Id: "u30010", Name: "Eve", IsActive: true},
+			{Id: "u30011", Name: "Frank", IsActive: true},
+		},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	sc, _ = post(t, "/pullRequest/create", CreatePullRequestRequest{
+		PullRequestID:   "pr-manual-001",
+		PullRequestName: "Manual PR",
+		AuthorID:        "u30000",
+	})
+	assertEqual(t, http.StatusCreated, sc, "PR creation should succeed")
+
+	t.Run("Add an expert from another team", func(t *testing.T) {
+		sc, prResp, _ := changeReviewer(t, "/pullRequest/addReviewer", "u30010")
+		assertEqual(t, http.StatusOK, sc, "Adding a reviewer should succeed")
+		assertEqual(t, 3, len(prResp.PR.AssignedReviewers), "Reviewers count")
+		assertTrue(t, slices.Contains(prResp.PR.AssignedReviewers, "u30010"), "Expert should be assigned")
+	})
+
+	t.Run("Team reviewer count is respected", func(t *testing.T) {
+		sc, _, code := changeReviewer(t, "/pullRequest/addReviewer", "u30011")
+		assertEqual(t, http.StatusConflict, sc, "Adding over the reviewer count should return 409")
+		assertEqual(t, "REVIEWER_LIMIT", code, "Error code")
+	})
+
+	t.Run("Remove a reviewer", func(t *testing.T) {
+		sc, prResp, _ := changeReviewer(t, "/pullRequest/removeReviewer", "u30001")
+		assertEqual(t, http.StatusOK, sc, "Removing a reviewer should succeed")
+		assertEqual(t, "[u30002 u30010]", fmt.Sprint(prResp.PR.AssignedReviewers), "Assigned reviewers")
+
+		sc, _, code := changeReviewer(t, "/pullRequest/removeReviewer", "u30001")
+		assertEqual(t, http.StatusConflict, sc, "Removing an unassigned reviewer should return 409")
+		assertEqual(t, "NOT_ASSIGNED", code, "Error code")
+	})
+
+	t.Run("Invalid reviewers", func(t *testing.T) {
+		sc, _, code := changeReviewer(t, "/pullRequest/addReviewer", "u30002")
+		assertEqual(t, http.StatusConflict, sc, "Adding an assigned reviewer should return 409")
+		assertEqual(t, "ALREADY_ASSIGNED", code, "Error code")
+
+		sc, _, _ = changeReviewer(t, "/pullRequest/addReviewer", "u30000")
+		assertEqual(t, http.StatusBadRequest, sc, "Adding the author should return 400")
+
+		sc, _, _ = changeReviewer(t, "/pullRequest/addReviewer", "u30003")
+		assertEqual(t, http.StatusBadRequest, sc, "Adding an inactive user should return 400")
+
+		sc, _, _ = changeReviewer(t, "/pullRequest/addReviewer", "u30099")
+		assertEqual(t, http.StatusNotFound, sc, "Adding a missing user should return 404")
+	})
+
+	t.Run("User review limit is respected", func(t *testing.T) {
+		limit := 0
+		sc, _ := post(t, "/users/setReviewLimit", SetUserReviewLimitRequest{UserID: "u30011", MaxOpenReviews: &limit})
+		assertEqual(t, http.StatusOK, sc, "Setting the review limit should succeed")
+
+		sc, _, code := changeReviewer(t, "/pullRequest/addReviewer", "u30011")
+		assertEqual(t, http.StatusConflict, sc, "Adding a user at their limit should return 409")
+		assertEqual(t, "REVIEWER_LIMIT", code, "Error code")
+	})
+
+	t.Run("Changes are recorded in the history", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/pullRequest/reviewerHistory?pull_request_id=pr-manual-001")
+		if err != nil {
+			t.Logf("Failed to get reviewer history: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, http.StatusOK, resp.StatusCode, "Getting the history should succeed")
+
+		var historyResp ReviewerHistoryResponse
+		err = json.NewDecoder(resp.Body).Decode(&historyResp)
+		if err != nil {
+			t.Logf("Failed to decode history response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 2, len(historyResp.History), "History length")
+		assertEqual(t, "u30010", historyResp.History[0].UserID, "First change user")
+		assertEqual(t, "ADDED", historyResp.History[0].Action, "First change action")
+		assertEqual(t, "u30001", historyResp.History[1].UserID, "Second change user")
+		assertEqual(t, "REMOVED", historyResp.History[1].Action, "Second change action")
+
+		missingResp, err := http.Get(baseURL + "/pullRequest/reviewerHistory?pull_request_id=pr-manual-missing")
+		if err != nil {
+			t.Logf("Failed to get reviewer history: %v", err)
+			t.FailNow()
+		}
+		missingResp.Body.Close()
+		assertEqual(t, http.StatusNotFound, missingResp.StatusCode, "Missing PR should return 404")
+	})
+
+	t.Run("Merged PR can't be changed", func(t *testing.T) {
+		sc, _ := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-manual-001"})
+		assertEqual(t, http.StatusOK, sc, "Merge should succeed")
+
+		sc, _, code := changeReviewer(t, "/pullRequest/addReviewer", "u30001")
+		assertEqual(t, http.StatusConflict, sc, "Adding to a merged PR should return 409")
+		assertEqual(t, "PR_MERGED", code, "Error code")
+
+		sc, _, code = changeReviewer(t, "/pullRequest/removeReviewer", "u30002")
+		assertEqual(t, http.StatusConflict, sc, "Removing from a merged PR should return 409")
+		assertEqual(t, "PR_MERGED", code, "Error code")
+	})
+}
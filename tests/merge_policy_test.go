@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type MergeBlockedResponse struct {
+	Error struct {
+		Code    string   `json:"code"`
+		Message string   `json:"message"`
+		Details []string `json:"details"`
+	} `json:"error"`
+}
+
+func TestMergePolicy(t *testing.T) {
+	review := func(t *testing.T, prID, userID, verdict string) {
+		sc, _ := post(t, "/pullRequest/review", map[string]string{
+			"pull_request_id": prID,
+			"user_id":         userID,
+			"verdict":         verdict,
+		})
+		assertEqual(t, http.StatusOK, sc, "Review should succeed")
+	}
+
+	expectBlocked := func(t *testing.T, body map[string]string) MergeBlockedResponse {
+		sc, bodyBytes := post(t, "/pullRequest/merge", body)
+		assertEqual(t, http.StatusConflict, sc, "Merge should be blocked")
+
+		var blockedResp MergeBlockedResponse
+		err := json.Unmarshal(bodyBytes, &blockedResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal error response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "MERGE_BLOCKED", blockedResp.Error.Code, "Error code")
+		return blockedResp
+	}
+
+	sc, _ := post(t, "/team/add", Team{
+		Name: "policy-team",
+		Members: []TeamMember{
+			{Id: "u26000", Name: "Alice", IsActive: true},
+			{Id: "u26001", Name: "Bob", IsActive: true},
+			{Id: "u26002", Name: "Charlie", IsActive: true},
+		},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	t.Run("Invalid policies", func(t *testing.T) {
+		sc, _ := post(t, "/team/setMergePolicy", map[string]any{"team_name": "policy-team", "min_approvals": -1})
+		assertEqual(t, http.StatusBadRequest, sc, "Negative approvals should return 400")
+
+		sc, _ = post(t, "/team/setMergePolicy", map[string]any{"team_name": "policy-team", "lead_ids": []string{"u10000"}})
+		assertEqual(t, http.StatusBadRequest, sc, "Lead outside the team should return 400")
+
+		sc, _ = post(t, "/team/setMergePolicy", map[string]any{"team_name": "policy-team-missing"})
+		assertEqual(t, http.StatusNotFound, sc, "Missing team should return 404")
+	})
+
+	sc, bodyBytes := post(t, "/team/setMergePolicy", map[string]any{
+		"team_name":                  "policy-team",
+		"min_approvals":              2,
+		"block_on_changes_requested": true,
+		"lead_ids":                   []string{"u26001"},
+	})
+	assertEqual(t, http.StatusOK, sc, "Setting the policy should succeed\n"+string(bodyBytes))
+
+	t.Run("Unreviewed PR is blocked", func(t *testing.T) {
+		createPR(t, "pr-policy-001", "u26000")
+
+		blockedResp := expectBlocked(t, map[string]string{"pull_request_id": "pr-policy-001"})
+		assertEqual(t, "[needs 2 approvals, has 0]", fmt.Sprint(blockedResp.Error.Details), "Unmet conditions")
+	})
+
+	t.Run("Every unmet condition is listed", func(t *testing.T) {
+		review(t, "pr-policy-001", "u26001", "APPROVED")
+		review(t, "pr-policy-001", "u26002", "CHANGES_REQUESTED")
+
+		blockedResp := expectBlocked(t, map[string]string{"pull_request_id": "pr-policy-001"})
+		assertEqual(t, "[needs 2 approvals, has 1 changes requested by u26002]", fmt.Sprint(blockedResp.Error.Details), "Unmet conditions")
+	})
+
+	t.Run("Only leads bypass the policy", func(t *testing.T) {
+		expectBlocked(t, map[string]string{"pull_request_id": "pr-policy-001", "user_id": "u26002"})
+
+		sc, _ := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-policy-001", "user_id": "u26001"})
+		assertEqual(t, http.StatusOK, sc, "Lead should merge")
+	})
+
+	t.Run("PR meeting the policy is merged", func(t *testing.T) {
+		createPR(t, "pr-policy-002", "u26000")
+		review(t, "pr-policy-002", "u26001", "APPROVED")
+		review(t, "pr-policy-002", "u26002", "APPROVED")
+
+		sc, _ := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-policy-002"})
+		assertEqual(t, http.StatusOK, sc, "Approved PR should merge")
+	})
+
+	t.Run("Verdicts of unassigned reviewers do not count", func(t *testing.T) {
+		createPR(t, "pr-policy-003", "u26000")
+		review(t, "pr-policy-003", "u26001", "APPROVED")
+		review(t, "pr-policy-003", "u26002", "APPROVED")
+
+		// The draft releases both reviewers, ready picks the same two again
+		sc, _ := post(t, "/pullRequest/draft", map[string]string{"pull_request_id": "pr-policy-003"})
+		assertEqual(t, http.StatusOK, sc, "Converting to draft should succeed")
+		sc, bodyBytes := post(t, "/pullRequest/ready", map[string]string{"pull_request_id": "pr-policy-003"})
+		assertEqual(t, http.StatusOK, sc, "Marking ready should succeed")
+		var prResp PullRequestResponse
+		decode(t, bodyBytes, &prResp)
+		slices.Sort(prResp.PR.AssignedReviewers)
+		assertEqual(t, "[u26001 u26002]", fmt.Sprint(prResp.PR.AssignedReviewers), "Same reviewers are picked again")
+		for _, state := range prResp.PR.Reviews {
+			assertEqual(t, "PENDING", state.State, "Verdict of "+state.UserID+" is dropped")
+		}
+
+		blockedResp := expectBlocked(t, map[string]string{"pull_request_id": "pr-policy-003"})
+		assertEqual(t, "[needs 2 approvals, has 0]", fmt.Sprint(blockedResp.Error.Details), "Unmet conditions")
+
+		review(t, "pr-policy-003", "u26001", "APPROVED")
+		review(t, "pr-policy-003", "u26002", "APPROVED")
+		sc, _ = post(t, "/pullRequest/removeReviewer", map[string]string{"pull_request_id": "pr-policy-003", "user_id": "u26002"})
+		assertEqual(t, http.StatusOK, sc, "Removing the reviewer should succeed")
+		sc, _ = post(t, "/pullRequest/addReviewer", map[string]string{"pull_request_id": "pr-policy-003", "user_id": "u26002"})
+		assertEqual(t, http.StatusOK, sc, "Adding the reviewer back should succeed")
+
+		blockedResp = expectBlocked(t, map[string]string{"pull_request_id": "pr-policy-003"})
+		assertEqual(t, "[needs 2 approvals, has 1]", fmt.Sprint(blockedResp.Error.Details), "Unmet conditions")
+	})
+}
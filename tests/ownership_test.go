@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type OwnershipSettingsResponse struct {
+	TeamName       string `json:"team_name"`
+	OwnershipRules []struct {
+		Pattern string   `json:"pattern"`
+		Owners  []string `json:"owners"`
+	} `json:"ownership_rules"`
+}
+
+func TestOwnershipRules(t *testing.T) {
+	teams := []Team{
+		{Name: "ownership-core", Members: []TeamMember{
+			{Id: "u32000", Name: "Alice", IsActive: true},
+			{Id: "u32001", Name: "Bob", IsActive: true},
+			{Id: "u32002", Name: "Charlie", IsActive: true},
+			{Id: "u32003", Name: "Dave", IsActive: true},
+		}},
+		{Name: "ownership-docs", Members: []TeamMember{
+			{Id: "u32010", Name: "Eve", IsActive: true},
+		}},
+	}
+	for _, team := range teams {
+		sc, _ := post(t, "/team/add", team)
+		assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+	}
+
+	t.Run("Invalid ownership rules", func(t *testing.T) {
+		sc, _ := post(t, "/team/setOwnershipRules", map[string]any{
+			"team_name":  "ownership-core",
+			"rules":      []map[string]any{{"pattern": "*.go", "owners": []string{"u32001"}}},
+			"codeowners": "*.go u32001",
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Rules together with codeowners should return 400")
+
+		sc, _ = post(t, "/team/setOwnershipRules", map[string]any{
+			"team_name": "ownership-core",
+			"rules":     []map[string]any{{"pattern": "*.go", "owners": []string{"u32999"}}},
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Missing owner should return 400")
+
+		sc, _ = post(t, "/team/setOwnershipRules", map[string]any{
+			"team_name":  "ownership-core",
+			"codeowners": "[ u32001",
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Malformed pattern should return 400")
+
+		sc, _ = post(t, "/team/setOwnershipRules", map[string]any{
+			"team_name": "ownership-missing",
+			"rules":     []map[string]any{},
+		})
+		assertEqual(t, http.StatusNotFound, sc, "Missing team should return 404")
+	})
+
+	t.Run("Upload CODEOWNERS", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/setOwnershipRules", map[string]any{
+			"team_name":  "ownership-core",
+			"codeowners": "# Go code\n*.go @u32001\n\n/docs/ @u32010\n",
+		})
+		assertEqual(t, http.StatusOK, sc, "Setting ownership rules should succeed\n"+string(bodyBytes))
+
+		var settingsResp OwnershipSettingsResponse
+		err := json.Unmarshal(bodyBytes, &settingsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal settings response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 2, len(settingsResp.OwnershipRules), "Two rules should be stored")
+		assertEqual(t, "*.go", settingsResp.OwnershipRules[0].Pattern, "First rule pattern")
+		assertEqual(t, "u32001", settingsResp.OwnershipRules[0].Owners[0], "Leading @ should be dropped")
+	})
+
+	t.Run("Owner of the changed paths is picked first", func(t *testing.T) {
+		prResp := createPR(t, "pr-ownership-001", "u32000", "cmd/main.go", "README.md")
+		assertEqual(t, 2, len(prResp.PR.Reviews), "Two reviewers should be assigned")
+
+		owner := prResp.PR.Reviews[0]
+		assertEqual(t, "u32001", owner.UserID, "Owner should be picked first")
+		assertEqual(t, "OWNER", owner.Reason, "Owner reason")
+		assertEqual(t, 1, len(owner.OwnedPaths), "Owner owns one path")
+		assertEqual(t, "cmd/main.go", owner.OwnedPaths[0], "Owned path")
+
+		teammate := prResp.PR.Reviews[1]
+		assertEqual(t, "TEAM_MEMBER", teammate.Reason, "Teammate reason")
+		assertEqual(t, 0, len(teammate.OwnedPaths), "Teammate owns no paths")
+	})
+
+	t.Run("Owner from another team", func(t *testing.T) {
+		prResp := createPR(t, "pr-ownership-002", "u32000", "docs/guide.md")
+		assertEqual(t, 2, len(prResp.PR.Reviews), "Two reviewers should be assigned")
+		assertEqual(t, "u32010", prResp.PR.Reviews[0].UserID, "Docs owner should be picked first")
+		assertEqual(t, "OWNER", prResp.PR.Reviews[0].Reason, "Owner reason")
+		assertEqual(t, "TEAM_MEMBER", prResp.PR.Reviews[1].Reason, "Teammate reason")
+	})
+
+	t.Run("Author is not picked as an owner", func(t *testing.T) {
+		prResp := createPR(t, "pr-ownership-003", "u32001", "cmd/main.go")
+		assertEqual(t, 2, len(prResp.PR.Reviews), "Two reviewers should be assigned")
+		for _, review := range prResp.PR.Reviews {
+			assertTrue(t, review.UserID != "u32001", "Author should not review")
+			assertEqual(t, "TEAM_MEMBER", review.Reason, "Teammate reason")
+		}
+	})
+
+	t.Run("Inactive owner is skipped", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/users/setIsActive", map[string]any{"user_id": "u32010", "is_active": false})
+		assertEqual(t, http.StatusOK, sc, "Deactivation should succeed\n"+string(bodyBytes))
+
+		prResp := createPR(t, "pr-ownership-004", "u32000", "docs/guide.md")
+		for _, review := range prResp.PR.Reviews {
+			assertTrue(t, review.UserID != "u32010", "Inactive owner should not review")
+			assertEqual(t, "TEAM_MEMBER", review.Reason, "Teammate reason")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestPullRequestLifecycle(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	transition := func(t *testing.T, action string) PullRequestResponse {
+		sc, bodyBytes := post(t, "/pullRequest/"+action, map[string]string{"pull_request_id": "pr-lifecycle-001"})
+		if sc != http.StatusOK {
+			t.Logf("%s should succeed, got status: %d\n%s", action, sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var prResp PullRequestResponse
+		err := json.Unmarshal(bodyBytes, &prResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+		return prResp
+	}
+
+	expectNotModifiable := func(t *testing.T, path string, body any) {
+		sc, bodyBytes := post(t, path, body)
+		assertEqual(t, http.StatusConflict, sc, path+" should return 409")
+
+		var errorResp ErrorResponse
+		err := json.Unmarshal(bodyBytes, &errorResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal error response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "PR_NOT_MODIFIABLE", errorResp.Error.Code, "Error code")
+	}
+
+	isReviewing := func(t *testing.T, userID string) bool {
+		resp, err := http.Get(baseURL + "/users/getReview?user_id=" + userID)
+		if err != nil {
+			t.Logf("Failed to get reviews: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		var reviewsResp GetUserReviewsResponse
+		err = json.NewDecoder(resp.Body).Decode(&reviewsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal reviews response: %v", err)
+			t.FailNow()
+		}
+		for _, pr := range reviewsResp.PullRequests {
+			if pr.PullRequestID == "pr-lifecycle-001" {
+				return true
+			}
+		}
+		return false
+	}
+
+	sc, _ := post(t, "/team/add", Team{
+		Name: "lifecycle-team",
+		Members: []TeamMember{
+			{Id: "u24000", Name: "Alice", IsActive: true},
+			{Id: "u24001", Name: "Bob", IsActive: true},
+			{Id: "u24002", Name: "Charlie", IsActive: true},
+		},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	t.Run("Draft gets reviewers when ready", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/create", map[string]any{
+			"pull_request_id":   "pr-lifecycle-001",
+			"pull_request_name": "Lifecycle PR",
+			"author_id":         "u24000",
+			"draft":             true,
+		})
+		if sc != http.StatusCreated {
+			t.Logf("Draft creation should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var prResp PullRequestResponse
+		err := json.Unmarshal(bodyBytes, &prResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "DRAFT", prResp.PR.Status, "Created status")
+		assertEqual(t, 0, len(prResp.PR.AssignedReviewers), "Draft has no reviewers")
+
+		expectNotModifiable(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-lifecycle-001"})
+
+		prResp = transition(t, "ready")
+		assertEqual(t, "OPEN", prResp.PR.Status, "Ready status")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Reviewers are assigned")
+
+		again := transition(t, "ready")
+		assertEqual(t, fmt.Sprint(prResp.PR.AssignedReviewers), fmt.Sprint(again.PR.AssignedReviewers), "Ready is idempotent")
+	})
+
+	t.Run("Converting to draft releases reviewers", func(t *testing.T) {
+		prResp := transition(t, "draft")
+		assertEqual(t, "DRAFT", prResp.PR.Status, "Draft status")
+		assertEqual(t, 0, len(prResp.PR.AssignedReviewers), "Reviewers are released")
+		assertTrue(t, !isReviewing(t, "u24001"), "Draft is not in user reviews")
+
+		prResp = transition(t, "ready")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Reviewers are assigned again")
+	})
+
+	t.Run("Closed PR is hidden from reviews and can be reopened", func(t *testing.T) {
+		prResp := transition(t, "close")
+		assertEqual(t, "CLOSED", prResp.PR.Status, "Closed status")
+		assertEqual(t, 2, len(prResp.PR.AssignedReviewers), "Reviewers are kept")
+		assertTrue(t, !isReviewing(t, "u24001"), "Closed PR is not in user reviews")
+
+		expectNotModifiable(t, "/pullRequest/reassign", map[string]string{
+			"pull_request_id": "pr-lifecycle-001",
+			"old_user_id":     "u24001",
+		})
+		expectNotModifiable(t, "/pullRequest/ready", map[string]string{"pull_request_id": "pr-lifecycle-001"})
+
+		reopened := transition(t, "reopen")
+		assertEqual(t, "OPEN", reopened.PR.Status, "Reopened status")
+		assertEqual(t, fmt.Sprint(prResp.PR.AssignedReviewers), fmt.Sprint(reopened.PR.AssignedReviewers), "Reviewers are restored")
+		assertTrue(t, isReviewing(t, "u24001"), "Reopened PR is in user reviews")
+	})
+
+	t.Run("Merged PR cannot change", func(t *testing.T) {
+		prResp := transition(t, "merge")
+		assertEqual(t, "MERGED", prResp.PR.Status, "Merged status")
+
+		for _, action := range []string{"close", "reopen", "draft", "ready"} {
+			expectNotModifiable(t, "/pullRequest/"+action, map[string]string{"pull_request_id": "pr-lifecycle-001"})
+		}
+	})
+
+	t.Run("Transition of non-existent PR", func(t *testing.T) {
+		sc, _ := post(t, "/pullRequest/close", map[string]string{"pull_request_id": "pr-lifecycle-missing"})
+		assertEqual(t, http.StatusNotFound, sc, "Missing PR should return 404")
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -24,6 +25,7 @@ type ReassignReviewerResponse struct {
 		AssignedReviewers []string `json:"assigned_reviewers"`
 		CreatedAt         *string  `json:"createdAt,omitempty"`
 		MergedAt          *string  `json:"mergedAt,omitempty"`
+		Version           int      `json:"version"`
 	} `json:"pr"`
 	ReplacedBy string `json:"replaced_by"`
 }
@@ -561,4 +563,112 @@ func TestReassignReviewer(t *testing.T) {
 			t.FailNow()
 		}
 	})
+
+	t.Run("Concurrent reassigns keep reviewers consistent", func(t *testing.T) {
+		testTeam := Team{
+			Name: "concurrent-reassign-team",
+			Members: []TeamMember{
+				{Id: "u19000", Name: "Alice", IsActive: true},
+				{Id: "u19001", Name: "Bob", IsActive: true},
+				{Id: "u19002", Name: "Charlie", IsActive: true},
+				{Id: "u19003", Name: "David", IsActive: true},
+				{Id: "u19004", Name: "Eve", IsActive: true},
+				{Id: "u19005", Name: "Frank", IsActive: true},
+			},
+		}
+
+		teamJSON, err := json.Marshal(testTeam)
+		if err != nil {
+			t.Log("Failed to marshal team")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(teamJSON))
+		if err != nil {
+			t.Logf("Failed to create team: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Logf("Team creation should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		reqJSON, err := json.Marshal(CreatePullRequestRequest{
+			PullRequestID:   "pr-reassign-concurrent",
+			PullRequestName: "Concurrent reassign PR",
+			AuthorID:        "u19000",
+		})
+		if err != nil {
+			t.Log("Failed to marshal create PR request")
+			t.FailNow()
+		}
+
+		resp, err = http.Post(baseURL+"/pullRequest/create", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to create PR: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+
+		var prResp PullRequestResponse
+		err = json.Unmarshal(bodyBytes, &prResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+
+		if len(prResp.PR.AssignedReviewers) != 2 {
+			t.Logf("Should have 2 reviewers, got %d", len(prResp.PR.AssignedReviewers))
+			t.FailNow()
+		}
+
+		// Both requests try to replace the same reviewer, only one of them can win
+		reqJSON, err = json.Marshal(ReassignReviewerRequest{
+			PullRequestID: "pr-reassign-concurrent",
+			OldUserID:     prResp.PR.AssignedReviewers[0],
+		})
+		if err != nil {
+			t.Log("Failed to marshal reassign request")
+			t.FailNow()
+		}
+
+		var wg sync.WaitGroup
+		statuses := make([]int, 5)
+		for i := range statuses {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := http.Post(baseURL+"/pullRequest/reassign", "application/json", bytes.NewBuffer(reqJSON))
+				if err != nil {
+					return
+				}
+				defer resp.Body.Close()
+				statuses[i] = resp.StatusCode
+			}()
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, status := range statuses {
+			if status == http.StatusOK {
+				succeeded++
+			} else if status != http.StatusConflict {
+				t.Logf("Concurrent reassign should return 200 or 409, got %d", status)
+				t.FailNow()
+			}
+		}
+
+		if succeeded != 1 {
+			t.Logf("Exactly one concurrent reassign should succeed, got %d", succeeded)
+			t.FailNow()
+		}
+	})
 }
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestReviewerCount(t *testing.T) {
+	sc, bodyBytes := post(t, "/team/add", map[string]any{
+		"team_name": "count-team",
+		"members": []TeamMember{
+			{Id: "u27000", Name: "Alice", IsActive: true},
+			{Id: "u27001", Name: "Bob", IsActive: true},
+			{Id: "u27002", Name: "Charlie", IsActive: true},
+			{Id: "u27003", Name: "Dave", IsActive: true},
+			{Id: "u27004", Name: "Eve", IsActive: true},
+		},
+		"reviewer_count": 3,
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed\n"+string(bodyBytes))
+
+	t.Run("Team gets the configured number of reviewers", func(t *testing.T) {
+		prResp := createPR(t, "pr-count-001", "u27000")
+		assertEqual(t, 3, len(prResp.PR.AssignedReviewers), "Three reviewers should be assigned")
+	})
+
+	t.Run("Invalid counts are rejected", func(t *testing.T) {
+		sc, _ := post(t, "/team/setReviewerCount", map[string]any{"team_name": "count-team", "reviewer_count": 0})
+		assertEqual(t, http.StatusBadRequest, sc, "Zero reviewers should return 400")
+
+		sc, _ = post(t, "/team/setReviewerCount", map[string]any{"team_name": "count-team", "reviewer_count": 11})
+		assertEqual(t, http.StatusBadRequest, sc, "Too many reviewers should return 400")
+
+		sc, _ = post(t, "/team/setReviewerCount", map[string]any{"team_name": "count-team-missing", "reviewer_count": 1})
+		assertEqual(t, http.StatusNotFound, sc, "Missing team should return 404")
+
+		sc, _ = post(t, "/team/add", map[string]any{
+			"team_name":      "count-team-invalid",
+			"members":        []TeamMember{{Id: "u27010", Name: "Frank", IsActive: true}},
+			"reviewer_count": 11,
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Team with too many reviewers should return 400")
+	})
+
+	t.Run("Count is changed per team", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/setReviewerCount", map[string]any{"team_name": "count-team", "reviewer_count": 1})
+		assertEqual(t, http.StatusOK, sc, "Setting the count should succeed")
+
+		var settingsResp TeamSettingsResponse
+		err := json.Unmarshal(bodyBytes, &settingsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal settings response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 1, settingsResp.ReviewerCount, "Reviewer count")
+
+		prResp := createPR(t, "pr-count-002", "u27000")
+		assertEqual(t, 1, len(prResp.PR.AssignedReviewers), "One reviewer should be assigned")
+	})
+
+	t.Run("Merge policy cannot require more approvals than reviewers", func(t *testing.T) {
+		sc, _ := post(t, "/team/setMergePolicy", map[string]any{"team_name": "count-team", "min_approvals": 2})
+		assertEqual(t, http.StatusBadRequest, sc, "Policy above the reviewer count should return 400")
+
+		sc, _ = post(t, "/team/setMergePolicy", map[string]any{"team_name": "count-team", "min_approvals": 1})
+		assertEqual(t, http.StatusOK, sc, "Policy within the reviewer count should succeed")
+
+		sc, _ = post(t, "/team/setReviewerCount", map[string]any{"team_name": "count-team", "reviewer_count": 1})
+		assertEqual(t, http.StatusOK, sc, "Count equal to the required approvals should succeed")
+	})
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+type SetReviewerStrategyRequest struct {
+	TeamName         string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy"`
+}
+
+type TeamSettingsResponse struct {
+	TeamName         string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy"`
+	ReviewerCount    int    `json:"reviewer_count"`
+}
+
+func TestSetReviewerStrategy(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	t.Run("Round robin rotates reviewers", func(t *testing.T) {
+		testTeam := Team{
+			Name: "round-robin-team",
+			Members: []TeamMember{
+				{Id: "u15000", Name: "Alice", IsActive: true},
+				{Id: "u15001", Name: "Bob", IsActive: true},
+				{Id: "u15002", Name: "Charlie", IsActive: true},
+				{Id: "u15003", Name: "David", IsActive: true},
+			},
+		}
+
+		teamJSON, err := json.Marshal(testTeam)
+		if err != nil {
+			t.Log("Failed to marshal team")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(teamJSON))
+		if err != nil {
+			t.Logf("Failed to create team: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Logf("Team creation should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		reqJSON, err := json.Marshal(SetReviewerStrategyRequest{
+			TeamName:         "round-robin-team",
+			ReviewerStrategy: "ROUND_ROBIN",
+		})
+		if err != nil {
+			t.Log("Failed to marshal strategy request")
+			t.FailNow()
+		}
+
+		resp, err = http.Post(baseURL+"/team/setReviewerStrategy", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to set strategy: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Logf("Setting strategy should succeed, got status: %d", resp.StatusCode)
+			t.FailNow()
+		}
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+
+		var settingsResp TeamSettingsResponse
+		err = json.Unmarshal(bodyBytes, &settingsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal settings response: %v", err)
+			t.FailNow()
+		}
+
+		assertEqual(t, "ROUND_ROBIN", settingsResp.ReviewerStrategy, "Strategy should be updated")
+
+		// Candidates are u15001, u15002, u15003 in this order
+		first := createPR(t, "pr-round-robin-001", "u15000")
+		second := createPR(t, "pr-round-robin-002", "u15000")
+
+		assertEqual(t, 2, len(first.PR.AssignedReviewers), "First PR reviewers count")
+		assertEqual(t, "u15001", first.PR.AssignedReviewers[0], "First PR first reviewer")
+		assertEqual(t, "u15002", first.PR.AssignedReviewers[1], "First PR second reviewer")
+
+		assertEqual(t, 2, len(second.PR.AssignedReviewers), "Second PR reviewers count")
+		assertEqual(t, "u15003", second.PR.AssignedReviewers[0], "Second PR first reviewer")
+		assertEqual(t, "u15001", second.PR.AssignedReviewers[1], "Second PR second reviewer")
+	})
+
+	t.Run("Create team with unknown strategy", func(t *testing.T) {
+		teamJSON, err := json.Marshal(map[string]any{
+			"team_name":         "unknown-strategy-team",
+			"reviewer_strategy": "ALPHABETICAL",
+			"members": []TeamMember{
+				{Id: "u15100", Name: "Eve", IsActive: true},
+			},
+		})
+		if err != nil {
+			t.Log("Failed to marshal team")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/add", "application/json", bytes.NewBuffer(teamJSON))
+		sc, _, errResp := processResponse(t, resp, err)
+
+		assertEqual(t, http.StatusBadRequest, sc, "Unknown strategy should be rejected")
+		assertEqual(t, "BAD_REQUEST", errResp.Error.Code, "Error code")
+	})
+
+	t.Run("Set strategy for non-existent team", func(t *testing.T) {
+		reqJSON, err := json.Marshal(SetReviewerStrategyRequest{
+			TeamName:         "no-such-strategy-team",
+			ReviewerStrategy: "RANDOM",
+		})
+		if err != nil {
+			t.Log("Failed to marshal strategy request")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/team/setReviewerStrategy", "application/json", bytes.NewBuffer(reqJSON))
+		sc, _, errResp := processResponse(t, resp, err)
+
+		assertEqual(t, http.StatusNotFound, sc, "Non-existent team should return 404")
+		assertEqual(t, "NOT_FOUND", errResp.Error.Code, "Error code")
+	})
+}
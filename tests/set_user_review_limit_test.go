@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+)
+
+type SetUserReviewLimitRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type SetUserReviewLimitResponse struct {
+	User struct {
+		UserID         string `json:"user_id"`
+		MaxOpenReviews *int   `json:"max_open_reviews,omitempty"`
+	} `json:"user"`
+}
+
+func TestSetUserReviewLimit(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	setLimit := func(t *testing.T, userID string, limit *int) (int, []byte) {
+		reqJSON, err := json.Marshal(SetUserReviewLimitRequest{UserID: userID, MaxOpenReviews: limit})
+		if err != nil {
+			t.Log("Failed to marshal review limit request")
+			t.FailNow()
+		}
+
+		resp, err := http.Post(baseURL+"/users/setReviewLimit", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			t.Logf("Failed to set review limit: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+		return resp.StatusCode, bodyBytes
+	}
+
+	t.Run("Least loaded spreads reviews", func(t *testing.T) {
+		createTeam(t, map[string]any{
+			"team_name":         "least-loaded-team",
+			"reviewer_strategy": "LEAST_LOADED",
+			"members": []TeamMember{
+				{Id: "u16000", Name: "Alice", IsActive: true},
+				{Id: "u16001", Name: "Bob", IsActive: true},
+				{Id: "u16002", Name: "Charlie", IsActive: true},
+				{Id: "u16003", Name: "David", IsActive: true},
+				{Id: "u16004", Name: "Eve", IsActive: true},
+			},
+		})
+
+		first := createPR(t, "pr-least-loaded-001", "u16000").PR.AssignedReviewers
+		second := createPR(t, "pr-least-loaded-002", "u16000").PR.AssignedReviewers
+
+		assertEqual(t, 2, len(first), "First PR reviewers count")
+		assertEqual(t, 2, len(second), "Second PR reviewers count")
+
+		for _, reviewer := range second {
+			if slices.Contains(first, reviewer) {
+				t.Logf("Reviewer %s got a second review while others had none", reviewer)
+				t.FailNow()
+			}
+		}
+	})
+
+	t.Run("Users over the cap are skipped", func(t *testing.T) {
+		createTeam(t, map[string]any{
+			"team_name":         "capped-team",
+			"reviewer_strategy": "LEAST_LOADED",
+			"members": []TeamMember{
+				{Id: "u16100", Name: "Frank", IsActive: true},
+				{Id: "u16101", Name: "Grace", IsActive: true},
+				{Id: "u16102", Name: "Henry", IsActive: true},
+			},
+		})
+
+		limit := 0
+		sc, bodyBytes := setLimit(t, "u16101", &limit)
+		assertEqual(t, http.StatusOK, sc, "Setting review limit should succeed")
+
+		var limitResp SetUserReviewLimitResponse
+		err := json.Unmarshal(bodyBytes, &limitResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal review limit response: %v", err)
+			t.FailNow()
+		}
+		assertTrue(t, limitResp.User.MaxOpenReviews != nil, "Review limit should be returned")
+		assertEqual(t, 0, *limitResp.User.MaxOpenReviews, "Review limit value")
+
+		reviewers := createPR(t, "pr-capped-001", "u16100").PR.AssignedReviewers
+		assertEqual(t, 1, len(reviewers), "Only one reviewer is under the cap")
+		assertEqual(t, "u16102", reviewers[0], "Capped user should be skipped")
+
+		sc, _ = setLimit(t, "u16101", nil)
+		assertEqual(t, http.StatusOK, sc, "Clearing review limit should succeed")
+
+		reviewers = createPR(t, "pr-capped-002", "u16100").PR.AssignedReviewers
+		assertEqual(t, 2, len(reviewers), "Both reviewers are available after clearing the cap")
+	})
+
+	t.Run("Negative review limit", func(t *testing.T) {
+		limit := -1
+		sc, _ := setLimit(t, "u16101", &limit)
+		assertEqual(t, http.StatusBadRequest, sc, "Negative limit should be rejected")
+	})
+
+	t.Run("Review limit for non-existent user", func(t *testing.T) {
+		limit := 3
+		sc, _ := setLimit(t, "no-such-user-16", &limit)
+		assertEqual(t, http.StatusNotFound, sc, "Non-existent user should return 404")
+	})
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSubmitReview(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	review := func(t *testing.T, userID, verdict string) (int, PullRequestResponse) {
+		sc, bodyBytes := post(t, "/pullRequest/review", map[string]string{
+			"pull_request_id": "pr-verdict-001",
+			"user_id":         userID,
+			"verdict":         verdict,
+		})
+
+		var prResp PullRequestResponse
+		if sc == http.StatusOK {
+			err := json.Unmarshal(bodyBytes, &prResp)
+			if err != nil {
+				t.Logf("Failed to unmarshal PR response: %v", err)
+				t.FailNow()
+			}
+		}
+		return sc, prResp
+	}
+
+	stateOf := func(prResp PullRequestResponse, userID string) ReviewerState {
+		for _, state := range prResp.PR.Reviews {
+			if state.UserID == userID {
+				return state
+			}
+		}
+		t.Logf("No review state for %s", userID)
+		t.FailNow()
+		return ReviewerState{}
+	}
+
+	awaiting := func(t *testing.T, userID string) []string {
+		resp, err := http.Get(baseURL + "/users/getReview?awaiting=true&user_id=" + userID)
+		if err != nil {
+			t.Logf("Failed to get reviews: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		var reviewsResp GetUserReviewsResponse
+		err = json.NewDecoder(resp.Body).Decode(&reviewsResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal reviews response: %v", err)
+			t.FailNow()
+		}
+
+		prIDs := []string{}
+		for _, pr := range reviewsResp.PullRequests {
+			prIDs = append(prIDs, pr.PullRequestID)
+		}
+		return prIDs
+	}
+
+	sc, _ := post(t, "/team/add", Team{
+		Name: "verdict-team",
+		Members: []TeamMember{
+			{Id: "u25000", Name: "Alice", IsActive: true},
+			{Id: "u25001", Name: "Bob", IsActive: true},
+			{Id: "u25002", Name: "Charlie", IsActive: true},
+		},
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	sc, bodyBytes := post(t, "/pullRequest/create", CreatePullRequestRequest{
+		PullRequestID:   "pr-verdict-001",
+		PullRequestName: "Review PR",
+		AuthorID:        "u25000",
+	})
+	assertEqual(t, http.StatusCreated, sc, "PR creation should succeed")
+
+	var created PullRequestResponse
+	err := json.Unmarshal(bodyBytes, &created)
+	if err != nil {
+		t.Logf("Failed to unmarshal PR response: %v", err)
+		t.FailNow()
+	}
+
+	t.Run("New PR has pending reviews", func(t *testing.T) {
+		assertEqual(t, 2, len(created.PR.Reviews), "Review states count")
+		for _, state := range created.PR.Reviews {
+			assertEqual(t, "PENDING", state.State, "State of "+state.UserID)
+		}
+		assertEqual(t, "[pr-verdict-001]", fmt.Sprint(awaiting(t, "u25001")), "PR awaits review")
+	})
+
+	t.Run("Comment keeps the review awaited", func(t *testing.T) {
+		sc, prResp := review(t, "u25001", "COMMENTED")
+		assertEqual(t, http.StatusOK, sc, "Review should succeed")
+		assertEqual(t, "COMMENTED", stateOf(prResp, "u25001").State, "Commented state")
+		assertTrue(t, stateOf(prResp, "u25001").SubmittedAt != nil, "Submission time")
+		assertEqual(t, "PENDING", stateOf(prResp, "u25002").State, "Other reviewer state")
+		assertEqual(t, "[pr-verdict-001]", fmt.Sprint(awaiting(t, "u25001")), "PR still awaits review")
+	})
+
+	t.Run("Approve replaces the verdict", func(t *testing.T) {
+		sc, prResp := review(t, "u25001", "APPROVED")
+		assertEqual(t, http.StatusOK, sc, "Review should succeed")
+		assertEqual(t, "APPROVED", stateOf(prResp, "u25001").State, "Approved state")
+		assertEqual(t, "[]", fmt.Sprint(awaiting(t, "u25001")), "Approved PR does not await review")
+
+		sc, prResp = review(t, "u25002", "CHANGES_REQUESTED")
+		assertEqual(t, http.StatusOK, sc, "Review should succeed")
+		assertEqual(t, "CHANGES_REQUESTED", stateOf(prResp, "u25002").State, "Changes requested state")
+		assertEqual(t, "APPROVED", stateOf(prResp, "u25001").State, "Other verdict is kept")
+	})
+
+	t.Run("Invalid reviews", func(t *testing.T) {
+		sc, _ := review(t, "u25001", "LGTM")
+		assertEqual(t, http.StatusBadRequest, sc, "Unknown verdict should return 400")
+
+		sc, _ = review(t, "u25000", "APPROVED")
+		assertEqual(t, http.StatusConflict, sc, "Author is not an assigned reviewer")
+
+		sc, _ = post(t, "/pullRequest/review", map[string]string{
+			"pull_request_id": "pr-verdict-missing",
+			"user_id":         "u25001",
+			"verdict":         "APPROVED",
+		})
+		assertEqual(t, http.StatusNotFound, sc, "Missing PR should return 404")
+	})
+
+	t.Run("Merged PR keeps verdicts and cannot be reviewed", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-verdict-001"})
+		assertEqual(t, http.StatusOK, sc, "Merge should succeed")
+
+		var merged PullRequestResponse
+		err := json.Unmarshal(bodyBytes, &merged)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "APPROVED", stateOf(merged, "u25001").State, "Verdict after merge")
+
+		sc, _ = review(t, "u25002", "APPROVED")
+		assertEqual(t, http.StatusConflict, sc, "Merged PR cannot be reviewed")
+	})
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+)
+
+type RosterTeam struct {
+	Name    string       `json:"team_name"`
+	Members []TeamMember `json:"members"`
+}
+
+type Roster struct {
+	Teams []RosterTeam `json:"teams"`
+}
+
+type RosterUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type RosterSyncResponse struct {
+	Applied bool `json:"applied"`
+	Plan    struct {
+		CreatedTeams []string     `json:"created_teams"`
+		CreatedUsers []RosterUser `json:"created_users"`
+		UpdatedUsers []struct {
+			Before RosterUser `json:"before"`
+			After  RosterUser `json:"after"`
+		} `json:"updated_users"`
+		DeactivatedUsers []RosterUser `json:"deactivated_users"`
+	} `json:"plan"`
+	Reassignments []struct {
+		PullRequestID string  `json:"pull_request_id"`
+		OldUserID     string  `json:"old_user_id"`
+		NewUserID     *string `json:"new_user_id"`
+	} `json:"reassignments"`
+}
+
+func TestSyncRoster(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	postDocument := func(t *testing.T, path, contentType string, body []byte) (int, []byte) {
+		resp, err := http.Post(baseURL+path, contentType, bytes.NewBuffer(body))
+		if err != nil {
+			t.Logf("Failed to send request to %s: %v", path, err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Logf("Failed to read response body: %v", err)
+			t.FailNow()
+		}
+		return resp.StatusCode, bodyBytes
+	}
+
+	sync := func(t *testing.T, roster Roster, dryRun bool) RosterSyncResponse {
+		rosterJSON, err := json.Marshal(roster)
+		if err != nil {
+			t.Log("Failed to marshal roster")
+			t.FailNow()
+		}
+
+		sc, bodyBytes := postDocument(t, fmt.Sprintf("/team/sync?dry_run=%t", dryRun), "application/json", rosterJSON)
+		if sc != http.StatusOK {
+			t.Logf("Sync should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var syncResp RosterSyncResponse
+		err = json.Unmarshal(bodyBytes, &syncResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal sync response: %v", err)
+			t.FailNow()
+		}
+		return syncResp
+	}
+
+	getTeam := func(t *testing.T, teamName string) *Team {
+		resp, err := http.Get(baseURL + "/team/get?team_name=" + teamName)
+		sc, team, _ := processResponse(t, resp, err)
+		assertEqual(t, http.StatusOK, sc, "Team should be found")
+		return team
+	}
+
+	teamJSON, err := json.Marshal(Team{
+		Name: "sync-team",
+		Members: []TeamMember{
+			{Id: "u23000", Name: "Alice", IsActive: true},
+			{Id: "u23001", Name: "Bob", IsActive: true},
+			{Id: "u23002", Name: "Charlie", IsActive: true},
+			{Id: "u23003", Name: "David", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Log("Failed to marshal team")
+		t.FailNow()
+	}
+	sc, _ := postDocument(t, "/team/add", "application/json", teamJSON)
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	prJSON, err := json.Marshal(CreatePullRequestRequest{
+		PullRequestID:   "pr-sync-001",
+		PullRequestName: "Sync PR",
+		AuthorID:        "u23000",
+	})
+	if err != nil {
+		t.Log("Failed to marshal create PR request")
+		t.FailNow()
+	}
+	sc, _ = postDocument(t, "/pullRequest/create", "application/json", prJSON)
+	assertEqual(t, http.StatusCreated, sc, "PR creation should succeed")
+
+	roster := Roster{Teams: []RosterTeam{
+		{Name: "sync-team", Members: []TeamMember{
+			{Id: "u23000", Name: "Alice", IsActive: true},
+			{Id: "u23001", Name: "Bobby", IsActive: true},
+			{Id: "u23004", Name: "Eve", IsActive: true},
+		}},
+		{Name: "sync-new-team", Members: []TeamMember{
+			{Id: "u23002", Name: "Charlie", IsActive: true},
+		}},
+	}}
+
+	t.Run("Dry run returns the plan", func(t *testing.T) {
+		syncResp := sync(t, roster, true)
+		assertTrue(t, !syncResp.Applied, "Dry run should not be applied")
+		assertEqual(t, "[sync-new-team]", fmt.Sprint(syncResp.Plan.CreatedTeams), "Created teams")
+		assertEqual(t, 1, len(syncResp.Plan.CreatedUsers), "Created users count")
+		assertEqual(t, "u23004", syncResp.Plan.CreatedUsers[0].UserID, "Created user")
+		assertEqual(t, 2, len(syncResp.Plan.UpdatedUsers), "Updated users count")
+		assertEqual(t, "Bobby", syncResp.Plan.UpdatedUsers[0].After.Username, "Renamed user")
+		assertEqual(t, "sync-new-team", syncResp.Plan.UpdatedUsers[1].After.TeamName, "Moved user")
+		assertEqual(t, 1, len(syncResp.Plan.DeactivatedUsers), "Deactivated users count")
+		assertEqual(t, "u23003", syncResp.Plan.DeactivatedUsers[0].UserID, "Deactivated user")
+
+		team := getTeam(t, "sync-team")
+		assertLen(t, team.Members, 4, "Dry run should not change the team")
+	})
+
+	t.Run("Sync applies the plan", func(t *testing.T) {
+		syncResp := sync(t, roster, false)
+		assertTrue(t, syncResp.Applied, "Sync should be applied")
+
+		for _, reassignment := range syncResp.Reassignments {
+			assertEqual(t, "pr-sync-001", reassignment.PullRequestID, "Reassigned PR")
+			assertTrue(t, slices.Contains([]string{"u23002", "u23003"}, reassignment.OldUserID), "Only leaving users are replaced")
+			if reassignment.NewUserID != nil {
+				assertTrue(t, *reassignment.NewUserID != "u23000", "Author should not be a replacement")
+			}
+		}
+
+		team := getTeam(t, "sync-team")
+		assertLen(t, team.Members, 4, "Deactivated member stays in the team")
+		for _, member := range team.Members {
+			assertEqual(t, member.Id != "u23003", member.IsActive, "Activity of "+member.Id)
+		}
+
+		newTeam := getTeam(t, "sync-new-team")
+		assertLen(t, newTeam.Members, 1, "New team members count")
+	})
+
+	t.Run("Synced roster has an empty plan", func(t *testing.T) {
+		rosterYAML := []byte(`
+teams:
+  - team_name: sync-team
+    members:
+      - { user_id: u23000, username: Alice, is_active: true }
+      - { user_id: u23001, username: Bobby, is_active: true }
+      - { user_id: u23004, username: Eve, is_active: true }
+  - team_name: sync-new-team
+    members:
+      - { user_id: u23002, username: Charlie, is_active: true }
+`)
+		sc, bodyBytes := postDocument(t, "/team/sync?dry_run=true", "application/yaml", rosterYAML)
+		if sc != http.StatusOK {
+			t.Logf("YAML sync should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var syncResp RosterSyncResponse
+		err := json.Unmarshal(bodyBytes, &syncResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal sync response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 0, len(syncResp.Plan.CreatedTeams), "Created teams count")
+		assertEqual(t, 0, len(syncResp.Plan.CreatedUsers), "Created users count")
+		assertEqual(t, 0, len(syncResp.Plan.UpdatedUsers), "Updated users count")
+		assertEqual(t, 0, len(syncResp.Plan.DeactivatedUsers), "Deactivated users count")
+	})
+
+	t.Run("User listed twice", func(t *testing.T) {
+		rosterJSON, err := json.Marshal(Roster{Teams: []RosterTeam{
+			{Name: "sync-team", Members: []TeamMember{{Id: "u23000", Name: "Alice", IsActive: true}}},
+			{Name: "sync-new-team", Members: []TeamMember{{Id: "u23000", Name: "Alice", IsActive: true}}},
+		}})
+		if err != nil {
+			t.Log("Failed to marshal roster")
+			t.FailNow()
+		}
+
+		sc, _ := postDocument(t, "/team/sync", "application/json", rosterJSON)
+		assertEqual(t, http.StatusBadRequest, sc, "Invalid roster should return 400")
+	})
+}
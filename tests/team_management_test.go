@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+type TeamMembershipResponse struct {
+	User struct {
+		UserID   string `json:"user_id"`
+		TeamName string `json:"team_name"`
+		IsActive bool   `json:"is_active"`
+	} `json:"user"`
+	FromTeam      string `json:"from_team"`
+	ToTeam        string `json:"to_team"`
+	Reassignments []struct {
+		PullRequestID string  `json:"pull_request_id"`
+		OldUserID     string  `json:"old_user_id"`
+		NewUserID     *string `json:"new_user_id"`
+	} `json:"reassignments"`
+}
+
+func TestTeamManagement(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	errorCode := func(t *testing.T, bodyBytes []byte) string {
+		var errorResp ErrorResponse
+		err := json.Unmarshal(bodyBytes, &errorResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal error response: %v", err)
+			t.FailNow()
+		}
+		return errorResp.Error.Code
+	}
+
+	getTeam := func(t *testing.T, teamName string) (int, *Team) {
+		resp, err := http.Get(baseURL + "/team/get?team_name=" + teamName)
+		sc, team, _ := processResponse(t, resp, err)
+		return sc, team
+	}
+
+	sc, bodyBytes := post(t, "/team/add", Team{
+		Name: "management-team",
+		Members: []TeamMember{
+			{Id: "u21000", Name: "Alice", IsActive: true},
+			{Id: "u21001", Name: "Bob", IsActive: true},
+			{Id: "u21002", Name: "Charlie", IsActive: true},
+		},
+	})
+	if sc != http.StatusCreated {
+		t.Logf("Team creation should succeed, got status: %d\n%s", sc, string(bodyBytes))
+		t.FailNow()
+	}
+	sc, _ = post(t, "/team/add", Team{Name: "management-other-team", Members: []TeamMember{}})
+	assertEqual(t, http.StatusCreated, sc, "Second team creation should succeed")
+
+	t.Run("Add member", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/addMember", map[string]any{
+			"team_name": "management-team",
+			"user_id":   "u21003",
+			"username":  "David",
+			"is_active": true,
+		})
+		if sc != http.StatusCreated {
+			t.Logf("Adding member should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		_, team := getTeam(t, "management-team")
+		assertLen(t, team.Members, 4, "Team members count")
+
+		sc, bodyBytes = post(t, "/team/addMember", map[string]any{
+			"team_name": "management-other-team",
+			"user_id":   "u21003",
+			"username":  "David",
+			"is_active": true,
+		})
+		assertEqual(t, http.StatusConflict, sc, "Adding existing user should return 409")
+		assertEqual(t, "USER_EXISTS", errorCode(t, bodyBytes), "Error code")
+
+		sc, _ = post(t, "/team/addMember", map[string]any{
+			"team_name": "no-such-management-team",
+			"user_id":   "u21099",
+			"username":  "Nobody",
+			"is_active": true,
+		})
+		assertEqual(t, http.StatusNotFound, sc, "Adding to missing team should return 404")
+	})
+
+	t.Run("Move member hands over reviews", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/pullRequest/create", CreatePullRequestRequest{
+			PullRequestID:   "pr-management-001",
+			PullRequestName: "Management PR",
+			AuthorID:        "u21000",
+		})
+		if sc != http.StatusCreated {
+			t.Logf("PR creation should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+		var prResp PullRequestResponse
+		err := json.Unmarshal(bodyBytes, &prResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal PR response: %v", err)
+			t.FailNow()
+		}
+		moved := prResp.PR.AssignedReviewers[0]
+
+		sc, bodyBytes = post(t, "/team/moveMember", map[string]any{
+			"user_id":   moved,
+			"team_name": "management-other-team",
+		})
+		if sc != http.StatusOK {
+			t.Logf("Moving member should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		var moveResp TeamMembershipResponse
+		err = json.Unmarshal(bodyBytes, &moveResp)
+		if err != nil {
+			t.Logf("Failed to unmarshal move response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, "management-team", moveResp.FromTeam, "Old team")
+		assertEqual(t, "management-other-team", moveResp.ToTeam, "New team")
+		assertEqual(t, "management-other-team", moveResp.User.TeamName, "User team")
+		assertEqual(t, 1, len(moveResp.Reassignments), "Reassignments count")
+		assertEqual(t, moved, moveResp.Reassignments[0].OldUserID, "Replaced reviewer")
+
+		_, team := getTeam(t, "management-other-team")
+		assertLen(t, team.Members, 1, "New team members count")
+	})
+
+	t.Run("Remove member", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/removeMember", map[string]any{
+			"team_name": "management-team",
+			"user_id":   "u21000",
+		})
+		assertEqual(t, http.StatusConflict, sc, "Removing PR author should return 409")
+		assertEqual(t, "USER_HAS_PRS", errorCode(t, bodyBytes), "Error code")
+
+		sc, _ = post(t, "/team/removeMember", map[string]any{
+			"team_name": "management-other-team",
+			"user_id":   "u21000",
+		})
+		assertEqual(t, http.StatusNotFound, sc, "Removing user of another team should return 404")
+
+		_, team := getTeam(t, "management-other-team")
+		sc, bodyBytes = post(t, "/team/removeMember", map[string]any{
+			"team_name": "management-other-team",
+			"user_id":   team.Members[0].Id,
+		})
+		if sc != http.StatusOK {
+			t.Logf("Removing member should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		_, team = getTeam(t, "management-other-team")
+		assertLen(t, team.Members, 0, "Team members count")
+	})
+
+	t.Run("Rename team", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/rename", map[string]any{
+			"team_name":     "management-team",
+			"new_team_name": "management-other-team",
+		})
+		assertEqual(t, http.StatusBadRequest, sc, "Renaming to taken name should return 400")
+		assertEqual(t, "TEAM_EXISTS", errorCode(t, bodyBytes), "Error code")
+
+		sc, bodyBytes = post(t, "/team/rename", map[string]any{
+			"team_name":     "management-team",
+			"new_team_name": "management-renamed-team",
+		})
+		if sc != http.StatusOK {
+			t.Logf("Renaming team should succeed, got status: %d\n%s", sc, string(bodyBytes))
+			t.FailNow()
+		}
+
+		sc, team := getTeam(t, "management-renamed-team")
+		assertEqual(t, http.StatusOK, sc, "Renamed team should be found")
+		assertLen(t, team.Members, 3, "Members should be kept")
+
+		sc, _ = getTeam(t, "management-team")
+		assertEqual(t, http.StatusNotFound, sc, "Old team name should be gone")
+	})
+
+	t.Run("Delete team", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/team/delete", map[string]any{
+			"team_name": "management-renamed-team",
+		})
+		assertEqual(t, http.StatusConflict, sc, "Deleting team with members should return 409")
+		assertEqual(t, "TEAM_NOT_EMPTY", errorCode(t, bodyBytes), "Error code")
+
+		sc, _ = post(t, "/team/delete", map[string]any{
+			"team_name": "management-other-team",
+		})
+		assertEqual(t, http.StatusOK, sc, "Deleting empty team should succeed")
+
+		sc, _ = getTeam(t, "management-other-team")
+		assertEqual(t, http.StatusNotFound, sc, "Deleted team should be gone")
+	})
+}
@@ -1,9 +1,12 @@
 package tests
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 )
 
@@ -34,6 +37,114 @@ type SimpleErrorResponse struct {
 	Message string `json:"message"`
 }
 
+type CreatePullRequestRequest struct {
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	ChangedPaths    []string `json:"changed_paths,omitempty"`
+}
+
+type PullRequestResponse struct {
+	PR struct {
+		PullRequestID     string          `json:"pull_request_id"`
+		PullRequestName   string          `json:"pull_request_name"`
+		AuthorID          string          `json:"author_id"`
+		Status            string          `json:"status"`
+		AssignedReviewers []string        `json:"assigned_reviewers"`
+		CreatedAt         *string         `json:"createdAt,omitempty"`
+		MergedAt          *string         `json:"mergedAt,omitempty"`
+		Reviews           []ReviewerState `json:"reviews"`
+	} `json:"pr"`
+}
+
+type ReviewerState struct {
+	UserID      string   `json:"user_id"`
+	State       string   `json:"state"`
+	SubmittedAt *string  `json:"submitted_at,omitempty"`
+	External    bool     `json:"external"`
+	Reason      string   `json:"reason"`
+	OwnedPaths  []string `json:"owned_paths"`
+}
+
+// serviceURL is the address of the service under test, its port is taken
+// from SERVICE_PORT.
+func serviceURL() string {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	return fmt.Sprintf("http://localhost:%s", port)
+}
+
+// post sends body as JSON to path and returns the status code and the response body.
+func post(t *testing.T, path string, body any) (int, []byte) {
+	reqJSON, err := json.Marshal(body)
+	if err != nil {
+		t.Log("Failed to marshal request")
+		t.FailNow()
+	}
+
+	resp, err := http.Post(serviceURL()+path, "application/json", bytes.NewBuffer(reqJSON))
+	if err != nil {
+		t.Logf("Failed to send request to %s: %v", path, err)
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Logf("Failed to read response body: %v", err)
+		t.FailNow()
+	}
+	return resp.StatusCode, bodyBytes
+}
+
+// get requests path and returns the status code and the response body.
+func get(t *testing.T, path string) (int, []byte) {
+	resp, err := http.Get(serviceURL() + path)
+	if err != nil {
+		t.Logf("Failed to send request to %s: %v", path, err)
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Logf("Failed to read response body: %v", err)
+		t.FailNow()
+	}
+	return resp.StatusCode, bodyBytes
+}
+
+func decode(t *testing.T, bodyBytes []byte, v any) {
+	if err := json.Unmarshal(bodyBytes, v); err != nil {
+		t.Logf("Failed to unmarshal response %s: %v", bodyBytes, err)
+		t.FailNow()
+	}
+}
+
+// createTeam adds a new team, team is a Team or a request with team settings.
+func createTeam(t *testing.T, team any) {
+	sc, bodyBytes := post(t, "/team/add", team)
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed\n"+string(bodyBytes))
+}
+
+// createPR creates a pull request of the author, changedPaths are optional.
+func createPR(t *testing.T, prID, authorID string, changedPaths ...string) PullRequestResponse {
+	sc, bodyBytes := post(t, "/pullRequest/create", CreatePullRequestRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Test PR " + prID,
+		AuthorID:        authorID,
+		ChangedPaths:    changedPaths,
+	})
+	assertEqual(t, http.StatusCreated, sc, "PR creation should succeed\n"+string(bodyBytes))
+
+	var prResp PullRequestResponse
+	decode(t, bodyBytes, &prResp)
+	return prResp
+}
+
 func processResponse(t *testing.T, resp *http.Response, err error) (int, *Team, *ErrorResponse) {
 	if err != nil {
 		t.Logf("Error when sending request: %v\n", err)
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+type Unavailability struct {
+	ID         int64  `json:"id"`
+	UserID     string `json:"user_id"`
+	StartsAt   string `json:"starts_at"`
+	EndsAt     string `json:"ends_at"`
+	Reason     string `json:"reason"`
+	HandedOver bool   `json:"handed_over"`
+}
+
+type AddUnavailabilityResponse struct {
+	Unavailability Unavailability `json:"unavailability"`
+	Reassignments  []struct {
+		PullRequestID string  `json:"pull_request_id"`
+		OldUserID     string  `json:"old_user_id"`
+		NewUserID     *string `json:"new_user_id"`
+	} `json:"reassignments"`
+}
+
+type GetUnavailabilityResponse struct {
+	UserID         string           `json:"user_id"`
+	Unavailability []Unavailability `json:"unavailability"`
+}
+
+func TestUnavailability(t *testing.T) {
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := fmt.Sprintf("http://localhost:%s", port)
+
+	addUnavailability := func(t *testing.T, userID string, startsAt, endsAt time.Time) (int, AddUnavailabilityResponse) {
+		sc, bodyBytes := post(t, "/users/addUnavailability", map[string]string{
+			"user_id":   userID,
+			"starts_at": startsAt.Format(time.RFC3339),
+			"ends_at":   endsAt.Format(time.RFC3339),
+			"reason":    "vacation",
+		})
+
+		var addResp AddUnavailabilityResponse
+		if sc == http.StatusCreated {
+			decode(t, bodyBytes, &addResp)
+		}
+		return sc, addResp
+	}
+
+	now := time.Now()
+
+	sc, _ := post(t, "/team/add", map[string]any{
+		"team_name": "away-team",
+		"members": []TeamMember{
+			{Id: "u31000", Name: "Alice", IsActive: true},
+			{Id: "u31001", Name: "Bob", IsActive: true},
+			{Id: "u31002", Name: "Charlie", IsActive: true},
+		},
+		"reviewer_count": 1,
+	})
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed")
+
+	prResp := createPR(t, "pr-away-001", "u31000")
+	assertEqual(t, 1, len(prResp.PR.AssignedReviewers), "Reviewers count")
+	away := prResp.PR.AssignedReviewers[0]
+	other := "u31001"
+	if away == other {
+		other = "u31002"
+	}
+
+	t.Run("Future period keeps reviews", func(t *testing.T) {
+		sc, addResp := addUnavailability(t, away, now.Add(24*time.Hour), now.Add(48*time.Hour))
+		assertEqual(t, http.StatusCreated, sc, "Adding a period should succeed")
+		assertEqual(t, away, addResp.Unavailability.UserID, "Period user")
+		assertEqual(t, "vacation", addResp.Unavailability.Reason, "Period reason")
+		assertEqual(t, false, addResp.Unavailability.HandedOver, "Reviews are not handed over yet")
+		assertEqual(t, 0, len(addResp.Reassignments), "Nothing is reassigned")
+	})
+
+	t.Run("Started period hands over reviews", func(t *testing.T) {
+		sc, addResp := addUnavailability(t, away, now.Add(-time.Hour), now.Add(time.Hour))
+		assertEqual(t, http.StatusCreated, sc, "Adding a period should succeed")
+		assertEqual(t, true, addResp.Unavailability.HandedOver, "Reviews are handed over")
+		assertEqual(t, 1, len(addResp.Reassignments), "Reassignments count")
+		assertEqual(t, "pr-away-001", addResp.Reassignments[0].PullRequestID, "Reassigned PR")
+		assertEqual(t, away, addResp.Reassignments[0].OldUserID, "Old reviewer")
+		assertTrue(t, addResp.Reassignments[0].NewUserID != nil, "New reviewer is picked")
+		assertEqual(t, other, *addResp.Reassignments[0].NewUserID, "New reviewer")
+	})
+
+	t.Run("Unavailable user is not assigned", func(t *testing.T) {
+		prResp := createPR(t, "pr-away-002", "u31000")
+		assertEqual(t, fmt.Sprint([]string{other}), fmt.Sprint(prResp.PR.AssignedReviewers), "Only the available teammate is assigned")
+	})
+
+	var futureID int64
+	t.Run("List periods", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/users/getUnavailability?user_id=" + away)
+		if err != nil {
+			t.Logf("Failed to get periods: %v", err)
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		assertEqual(t, http.StatusOK, resp.StatusCode, "Listing periods should succeed")
+
+		var listResp GetUnavailabilityResponse
+		err = json.NewDecoder(resp.Body).Decode(&listResp)
+		if err != nil {
+			t.Logf("Failed to decode periods: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, 2, len(listResp.Unavailability), "Periods count")
+		assertEqual(t, true, listResp.Unavailability[0].HandedOver, "Started period goes first")
+		assertEqual(t, false, listResp.Unavailability[1].HandedOver, "Future period goes last")
+		futureID = listResp.Unavailability[1].ID
+	})
+
+	t.Run("Invalid periods", func(t *testing.T) {
+		sc, _ := addUnavailability(t, away, now.Add(2*time.Hour), now.Add(time.Hour))
+		assertEqual(t, http.StatusBadRequest, sc, "End before start should return 400")
+
+		sc, _ = addUnavailability(t, away, now.Add(-2*time.Hour), now.Add(-time.Hour))
+		assertEqual(t, http.StatusBadRequest, sc, "Period in the past should return 400")
+
+		sc, _ = post(t, "/users/addUnavailability", map[string]string{"user_id": away})
+		assertEqual(t, http.StatusBadRequest, sc, "Missing times should return 400")
+
+		sc, _ = addUnavailability(t, "u31099", now, now.Add(time.Hour))
+		assertEqual(t, http.StatusNotFound, sc, "Missing user should return 404")
+
+		resp, err := http.Get(baseURL + "/users/getUnavailability")
+		if err != nil {
+			t.Logf("Failed to get periods: %v", err)
+			t.FailNow()
+		}
+		resp.Body.Close()
+		assertEqual(t, http.StatusBadRequest, resp.StatusCode, "Missing user_id should return 400")
+	})
+
+	t.Run("Delete a period", func(t *testing.T) {
+		sc, _ := post(t, "/users/deleteUnavailability", map[string]int64{"id": futureID})
+		assertEqual(t, http.StatusOK, sc, "Deleting a period should succeed")
+
+		sc, _ = post(t, "/users/deleteUnavailability", map[string]int64{"id": futureID})
+		assertEqual(t, http.StatusNotFound, sc, "Deleting it again should return 404")
+	})
+}
@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type Webhook struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	TeamName  string   `json:"team_name"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type WebhookAttempt struct {
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error"`
+}
+
+type WebhookDelivery struct {
+	ID        int64            `json:"id"`
+	Event     string           `json:"event"`
+	Status    string           `json:"status"`
+	Payload   json.RawMessage  `json:"payload"`
+	LastError string           `json:"last_error"`
+	Attempts  []WebhookAttempt `json:"attempts"`
+}
+
+type WebhookDeliveriesResponse struct {
+	SubscriptionID int64             `json:"subscription_id"`
+	Deliveries     []WebhookDelivery `json:"deliveries"`
+}
+
+type WebhookPayload struct {
+	Event       string `json:"event"`
+	TeamName    string `json:"team_name"`
+	PullRequest struct {
+		PullRequestID     string   `json:"pull_request_id"`
+		Status            string   `json:"status"`
+		AssignedReviewers []string `json:"assigned_reviewers"`
+	} `json:"pull_request"`
+	UserID string `json:"user_id"`
+}
+
+// webhookReceiver records the requests it gets and answers them with status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+
+	w.WriteHeader(r.status)
+}
+
+func TestWebhooks(t *testing.T) {
+	const secret = "webhook-secret"
+
+	createWebhook := func(t *testing.T, body map[string]any) Webhook {
+		sc, bodyBytes := post(t, "/webhooks/create", body)
+		assertEqual(t, http.StatusCreated, sc, "Webhook creation should succeed\n"+string(bodyBytes))
+
+		var resp struct {
+			Webhook Webhook `json:"webhook"`
+		}
+		if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+			t.Logf("Failed to decode webhook response: %v", err)
+			t.FailNow()
+		}
+		return resp.Webhook
+	}
+
+	getDeliveries := func(t *testing.T, subscriptionID int64) []WebhookDelivery {
+		sc, bodyBytes := get(t, fmt.Sprintf("/webhooks/deliveries?subscription_id=%d", subscriptionID))
+		assertEqual(t, http.StatusOK, sc, "Getting deliveries should succeed\n"+string(bodyBytes))
+
+		var resp WebhookDeliveriesResponse
+		if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+			t.Logf("Failed to decode deliveries response: %v", err)
+			t.FailNow()
+		}
+		assertEqual(t, subscriptionID, resp.SubscriptionID, "Subscription id is echoed")
+		return resp.Deliveries
+	}
+
+	// waitDeliveries polls the delivery log until every delivery got at
+	// least one attempt, the worker runs every few seconds
+	waitDeliveries := func(t *testing.T, subscriptionID int64, count int) []WebhookDelivery {
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			deliveries := getDeliveries(t, subscriptionID)
+			attempted := len(deliveries) == count
+			for _, delivery := range deliveries {
+				attempted = attempted && len(delivery.Attempts) > 0
+			}
+			if attempted {
+				return deliveries
+			}
+			if time.Now().After(deadline) {
+				t.Logf("Deliveries were not attempted in time: %+v", deliveries)
+				t.FailNow()
+			}
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
+
+	receiver := &webhookReceiver{status: http.StatusNoContent}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+
+	failing := &webhookReceiver{status: http.StatusInternalServerError}
+	failingServer := httptest.NewServer(failing)
+	defer failingServer.Close()
+
+	team := Team{Name: "webhook-core", Members: []TeamMember{
+		{Id: "u35000", Name: "Alice", IsActive: true},
+		{Id: "u35001", Name: "Bob", IsActive: true},
+		{Id: "u35002", Name: "Charlie", IsActive: true},
+	}}
+	sc, bodyBytes := post(t, "/team/add", team)
+	assertEqual(t, http.StatusCreated, sc, "Team creation should succeed\n"+string(bodyBytes))
+
+	other := Team{Name: "webhook-other", Members: []TeamMember{
+		{Id: "u35010", Name: "Dave", IsActive: true},
+		{Id: "u35011", Name: "Eve", IsActive: true},
+	}}
+	sc, bodyBytes = post(t, "/team/add", other)
+	assertEqual(t, http.StatusCreated, sc, "Other team creation should succeed\n"+string(bodyBytes))
+
+	t.Run("Invalid subscriptions are rejected", func(t *testing.T) {
+		sc, _ := post(t, "/webhooks/create", map[string]any{"url": "ftp://example.com", "secret": secret})
+		assertEqual(t, http.StatusBadRequest, sc, "Non-HTTP URL should return 400")
+
+		sc, _ = post(t, "/webhooks/create", map[string]any{"url": receiverServer.URL, "secret": secret, "events": []string{"PR_OPENED"}})
+		assertEqual(t, http.StatusBadRequest, sc, "Unknown event should return 400")
+
+		sc, _ = post(t, "/webhooks/create", map[string]any{"url": receiverServer.URL, "secret": secret, "team_name": "webhook-missing"})
+		assertEqual(t, http.StatusBadRequest, sc, "Missing team should return 400")
+
+		sc, _ = post(t, "/webhooks/create", map[string]any{"url": receiverServer.URL})
+		assertEqual(t, http.StatusBadRequest, sc, "Missing secret should return 400")
+
+		sc, _ = get(t, "/webhooks/get?id=999999999")
+		assertEqual(t, http.StatusNotFound, sc, "Missing subscription should return 404")
+
+		sc, _ = post(t, "/webhooks/delete", map[string]any{"id": 999999999})
+		assertEqual(t, http.StatusNotFound, sc, "Deleting a missing subscription should return 404")
+
+		sc, _ = get(t, "/webhooks/deliveries?subscription_id=999999999")
+		assertEqual(t, http.StatusNotFound, sc, "Deliveries of a missing subscription should return 404")
+	})
+
+	all := createWebhook(t, map[string]any{"url": receiverServer.URL, "secret": secret, "team_name": "webhook-core"})
+	merged := createWebhook(t, map[string]any{"url": receiverServer.URL, "secret": secret, "team_name": "webhook-core", "events": []string{"PULL_REQUEST_MERGED"}})
+	otherTeam := createWebhook(t, map[string]any{"url": receiverServer.URL, "secret": secret, "team_name": "webhook-other"})
+	broken := createWebhook(t, map[string]any{"url": failingServer.URL, "secret": secret, "team_name": "webhook-core", "events": []string{"PULL_REQUEST_CREATED"}})
+
+	t.Run("Subscriptions do not expose the secret", func(t *testing.T) {
+		assertTrue(t, all.Active, "New subscription is active")
+		assertEqual(t, 0, len(all.Events), "No filter means all events")
+		assertEqual(t, "PULL_REQUEST_MERGED", strings.Join(merged.Events, ","), "Event filter")
+
+		sc, bodyBytes := get(t, fmt.Sprintf("/webhooks/get?id=%d", all.ID))
+		assertEqual(t, http.StatusOK, sc, "Getting a subscription should succeed\n"+string(bodyBytes))
+		assertTrue(t, !strings.Contains(string(bodyBytes), secret), "Secret is not returned")
+
+		sc, bodyBytes = get(t, "/webhooks/list")
+		assertEqual(t, http.StatusOK, sc, "Listing subscriptions should succeed\n"+string(bodyBytes))
+		assertTrue(t, !strings.Contains(string(bodyBytes), secret), "Secret is not listed")
+
+		sc, bodyBytes = get(t, fmt.Sprintf("/audit/log?target_type=WEBHOOK&target_id=%d", all.ID))
+		assertEqual(t, http.StatusOK, sc, "Getting the audit log should succeed\n"+string(bodyBytes))
+		assertContains(t, string(bodyBytes), "WEBHOOK_CREATE", "Creation is audited")
+		assertTrue(t, !strings.Contains(string(bodyBytes), secret), "Secret is not audited")
+	})
+
+	sc, bodyBytes = post(t, "/pullRequest/create", CreatePullRequestRequest{
+		PullRequestID:   "pr-webhook-001",
+		PullRequestName: "Webhook PR",
+		AuthorID:        "u35000",
+	})
+	assertEqual(t, http.StatusCreated, sc, "PR creation should succeed\n"+string(bodyBytes))
+
+	t.Run("PR creation is delivered signed", func(t *testing.T) {
+		deliveries := waitDeliveries(t, all.ID, 3)
+
+		// Newest first: the PR is created before its reviewers are assigned
+		assertEqual(t, "REVIEWER_ASSIGNED", deliveries[0].Event, "Third event")
+		assertEqual(t, "REVIEWER_ASSIGNED", deliveries[1].Event, "Second event")
+		assertEqual(t, "PULL_REQUEST_CREATED", deliveries[2].Event, "First event")
+		reviewers := map[string]bool{}
+		for _, delivery := range deliveries {
+			assertEqual(t, "DELIVERED", delivery.Status, "Delivery status")
+			assertEqual(t, 1, len(delivery.Attempts), "Delivered on the first attempt")
+			assertEqual(t, http.StatusNoContent, delivery.Attempts[0].StatusCode, "Receiver status")
+
+			var payload WebhookPayload
+			if err := json.Unmarshal(delivery.Payload, &payload); err != nil {
+				t.Logf("Failed to decode payload %s: %v", delivery.Payload, err)
+				t.FailNow()
+			}
+			assertEqual(t, delivery.Event, payload.Event, "Payload event")
+			assertEqual(t, "webhook-core", payload.TeamName, "Payload team")
+			assertEqual(t, "pr-webhook-001", payload.PullRequest.PullRequestID, "Payload PR")
+			assertEqual(t, "OPEN", payload.PullRequest.Status, "Payload PR status")
+			if payload.Event == "REVIEWER_ASSIGNED" {
+				reviewers[payload.UserID] = true
+			}
+		}
+		assertTrue(t, reviewers["u35001"] && reviewers["u35002"], "Both reviewers are announced")
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		assertEqual(t, 3, len(receiver.requests), "Receiver got every delivery once")
+		for i, req := range receiver.requests {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(receiver.bodies[i])
+			expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+			assertEqual(t, expected, req.Header.Get("X-Webhook-Signature"), "Signature")
+			assertTrue(t, req.Header.Get("X-Webhook-Event") != "", "Event header")
+			assertTrue(t, req.Header.Get("X-Webhook-Delivery") != "", "Delivery header")
+		}
+	})
+
+	t.Run("Failed deliveries are retried later", func(t *testing.T) {
+		deliveries := waitDeliveries(t, broken.ID, 1)
+		delivery := deliveries[0]
+		assertEqual(t, "PULL_REQUEST_CREATED", delivery.Event, "Only the filtered event")
+		assertEqual(t, "PENDING", delivery.Status, "Delivery waits for a retry")
+		assertEqual(t, http.StatusInternalServerError, delivery.Attempts[0].StatusCode, "Receiver status")
+		assertContains(t, delivery.LastError, "500", "Last error")
+	})
+
+	t.Run("Event and team filters", func(t *testing.T) {
+		assertEqual(t, 0, len(getDeliveries(t, merged.ID)), "Nothing is merged yet")
+		assertEqual(t, 0, len(getDeliveries(t, otherTeam.ID)), "Other team events only")
+
+		sc, bodyBytes := post(t, "/pullRequest/merge", map[string]string{"pull_request_id": "pr-webhook-001"})
+		assertEqual(t, http.StatusOK, sc, "Merge should succeed\n"+string(bodyBytes))
+
+		deliveries := waitDeliveries(t, merged.ID, 1)
+		assertEqual(t, "PULL_REQUEST_MERGED", deliveries[0].Event, "Merge event")
+		assertEqual(t, "DELIVERED", deliveries[0].Status, "Merge is delivered")
+		assertEqual(t, 0, len(getDeliveries(t, otherTeam.ID)), "Other team still gets nothing")
+	})
+
+	t.Run("Inactive subscriptions get nothing", func(t *testing.T) {
+		sc, bodyBytes := post(t, "/webhooks/update", map[string]any{"id": merged.ID, "url": receiverServer.URL, "events": []string{"PULL_REQUEST_CREATED"}, "team_name": "webhook-core", "active": false})
+		assertEqual(t, http.StatusOK, sc, "Update should succeed\n"+string(bodyBytes))
+
+		var resp struct {
+			Webhook Webhook `json:"webhook"`
+		}
+		if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+			t.Logf("Failed to decode webhook response: %v", err)
+			t.FailNow()
+		}
+		assertTrue(t, !resp.Webhook.Active, "Subscription is paused")
+		assertEqual(t, "PULL_REQUEST_CREATED", strings.Join(resp.Webhook.Events, ","), "Updated filter")
+
+		sc, bodyBytes = post(t, "/pullRequest/create", CreatePullRequestRequest{
+			PullRequestID:   "pr-webhook-002",
+			PullRequestName: "Another webhook PR",
+			AuthorID:        "u35000",
+		})
+		assertEqual(t, http.StatusCreated, sc, "PR creation should succeed\n"+string(bodyBytes))
+
+		assertEqual(t, 1, len(getDeliveries(t, merged.ID)), "Paused subscription gets no new deliveries")
+	})
+
+	t.Run("Deleting a subscription drops its deliveries", func(t *testing.T) {
+		for _, subscription := range []Webhook{all, merged, otherTeam, broken} {
+			sc, bodyBytes := post(t, "/webhooks/delete", map[string]any{"id": subscription.ID})
+			assertEqual(t, http.StatusOK, sc, "Delete should succeed\n"+string(bodyBytes))
+		}
+
+		sc, _ := get(t, fmt.Sprintf("/webhooks/deliveries?subscription_id=%d", all.ID))
+		assertEqual(t, http.StatusNotFound, sc, "Deleted subscription has no log")
+	})
+}